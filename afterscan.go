@@ -0,0 +1,80 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+)
+
+// AfterScanner is implemented by types that are mapped with a struct mapper
+// and need to run code after each row is scanned, e.g. to compute derived fields
+// or check invariants. An error from AfterScan is returned for the row
+//
+//	func (u *User) AfterScan(ctx context.Context) error {
+//	    u.FullName = u.FirstName + " " + u.LastName
+//	    return nil
+//	}
+type AfterScanner interface {
+	AfterScan(ctx context.Context) error
+}
+
+var afterScannerType = reflect.TypeOf((*AfterScanner)(nil)).Elem()
+
+// isAfterScanner reports if T or *T implements [AfterScanner]
+func isAfterScanner(typ reflect.Type) bool {
+	if typ == nil {
+		return false
+	}
+
+	return typ.Implements(afterScannerType) || reflect.PtrTo(typ).Implements(afterScannerType)
+}
+
+// withAfterScan calls AfterScan on each value mapped by m, if T implements [AfterScanner]
+func withAfterScan[T any](m Mapper[T]) Mapper[T] {
+	if !isAfterScanner(typeOf[T]()) {
+		return m
+	}
+
+	return func(ctx context.Context, c cols) (func(*Row) (any, error), func(any) (T, error)) {
+		before, after := m(ctx, c)
+		return func(v *Row) (any, error) {
+				link, err := before(v)
+				if err != nil {
+					return nil, err
+				}
+
+				// The row is kept with the link for the context of the run
+				return afterScanLink{link: link, row: v}, nil
+			}, func(v any) (T, error) {
+				l := v.(afterScanLink)
+				t, err := after(l.link)
+				if err != nil {
+					return t, err
+				}
+
+				return t, afterScan(l.row.runContext(ctx), &t)
+			}
+	}
+}
+
+type afterScanLink struct {
+	link any
+	row  *Row
+}
+
+// afterScan calls AfterScan on the value, with a pointer receiver if needed.
+// Nil pointers are skipped
+func afterScan[T any](ctx context.Context, t *T) error {
+	if a, ok := any(t).(AfterScanner); ok {
+		return a.AfterScan(ctx)
+	}
+
+	if v := reflect.ValueOf(t).Elem(); v.Kind() == reflect.Pointer && v.IsNil() {
+		return nil
+	}
+
+	if a, ok := any(*t).(AfterScanner); ok {
+		return a.AfterScan(ctx)
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var errNoName = errors.New("no name")
+
+type scannedUser struct {
+	ID    int
+	Name  string
+	Label string `db:"-"`
+}
+
+func (u *scannedUser) AfterScan(ctx context.Context) error {
+	if u.Name == "" {
+		return errNoName
+	}
+
+	u.Label = u.Name + "#" + strconv.Itoa(u.ID)
+	return nil
+}
+
+func TestAfterScanner(t *testing.T) {
+	ctx := context.Background()
+
+	newRows := func() Rows {
+		return newSliceRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"})
+	}
+
+	expected := []scannedUser{
+		{ID: 1, Name: "foo", Label: "foo#1"},
+		{ID: 2, Name: "bar", Label: "bar#2"},
+	}
+
+	users, err := AllFromRows(ctx, StructMapper[scannedUser](), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(expected, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	pointers, err := AllFromRows(ctx, StructMapper[*scannedUser](), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]*scannedUser{&expected[0], &expected[1]}, pointers); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows := newSliceRows([]string{"id", "name"}, []any{1, ""})
+	if _, err := AllFromRows(ctx, StructMapper[scannedUser](), rows); !errors.Is(err, errNoName) {
+		t.Fatalf("expected the error from AfterScan, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// decodeArray decodes the text representation of a Postgres array
+// e.g. {1,2,3} or {"a","b"} into the slice pointed to by dest.
+// Elements are converted in the same way as the fields of composite values,
+// and multi-dimensional arrays are decoded into slices of slices
+func (s *mapperSourceImpl) decodeArray(data []byte, dest any) error {
+	v := reflect.ValueOf(dest).Elem()
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if !isArraySlice(v.Type()) {
+		return fmt.Errorf("cannot decode an array into %s", v.Type())
+	}
+
+	values, err := parseArray(string(data))
+	if err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+	for i, value := range values {
+		ev := slice.Index(i)
+
+		if value != nil && isArraySlice(ev.Type()) {
+			err = s.decodeArray([]byte(*value), ev.Addr().Interface())
+		} else {
+			err = s.assignCompositeField(ev, value)
+		}
+		if err != nil {
+			return fmt.Errorf("array element %d: %w", i, err)
+		}
+	}
+
+	v.Set(slice)
+	return nil
+}
+
+// isArraySlice reports if an array can be decoded into the type.
+// Byte slices are excluded since they hold a single value
+func isArraySlice(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice && typ.Elem().Kind() != reflect.Uint8
+}
+
+// parseArray splits the text representation of an array into its elements.
+// NULL elements are returned as nil, and nested arrays are returned as they are
+func parseArray(s string) ([]*string, error) {
+	s = strings.TrimSpace(s)
+
+	// Arrays with custom bounds are prefixed with them e.g. [0:1]={1,2}
+	if strings.HasPrefix(s, "[") {
+		if i := strings.Index(s, "="); i >= 0 {
+			s = s[i+1:]
+		}
+	}
+
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, fmt.Errorf("invalid array value %q", s)
+	}
+	s = s[1 : len(s)-1]
+
+	values := []*string{}
+	if strings.TrimSpace(s) == "" {
+		return values, nil
+	}
+
+	var current strings.Builder
+	var quoted, inQuotes bool
+	var depth int
+
+	end := func() {
+		val := current.String()
+		current.Reset()
+
+		if !quoted {
+			val = strings.TrimSpace(val)
+			if strings.EqualFold(val, "NULL") {
+				values = append(values, nil)
+				return
+			}
+		}
+
+		values = append(values, &val)
+		quoted = false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case depth > 0:
+			// Nested arrays are kept as they are to be parsed on their own
+			current.WriteByte(c)
+			switch {
+			case c == '\\' && i+1 < len(s):
+				i++
+				current.WriteByte(s[i])
+			case c == '"':
+				inQuotes = !inQuotes
+			case c == '{' && !inQuotes:
+				depth++
+			case c == '}' && !inQuotes:
+				depth--
+			}
+		case c == '\\' && i+1 < len(s):
+			i++
+			current.WriteByte(s[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case c == '{' && !inQuotes:
+			depth++
+			current.WriteByte(c)
+		case c == ',' && !inQuotes:
+			end()
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	if inQuotes || depth != 0 {
+		return nil, fmt.Errorf("unterminated array value %q", s)
+	}
+	end()
+
+	return values, nil
+}
@@ -136,3 +136,100 @@ func TestPrepare(t *testing.T) {
 		t.Error("wrong cnt")
 	}
 }
+
+// benchRows returns the same row forever and scans it without allocating,
+// so that benchmarks only measure the mapping
+type benchRows struct {
+	cols []string
+	row  Userss
+}
+
+func (r *benchRows) Columns() ([]string, error) { return r.cols, nil }
+func (r *benchRows) Next() bool                 { return true }
+func (r *benchRows) Close() error               { return nil }
+func (r *benchRows) Err() error                 { return nil }
+
+func (r *benchRows) Scan(dest ...any) error {
+	for i, d := range dest {
+		switch d := d.(type) {
+		case *int:
+			*d = r.row.ID + r.row.Role*i
+		case *int64:
+			*d = r.row.LastOnlineAt
+		case *string:
+			*d = r.row.UserName
+		case *time.Time:
+			*d = r.row.CreateAt
+		case *any:
+			*d = nil
+		default:
+			return fmt.Errorf("unexpected destination %T", d)
+		}
+	}
+
+	return nil
+}
+
+func newBenchRows() *benchRows {
+	return &benchRows{
+		cols: []string{
+			"id", "username", "password", "email", "mobile_phone", "company",
+			"avatar_url", "role", "last_online_at", "create_at", "update_at",
+		},
+		row: Userss{ID: 1, UserName: "user1", Role: 2, LastOnlineAt: 3, CreateAt: time.Now()},
+	}
+}
+
+func BenchmarkMapRow(b *testing.B) {
+	ctx := context.Background()
+	rows := newBenchRows()
+
+	v, err := wrapRows(rows, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	before, after := StructMapper[Userss]()(ctx, v.columnsCopy())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mapOneRow(v, before, after); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMapRowPointer(b *testing.B) {
+	ctx := context.Background()
+	rows := newBenchRows()
+
+	v, err := wrapRows(rows, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	before, after := StructMapper[*Userss]()(ctx, v.columnsCopy())
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := mapOneRow(v, before, after); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkHandWrittenScan is the baseline for BenchmarkMapRow
+func BenchmarkHandWrittenScan(b *testing.B) {
+	rows := newBenchRows()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var u Userss
+		err := rows.Scan(&u.ID, &u.UserName, &u.Password, &u.Email, &u.MobilePhone, &u.Company,
+			&u.AvatarURL, &u.Role, &u.LastOnlineAt, &u.CreateAt, &u.UpdateAt)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
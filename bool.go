@@ -0,0 +1,108 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aarondl/opt"
+)
+
+// BoolValues are the text values that are read as true and false by [WithBoolValues].
+// They are compared ignoring case and surrounding spaces, and numbers are compared
+// with their decimal text e.g. 1 and "1"
+type BoolValues struct {
+	True  []string
+	False []string
+}
+
+// DefaultBoolValues are the values used by common schemas that do not have a boolean type,
+// such as 1/0 in MySQL and SQL Server, or Y/N in Oracle
+var DefaultBoolValues = BoolValues{
+	True:  []string{"1", "t", "true", "y", "yes", "on"},
+	False: []string{"0", "f", "false", "n", "no", "off"},
+}
+
+// WithBoolValues makes the struct mapper read the values of bool fields (and pointers to them)
+// from the text or numbers in values, e.g. [DefaultBoolValues].
+// Other values return an error, and NULL values set the field to its zero value
+//
+//	m := scan.StructMapper[Account](scan.WithBoolValues(scan.BoolValues{
+//	    True:  []string{"Y", "1"},
+//	    False: []string{"N", "0"},
+//	}))
+func WithBoolValues(values BoolValues) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.boolValues = &values
+	}
+}
+
+// parse returns the bool for the text, and false if it is not in the values
+func (b BoolValues) parse(text string) (value bool, ok bool) {
+	text = strings.TrimSpace(text)
+	for _, t := range b.True {
+		if strings.EqualFold(text, t) {
+			return true, true
+		}
+	}
+
+	for _, f := range b.False {
+		if strings.EqualFold(text, f) {
+			return false, true
+		}
+	}
+
+	return false, false
+}
+
+// withBoolValues sets a decoder that reads the values of the bool fields
+// of the mapping that do not have a decoder
+func withBoolValues(m mapping, values BoolValues) {
+	decode := func(src any, dest any) error {
+		v := reflect.ValueOf(dest).Elem()
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		var text string
+		switch src := src.(type) {
+		case bool:
+			v.SetBool(src)
+			return nil
+		case string:
+			text = src
+		case []byte:
+			text = string(src)
+		case int64, int32, int, uint64, float64:
+			text = fmt.Sprint(src)
+		default:
+			return opt.ConvertAssign(v.Addr().Interface(), src)
+		}
+
+		b, ok := values.parse(text)
+		if !ok {
+			return fmt.Errorf("cannot convert %q to bool", text)
+		}
+
+		v.SetBool(b)
+		return nil
+	}
+
+	for i, info := range m {
+		if info.decode != nil || info.typ == nil {
+			continue
+		}
+
+		typ := info.typ
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		if typ.Kind() == reflect.Bool && !reflect.PtrTo(typ).Implements(scannerType) {
+			m[i].decode = decode
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestBoolValues(t *testing.T) {
+	type flags struct {
+		Active   bool
+		Verified *bool
+		Admin    bool
+	}
+
+	rows := newSliceRows([]string{"active", "verified", "admin"},
+		[]any{int64(1), "Y", []byte("yes ")},
+		[]any{int64(0), []byte("n"), "F"},
+		[]any{"T", nil, true},
+	)
+
+	res, err := AllFromRows(context.Background(), StructMapper[flags](WithBoolValues(DefaultBoolValues)), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []flags{
+		{Active: true, Verified: toPtr(true), Admin: true},
+		{Active: false, Verified: toPtr(false), Admin: false},
+		{Active: true, Admin: true},
+	}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	custom := WithBoolValues(BoolValues{True: []string{"J"}, False: []string{"N"}})
+	rows = newSliceRows([]string{"active"}, []any{"j"}, []any{"N"})
+	res, err = AllFromRows(context.Background(), StructMapper[flags](custom), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]flags{{Active: true}, {}}, res); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows([]string{"active"}, []any{"Y"})
+	if _, err := AllFromRows(context.Background(), StructMapper[flags](custom), rows); err == nil {
+		t.Fatal("expected an error for a value that is not in the set")
+	}
+}
@@ -0,0 +1,106 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/aarondl/opt"
+	"golang.org/x/text/encoding"
+)
+
+// columnEncoding is the character encoding of the columns, or of every column if there are none
+type columnEncoding struct {
+	enc     encoding.Encoding
+	columns []string
+}
+
+// WithEncoding makes the struct mapper convert the text values of string fields
+// (and pointers to them) from the character encoding to UTF-8,
+// for legacy databases that return e.g. latin1 or Shift-JIS bytes.
+// If columns are given, only their fields are converted, and the encoding
+// is used instead of one set for every column.
+//
+//	m := scan.StructMapper[Customer](
+//	    scan.WithEncoding(charmap.ISO8859_1),
+//	    scan.WithEncoding(japanese.ShiftJIS, "name_kana"),
+//	)
+func WithEncoding(enc encoding.Encoding, columns ...string) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.encodings = append(opt.encodings, columnEncoding{enc: enc, columns: columns})
+	}
+}
+
+// encodingOf returns the encoding of the column, if any.
+// The last option with the column is used, or else the last option for every column
+func encodingOf(encodings []columnEncoding, column string) encoding.Encoding {
+	var all encoding.Encoding
+	for i := len(encodings) - 1; i >= 0; i-- {
+		e := encodings[i]
+		if len(e.columns) == 0 {
+			if all == nil {
+				all = e.enc
+			}
+			continue
+		}
+
+		for _, c := range e.columns {
+			if c == column {
+				return e.enc
+			}
+		}
+	}
+
+	return all
+}
+
+// withEncodings sets a decoder that converts text values to UTF-8
+// for the string fields of the mapping that do not have a decoder
+func withEncodings(m mapping, encodings []columnEncoding) {
+	for i, info := range m {
+		if info.decode != nil || info.typ == nil {
+			continue
+		}
+
+		typ := info.typ
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		if typ.Kind() != reflect.String || reflect.PtrTo(typ).Implements(scannerType) {
+			continue
+		}
+
+		enc := encodingOf(encodings, info.name)
+		if enc == nil {
+			continue
+		}
+
+		m[i].decode = func(src any, dest any) error {
+			v := reflect.ValueOf(dest).Elem()
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+
+			var text []byte
+			switch src := src.(type) {
+			case []byte:
+				text = src
+			case string:
+				text = []byte(src)
+			default:
+				return opt.ConvertAssign(v.Addr().Interface(), src)
+			}
+
+			decoded, err := enc.NewDecoder().Bytes(text)
+			if err != nil {
+				return fmt.Errorf("cannot decode text: %w", err)
+			}
+
+			v.Set(reflect.ValueOf(string(decoded)).Convert(v.Type()))
+			return nil
+		}
+	}
+}
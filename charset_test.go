@@ -0,0 +1,45 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func TestEncoding(t *testing.T) {
+	type customer struct {
+		ID       int
+		Name     string
+		NameKana *string
+		Raw      []byte
+	}
+
+	latin1, _ := charmap.ISO8859_1.NewEncoder().String("Zoë Ångström")
+	sjis, _ := japanese.ShiftJIS.NewEncoder().String("ヤマダ")
+
+	rows := newSliceRows([]string{"id", "name", "name_kana", "raw"},
+		[]any{1, []byte(latin1), []byte(sjis), []byte(latin1)},
+		[]any{2, "plain", nil, nil},
+	)
+
+	m := StructMapper[customer](
+		WithEncoding(charmap.ISO8859_1),
+		WithEncoding(japanese.ShiftJIS, "name_kana"),
+	)
+
+	customers, err := AllFromRows(context.Background(), m, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []customer{
+		{ID: 1, Name: "Zoë Ångström", NameKana: toPtr("ヤマダ"), Raw: []byte(latin1)},
+		{ID: 2, Name: "plain"},
+	}
+	if diff := cmp.Diff(expected, customers); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
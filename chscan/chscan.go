@@ -0,0 +1,330 @@
+// Package chscan scans the results of queries made with the native
+// protocol of github.com/ClickHouse/clickhouse-go/v2
+package chscan
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/aarondl/opt"
+	"github.com/stephenafamo/scan"
+)
+
+// One scans a single row from the query and maps it to T using a [Queryer]
+func One[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], query string, args ...any) (T, error) {
+	return scan.One(ctx, Wrap(exec), m, query, args...)
+}
+
+// All scans all rows from the query and returns a slice []T of all rows using a [Queryer]
+func All[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], query string, args ...any) ([]T, error) {
+	return scan.All(ctx, Wrap(exec), m, query, args...)
+}
+
+// Cursor returns a cursor that works similar to *sql.Rows
+func Cursor[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], query string, args ...any) (scan.ICursor[T], error) {
+	return scan.Cursor(ctx, Wrap(exec), m, query, args...)
+}
+
+// A Queryer that returns [driver.Rows], such as [driver.Conn]
+type Queryer interface {
+	Query(ctx context.Context, query string, args ...any) (driver.Rows, error)
+}
+
+// Wrap converts a [Queryer] such as [driver.Conn] into a [scan.Queryer]
+// to use it with the functions in the base scan package.
+//
+// The returned queryer implements [scan.FetchSizer], so it can be used
+// with [scan.FetchSize] and [scan.AdaptiveFetchSize]
+func Wrap(exec Queryer) scan.Queryer {
+	return queryer{wrapped: exec}
+}
+
+// WrapSettings is like [Wrap], but sends the settings with every query.
+// Use it instead of [clickhouse.WithSettings] with a fetch size,
+// since the fetch size is added to these settings
+//
+//	exec := chscan.WrapSettings(conn, clickhouse.Settings{"max_threads": 4})
+//	users, err := scan.All(ctx, scan.FetchSize(exec, 1000), scan.StructMapper[User](), query)
+func WrapSettings(exec Queryer, settings clickhouse.Settings) scan.Queryer {
+	return queryer{wrapped: exec, settings: settings}
+}
+
+type queryer struct {
+	wrapped   Queryer
+	settings  clickhouse.Settings
+	fetchSize int
+}
+
+// WithFetchSize implements [scan.FetchSizer] by sending the size as the
+// max_block_size setting of the query. The settings of the query are replaced,
+// so settings given to the context with [clickhouse.WithSettings] are not sent.
+// Use [WrapSettings] to send other settings with the fetch size
+func (q queryer) WithFetchSize(size int) scan.Queryer {
+	return queryer{wrapped: q.wrapped, settings: q.settings, fetchSize: size}
+}
+
+func (q queryer) QueryContext(ctx context.Context, query string, args ...any) (scan.Rows, error) {
+	if settings := q.querySettings(); settings != nil {
+		ctx = clickhouse.Context(ctx, clickhouse.WithSettings(settings))
+	}
+
+	rows, err := q.wrapped.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRows(rows), nil
+}
+
+// querySettings returns the settings to send with the query, if any
+func (q queryer) querySettings() clickhouse.Settings {
+	if q.fetchSize <= 0 && len(q.settings) == 0 {
+		return nil
+	}
+
+	settings := make(clickhouse.Settings, len(q.settings)+1)
+	for k, v := range q.settings {
+		settings[k] = v
+	}
+	if q.fetchSize > 0 {
+		settings["max_block_size"] = q.fetchSize
+	}
+
+	return settings
+}
+
+// NewRows converts [driver.Rows] into [scan.Rows].
+//
+// The native protocol can only scan into the exact type of a column,
+// so each column is scanned into its own type and then converted to the destination:
+//   - Numbers, strings and times are converted the same way as database/sql
+//   - Arrays are converted into slices or arrays, element by element
+//   - Tuples are converted into structs, by position or by name for named tuples
+//   - Maps are converted key by key
+//   - NULL values set the destination to its zero value
+func NewRows(rows driver.Rows) scan.Rows {
+	return &chRows{rows: rows}
+}
+
+type chRows struct {
+	rows  driver.Rows
+	types []reflect.Type
+}
+
+func (r *chRows) Columns() ([]string, error) {
+	return r.rows.Columns(), nil
+}
+
+func (r *chRows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r *chRows) Close() error {
+	return r.rows.Close()
+}
+
+func (r *chRows) Err() error {
+	return r.rows.Err()
+}
+
+func (r *chRows) Scan(dest ...any) error {
+	if r.types == nil {
+		for _, ct := range r.rows.ColumnTypes() {
+			r.types = append(r.types, ct.ScanType())
+		}
+	}
+
+	if len(dest) != len(r.types) {
+		return fmt.Errorf("chscan: expected %d destinations, got %d", len(r.types), len(dest))
+	}
+
+	native := make([]any, len(r.types))
+	for i, typ := range r.types {
+		native[i] = reflect.New(typ).Interface()
+	}
+
+	if err := r.rows.Scan(native...); err != nil {
+		return err
+	}
+
+	for i, d := range dest {
+		dst := reflect.ValueOf(d)
+		if dst.Kind() != reflect.Pointer || dst.IsNil() {
+			return fmt.Errorf("chscan: destination %d is not a pointer", i)
+		}
+
+		if err := assign(dst.Elem(), reflect.ValueOf(native[i]).Elem()); err != nil {
+			return fmt.Errorf("chscan: column index %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// assign converts the value of src and sets it to dst
+func assign(dst, src reflect.Value) error {
+	for src.Kind() == reflect.Interface || src.Kind() == reflect.Pointer {
+		if src.IsNil() {
+			return assignNull(dst)
+		}
+		src = src.Elem()
+	}
+
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+
+	if (src.Kind() == reflect.Slice || src.Kind() == reflect.Map) && src.IsNil() {
+		return assignNull(dst)
+	}
+
+	if dst.Addr().Type().Implements(scannerType) {
+		return dst.Addr().Interface().(sql.Scanner).Scan(src.Interface())
+	}
+
+	switch dst.Kind() {
+	case reflect.Pointer:
+		ptr := reflect.New(dst.Type().Elem())
+		if err := assign(ptr.Elem(), src); err != nil {
+			return err
+		}
+		dst.Set(ptr)
+		return nil
+
+	case reflect.Slice:
+		if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+			break
+		}
+		slice := reflect.MakeSlice(dst.Type(), src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := assign(slice.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+		return nil
+
+	case reflect.Array:
+		if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+			break
+		}
+		if src.Len() != dst.Len() {
+			return fmt.Errorf("cannot convert %d values into %s", src.Len(), dst.Type())
+		}
+		for i := 0; i < src.Len(); i++ {
+			if err := assign(dst.Index(i), src.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if src.Kind() != reflect.Map {
+			break
+		}
+		m := reflect.MakeMapWithSize(dst.Type(), src.Len())
+		iter := src.MapRange()
+		for iter.Next() {
+			k := reflect.New(dst.Type().Key()).Elem()
+			v := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(k, iter.Key()); err != nil {
+				return err
+			}
+			if err := assign(v, iter.Value()); err != nil {
+				return err
+			}
+			m.SetMapIndex(k, v)
+		}
+		dst.Set(m)
+		return nil
+
+	case reflect.Struct:
+		switch src.Kind() {
+		case reflect.Slice, reflect.Array:
+			return assignTuple(dst, src)
+		case reflect.Map:
+			return assignNamedTuple(dst, src)
+		}
+	}
+
+	if src.Type().ConvertibleTo(dst.Type()) && src.Kind() == dst.Kind() {
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+
+	return opt.ConvertAssign(dst.Addr().Interface(), src.Interface())
+}
+
+func assignNull(dst reflect.Value) error {
+	if dst.Addr().Type().Implements(scannerType) {
+		return dst.Addr().Interface().(sql.Scanner).Scan(nil)
+	}
+
+	dst.Set(reflect.Zero(dst.Type()))
+	return nil
+}
+
+// assignTuple sets the exported fields of the struct in order
+func assignTuple(dst, src reflect.Value) error {
+	fields := exportedFields(dst.Type())
+	if len(fields) != src.Len() {
+		return fmt.Errorf("cannot convert a tuple of %d values into %s", src.Len(), dst.Type())
+	}
+
+	for i, f := range fields {
+		if err := assign(dst.Field(f.Index[0]), src.Index(i)); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// assignNamedTuple sets the exported fields of the struct by name.
+// A field matches the name in its db tag, its snake case name, or its name
+func assignNamedTuple(dst, src reflect.Value) error {
+	for _, f := range exportedFields(dst.Type()) {
+		for _, name := range []string{tagName(f), scan.SnakeCase(f.Name), f.Name} {
+			if name == "" {
+				continue
+			}
+
+			v := src.MapIndex(reflect.ValueOf(name).Convert(src.Type().Key()))
+			if !v.IsValid() {
+				continue
+			}
+
+			if err := assign(dst.Field(f.Index[0]), v); err != nil {
+				return fmt.Errorf("field %s: %w", f.Name, err)
+			}
+			break
+		}
+	}
+
+	return nil
+}
+
+func exportedFields(typ reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		if f := typ.Field(i); f.IsExported() && tagName(f) != "-" {
+			fields = append(fields, f)
+		}
+	}
+
+	return fields
+}
+
+// tagName returns the name in the db tag of the field, without its options
+func tagName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("db"), ",")
+	return name
+}
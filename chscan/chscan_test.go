@@ -0,0 +1,225 @@
+package chscan
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/go-cmp/cmp"
+	"github.com/stephenafamo/scan"
+)
+
+type columnType struct {
+	name string
+	typ  reflect.Type
+}
+
+func (c columnType) Name() string             { return c.name }
+func (c columnType) Nullable() bool           { return c.typ.Kind() == reflect.Pointer }
+func (c columnType) ScanType() reflect.Type   { return c.typ }
+func (c columnType) DatabaseTypeName() string { return c.typ.String() }
+
+// rows behaves like the native rows, which only scan into the exact column type
+type rows struct {
+	driver.Rows
+	types []columnType
+	data  [][]any
+	index int
+}
+
+func (r *rows) ColumnTypes() []driver.ColumnType {
+	types := make([]driver.ColumnType, len(r.types))
+	for i, t := range r.types {
+		types[i] = t
+	}
+	return types
+}
+
+func (r *rows) Columns() []string {
+	cols := make([]string, len(r.types))
+	for i, t := range r.types {
+		cols[i] = t.name
+	}
+	return cols
+}
+
+func (r *rows) Next() bool   { r.index++; return r.index <= len(r.data) }
+func (r *rows) Close() error { return nil }
+func (r *rows) Err() error   { return nil }
+
+func (r *rows) Scan(dest ...any) error {
+	for i, d := range dest {
+		dst := reflect.ValueOf(d).Elem()
+		if dst.Type() != r.types[i].typ {
+			return errors.New("wrong destination type " + dst.Type().String())
+		}
+		if v := r.data[r.index-1][i]; v != nil {
+			dst.Set(reflect.ValueOf(v))
+		}
+	}
+	return nil
+}
+
+type fakeConn struct {
+	rows *rows
+}
+
+func (q fakeConn) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	return q.rows, nil
+}
+
+type point struct {
+	X float64
+	Y float64
+}
+
+type owner struct {
+	FirstName string
+	Age       int
+	Email     string `db:"contact_email,omitempty"`
+}
+
+type event struct {
+	ID     int
+	Name   *string
+	Tags   []string
+	Scores []int
+	Attrs  map[string]int
+}
+
+func TestAll(t *testing.T) {
+	name := "click"
+	exec := fakeConn{rows: &rows{
+		types: []columnType{
+			{name: "id", typ: reflect.TypeOf(uint64(0))},
+			{name: "name", typ: reflect.TypeOf((*string)(nil))},
+			{name: "tags", typ: reflect.TypeOf([]string{})},
+			{name: "scores", typ: reflect.TypeOf([]uint8{})},
+			{name: "attrs", typ: reflect.TypeOf(map[string]uint16{})},
+		},
+		data: [][]any{
+			{uint64(1), &name, []string{"a", "b"}, []uint8{1, 2}, map[string]uint16{"x": 1}},
+			{uint64(2), nil, nil, nil, nil},
+		},
+	}}
+
+	events, err := All(context.Background(), exec, scan.StructMapper[event](), "SELECT * FROM events")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	first := event{ID: 1, Name: &name, Tags: []string{"a", "b"}, Scores: []int{1, 2}, Attrs: map[string]int{"x": 1}}
+	if diff := cmp.Diff([]event{first, {ID: 2}}, events); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestTuples(t *testing.T) {
+	exec := fakeConn{rows: &rows{
+		types: []columnType{
+			{name: "point", typ: reflect.TypeOf([]any{})},
+			{name: "owner", typ: reflect.TypeOf(map[string]any{})},
+		},
+		data: [][]any{{[]any{1.5, 2.5}, map[string]any{"first_name": "Jane", "age": uint8(30), "contact_email": "jane@example.com"}}},
+	}}
+
+	type pointAndOwner struct {
+		Point point
+		Owner owner
+	}
+
+	m := func(ctx context.Context, c []string) (scan.BeforeFunc, func(any) (pointAndOwner, error)) {
+		return func(r *scan.Row) (any, error) {
+				var v pointAndOwner
+				r.ScheduleScan("point", &v.Point)
+				r.ScheduleScan("owner", &v.Owner)
+				return &v, nil
+			}, func(link any) (pointAndOwner, error) {
+				return *link.(*pointAndOwner), nil
+			}
+	}
+
+	got, err := One(context.Background(), exec, m, "SELECT point, owner FROM events")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := pointAndOwner{Point: point{X: 1.5, Y: 2.5}, Owner: owner{FirstName: "Jane", Age: 30, Email: "jane@example.com"}}
+	if diff := cmp.Diff(expected, got); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestTupleLength(t *testing.T) {
+	exec := fakeConn{rows: &rows{
+		types: []columnType{{name: "point", typ: reflect.TypeOf([]any{})}},
+		data:  [][]any{{[]any{1.5}}},
+	}}
+
+	_, err := All(context.Background(), exec, scan.SingleColumnMapper[point], "SELECT point FROM events")
+	if err == nil {
+		t.Fatal("expected an error for a tuple with the wrong length")
+	}
+}
+
+func TestScanError(t *testing.T) {
+	exec := fakeConn{rows: &rows{
+		types: []columnType{
+			{name: "id", typ: reflect.TypeOf(uint64(0))},
+			{name: "name", typ: reflect.TypeOf("")},
+		},
+		data: [][]any{{uint64(1), "click"}},
+	}}
+
+	type badEvent struct {
+		ID   int
+		Name int
+	}
+
+	_, err := All(context.Background(), exec, scan.StructMapper[badEvent](), "SELECT id, name FROM events")
+
+	var scanErr *scan.Error
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("expected a *scan.Error, got %T: %v", err, err)
+	}
+
+	if scanErr.Column() != "name" {
+		t.Fatalf("expected column %q, got %q", "name", scanErr.Column())
+	}
+}
+
+func TestWithFetchSize(t *testing.T) {
+	exec := fakeConn{rows: &rows{
+		types: []columnType{{name: "id", typ: reflect.TypeOf(uint64(0))}},
+		data:  [][]any{{uint64(1)}},
+	}}
+
+	settings := clickhouse.Settings{"max_threads": 2}
+	sized := WrapSettings(exec, settings).(scan.FetchSizer).WithFetchSize(100)
+	if _, err := scan.All(context.Background(), sized, scan.SingleColumnMapper[int], "SELECT id"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := map[string]struct {
+		q        scan.Queryer
+		expected clickhouse.Settings
+	}{
+		"none":       {q: Wrap(exec), expected: nil},
+		"settings":   {q: WrapSettings(exec, settings), expected: settings},
+		"fetch size": {q: Wrap(exec).(scan.FetchSizer).WithFetchSize(10), expected: clickhouse.Settings{"max_block_size": 10}},
+		"both":       {q: sized, expected: clickhouse.Settings{"max_threads": 2, "max_block_size": 100}},
+	}
+
+	for name, c := range cases {
+		if diff := cmp.Diff(c.expected, c.q.(queryer).querySettings()); diff != "" {
+			t.Fatalf("%s: diff: %s", name, diff)
+		}
+	}
+
+	if diff := cmp.Diff(clickhouse.Settings{"max_threads": 2}, settings); diff != "" {
+		t.Fatalf("expected the settings to be unchanged: %s", diff)
+	}
+}
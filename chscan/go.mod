@@ -0,0 +1,30 @@
+module github.com/stephenafamo/scan/chscan
+
+go 1.18
+
+require (
+	github.com/ClickHouse/clickhouse-go/v2 v2.6.0
+	github.com/aarondl/opt v0.0.0-20221129170750-3d40c96d9bb8
+	github.com/google/go-cmp v0.5.9
+	github.com/stephenafamo/scan v0.7.0
+)
+
+require (
+	github.com/ClickHouse/ch-go v0.51.2 // indirect
+	github.com/aarondl/json v0.0.0-20221020222930-8b0db17ef1bf // indirect
+	github.com/andybalholm/brotli v1.0.4 // indirect
+	github.com/go-faster/city v1.0.1 // indirect
+	github.com/go-faster/errors v0.6.1 // indirect
+	github.com/google/uuid v1.3.0 // indirect
+	github.com/klauspost/compress v1.15.14 // indirect
+	github.com/paulmach/orb v0.8.0 // indirect
+	github.com/pierrec/lz4/v4 v4.1.17 // indirect
+	github.com/pkg/errors v0.9.1 // indirect
+	github.com/segmentio/asm v1.2.0 // indirect
+	github.com/shopspring/decimal v1.3.1 // indirect
+	go.opentelemetry.io/otel v1.11.2 // indirect
+	go.opentelemetry.io/otel/trace v1.11.2 // indirect
+	golang.org/x/sys v0.0.0-20220919091848-fb04ddd9f9c8 // indirect
+	golang.org/x/text v0.3.8 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
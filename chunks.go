@@ -0,0 +1,76 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+)
+
+// Chunks runs the query and calls fn with the rows in batches of size,
+// for pipelines that want bounded memory and batched downstream writes.
+// The last batch may have fewer rows.
+//
+// The slice passed to fn is reused for the next batch, so fn must copy it to keep it.
+// Scanning stops at the first error returned by fn, or when the context is done
+func Chunks[T any](ctx context.Context, exec Queryer, m Mapper[T], size int, fn func([]T) error, query string, args ...any) error {
+	if size <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", size)
+	}
+
+	rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return withQuery(ChunksFromRows(ctx, m, size, fn, rows), query)
+}
+
+// ChunksFromRows calls fn with the rows of the given [Rows] in batches of size. See [Chunks]
+func ChunksFromRows[T any](ctx context.Context, m Mapper[T], size int, fn func([]T) error, rows Rows) error {
+	if size <= 0 {
+		return fmt.Errorf("chunk size must be positive, got %d", size)
+	}
+
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+	v, err := wrapRows(rows, allowUnknown)
+	if err != nil {
+		return err
+	}
+
+	before, after := buildMapper(ctx, m, v, v.columnsCopy())
+
+	chunk := make([]T, 0, size)
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		one, err := scanOneRow(v, before, after)
+		if isSkipped(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		chunk = append(chunk, one)
+		if len(chunk) < size {
+			continue
+		}
+
+		if err := fn(chunk); err != nil {
+			return err
+		}
+		chunk = chunk[:0]
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(chunk) > 0 {
+		return fn(chunk)
+	}
+
+	return nil
+}
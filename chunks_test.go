@@ -0,0 +1,48 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChunks(t *testing.T) {
+	ctx := context.Background()
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		rows := make([][]any, 5)
+		for i := range rows {
+			rows[i] = []any{i + 1}
+		}
+		return newSliceRows([]string{"id"}, rows...), nil
+	})
+
+	var chunks [][]int
+	err := Chunks(ctx, exec, SingleColumnMapper[int], 2, func(ids []int) error {
+		chunks = append(chunks, append([]int(nil), ids...))
+		return nil
+	}, "SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([][]int{{1, 2}, {3, 4}, {5}}, chunks); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	errStop := errors.New("stop")
+	var calls int
+	err = Chunks(ctx, exec, SingleColumnMapper[int], 2, func(ids []int) error {
+		calls++
+		return errStop
+	}, "SELECT id FROM users")
+	if !errors.Is(err, errStop) || calls != 1 {
+		t.Fatalf("expected to stop after the first chunk, got %v after %d calls", err, calls)
+	}
+
+	err = Chunks(ctx, exec, SingleColumnMapper[int], 0, func([]int) error { return nil }, "SELECT id FROM users")
+	if err == nil {
+		t.Fatal("expected an error for a chunk size of 0")
+	}
+}
@@ -0,0 +1,318 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Where builds a `WHERE col1 = ? AND col2 = ?` clause from a filter struct
+// using the same column names as [StructMapper].
+// Nil pointers and zero values are skipped, and the column names are quoted if needed.
+// If no field is set or the filter is nil, an empty clause and no args are returned.
+func Where(filter any) (string, []any, error) {
+	return CustomWhere(defaultStructMapper, filter)
+}
+
+// CustomWhere is like [Where] but uses a custom struct mapping source
+// which should have been created with [NewStructMapperSource]
+func CustomWhere(src StructMapperSource, filter any) (string, []any, error) {
+	val := reflect.ValueOf(filter)
+	if !val.IsValid() {
+		return "", nil, nil
+	}
+
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return "", nil, nil
+		}
+		val = val.Elem()
+	}
+
+	if _, err := checks(val.Type()); err != nil {
+		return "", nil, err
+	}
+
+	// Unexported fields can only be read from an addressable value
+	if !val.CanAddr() {
+		addr := reflect.New(val.Type()).Elem()
+		addr.Set(val)
+		val = addr
+	}
+
+	m, err := src.getMapping(val.Type())
+	if err != nil {
+		return "", nil, err
+	}
+	m = m.withContainers(nil)
+
+	conditions := make([]string, 0, len(m))
+	args := make([]any, 0, len(m))
+	for _, info := range m {
+		fv, ok := fieldByIndex(val, info.position)
+		if !ok || info.name == "" {
+			continue
+		}
+
+		if fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		} else if fv.IsZero() {
+			continue
+		}
+
+		conditions = append(conditions, fmt.Sprintf("%s = ?", quoteIdentifier(info.name)))
+		args = append(args, fv.Interface())
+	}
+
+	if len(conditions) == 0 {
+		return "", nil, nil
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), args, nil
+}
+
+// ColumnValues returns the columns that [StructMapper] maps for the struct v and the value
+// of the field of each column, in the order of the fields.
+// It is the reverse of the mapping, e.g. to build an `INSERT` statement.
+// Fields inside structs reached through a nil pointer are skipped, and nil returns no columns.
+func ColumnValues(v any) ([]string, []any, error) {
+	return CustomColumnValues(defaultStructMapper, v)
+}
+
+// CustomColumnValues is like [ColumnValues] but uses a custom struct mapping source
+// which should have been created with [NewStructMapperSource]
+func CustomColumnValues(src StructMapperSource, v any) ([]string, []any, error) {
+	val := reflect.ValueOf(v)
+	if !val.IsValid() {
+		return nil, nil, nil
+	}
+
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return nil, nil, nil
+		}
+		val = val.Elem()
+	}
+
+	if _, err := checks(val.Type()); err != nil {
+		return nil, nil, err
+	}
+
+	// Unexported fields can only be read from an addressable value
+	if !val.CanAddr() {
+		addr := reflect.New(val.Type()).Elem()
+		addr.Set(val)
+		val = addr
+	}
+
+	m, err := src.getMapping(val.Type())
+	if err != nil {
+		return nil, nil, err
+	}
+	m = m.withContainers(nil)
+
+	cols := make([]string, 0, len(m))
+	vals := make([]any, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, info := range m {
+		fv, ok := fieldByIndex(val, info.position)
+		if !ok || info.name == "" || seen[info.name] {
+			continue
+		}
+		seen[info.name] = true
+
+		cols = append(cols, info.name)
+		vals = append(vals, fv.Interface())
+	}
+
+	return cols, vals, nil
+}
+
+// Columns returns the SELECT list of the columns that [StructMapper] maps for T,
+// so that queries stay in sync with the struct. With a prefix, usually the alias of the table,
+// each column is qualified with it and aliased with the prefix and a dot, e.g. for "u":
+//
+//	u.id AS "u.id", u.name AS "u.name"
+//
+// which is mapped back with [WithStructTagPrefix]("u."), e.g. when joining several tables.
+// Column names that are not plain identifiers, such as the ones of nested structs, are quoted.
+// It panics if T is not a struct or a pointer to a struct, use [ColumnList] to get an error instead
+//
+//	query := "SELECT " + scan.Columns[User]("u") + ", " + scan.Columns[Team]("t") +
+//	    " FROM users u JOIN teams t ON t.id = u.team_id"
+func Columns[T any](prefix string) string {
+	return CustomColumns[T](defaultStructMapper, prefix)
+}
+
+// CustomColumns is like [Columns] but uses a custom struct mapping source
+// which should have been created with [NewStructMapperSource]
+func CustomColumns[T any](src StructMapperSource, prefix string) string {
+	list, err := CustomColumnList[T](src, prefix)
+	if err != nil {
+		panic(err)
+	}
+
+	return strings.Join(list, ", ")
+}
+
+// ColumnList is like [Columns] but returns each item of the SELECT list on its own,
+// and an error instead of panicking if T is not a struct or a pointer to a struct
+func ColumnList[T any](prefix string) ([]string, error) {
+	return CustomColumnList[T](defaultStructMapper, prefix)
+}
+
+// CustomColumnList is like [ColumnList] but uses a custom struct mapping source
+// which should have been created with [NewStructMapperSource]
+func CustomColumnList[T any](src StructMapperSource, prefix string) ([]string, error) {
+	typ := typeOf[T]()
+	if _, err := checks(typ); err != nil {
+		return nil, err
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	m, err := src.getMapping(typ)
+	if err != nil {
+		return nil, err
+	}
+	m = m.withContainers(nil)
+
+	list := make([]string, 0, len(m))
+	seen := make(map[string]bool, len(m))
+	for _, info := range m {
+		if info.name == "" || seen[info.name] {
+			continue
+		}
+		seen[info.name] = true
+
+		col := quoteIdentifier(info.name)
+		if prefix == "" {
+			list = append(list, col)
+			continue
+		}
+
+		alias := strings.ReplaceAll(prefix+"."+info.name, `"`, `""`)
+		list = append(list, fmt.Sprintf(`%s.%s AS "%s"`, prefix, col, alias))
+	}
+
+	return list, nil
+}
+
+// quoteIdentifier quotes the name with double quotes unless it is made of
+// letters, digits and underscores, and does not start with a digit
+func quoteIdentifier(name string) string {
+	plain := true
+	for i, r := range name {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !(i > 0 && '0' <= r && r <= '9') {
+			plain = false
+			break
+		}
+	}
+
+	if plain {
+		return name
+	}
+
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// fieldByIndex is like [reflect.Value.FieldByIndex] but returns false
+// instead of panicking when it encounters a nil pointer
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = exposeField(v.Field(x))
+	}
+
+	return v, true
+}
+
+// OrderBy builds an `ORDER BY` clause from user supplied sort fields.
+// Each field must be one of the columns [StructMapper] would map for T,
+// otherwise an error is returned, so it is safe to use with untrusted input.
+//
+// A field can be prefixed with "-" for descending order or "+" for ascending order,
+// or followed by " ASC" or " DESC".
+// If no field is given, an empty clause is returned.
+func OrderBy[T any](fields ...string) (string, error) {
+	return CustomOrderBy[T](defaultStructMapper, fields...)
+}
+
+// CustomOrderBy is like [OrderBy] but uses a custom struct mapping source
+// which should have been created with [NewStructMapperSource]
+func CustomOrderBy[T any](src StructMapperSource, fields ...string) (string, error) {
+	if len(fields) == 0 {
+		return "", nil
+	}
+
+	typ := typeOf[T]()
+	if _, err := checks(typ); err != nil {
+		return "", err
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	m, err := src.getMapping(typ)
+	if err != nil {
+		return "", err
+	}
+	m = m.withContainers(nil)
+
+	known := make(map[string]bool, len(m))
+	for _, info := range m {
+		known[info.name] = true
+	}
+
+	terms := make([]string, len(fields))
+	for i, field := range fields {
+		col, dir, err := parseSortField(field)
+		if err != nil {
+			return "", err
+		}
+
+		if col == "" || !known[col] {
+			return "", fmt.Errorf("unknown sort field %q", field)
+		}
+
+		terms[i] = quoteIdentifier(col) + " " + dir
+	}
+
+	return "ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+func parseSortField(field string) (col string, dir string, err error) {
+	field = strings.TrimSpace(field)
+
+	switch {
+	case strings.HasPrefix(field, "-"):
+		return field[1:], "DESC", nil
+	case strings.HasPrefix(field, "+"):
+		return field[1:], "ASC", nil
+	}
+
+	col, dir, found := strings.Cut(field, " ")
+	if !found {
+		return col, "ASC", nil
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(dir)) {
+	case "ASC":
+		return col, "ASC", nil
+	case "DESC":
+		return col, "DESC", nil
+	default:
+		return "", "", fmt.Errorf("invalid sort direction in %q", field)
+	}
+}
@@ -0,0 +1,233 @@
+package scan
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWhere(t *testing.T) {
+	type Filter struct {
+		ID     *int
+		Name   string
+		Email  string `db:"EMAIL"`
+		Ignore string `db:"-"`
+		*PtrTimestamps
+	}
+
+	cases := map[string]struct {
+		filter any
+		clause string
+		args   []any
+	}{
+		"nil": {
+			filter: nil,
+		},
+		"nil pointer": {
+			filter: (*Filter)(nil),
+		},
+		"empty": {
+			filter: Filter{},
+		},
+		"zero pointer value": {
+			filter: Filter{ID: toPtr(0)},
+			clause: "WHERE id = ?",
+			args:   []any{0},
+		},
+		"multiple": {
+			filter: &Filter{Name: "foo", Email: "foo@example.com", Ignore: "ignored"},
+			clause: "WHERE name = ? AND EMAIL = ?",
+			args:   []any{"foo", "foo@example.com"},
+		},
+		"embedded": {
+			filter: Filter{Name: "foo", PtrTimestamps: &PtrTimestamps{CreatedAt: &now}},
+			clause: "WHERE name = ? AND created_at = ?",
+			args:   []any{"foo", now},
+		},
+		"nested": {
+			filter: Blog{User: UserWithTimestamps{User: User{Name: "foo"}}},
+			clause: `WHERE "user.name" = ?`,
+			args:   []any{"foo"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clause, args, err := Where(tc.filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if clause != tc.clause {
+				t.Fatalf("wrong clause.\nExpected: %s\nGot: %s", tc.clause, clause)
+			}
+
+			if diff := cmp.Diff(tc.args, args); diff != "" {
+				t.Fatalf("diff: %s", diff)
+			}
+		})
+	}
+
+	if _, _, err := Where(1); err == nil {
+		t.Fatal("expected error for non-struct filter")
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	cases := map[string]struct {
+		fields []string
+		clause string
+		err    bool
+	}{
+		"empty": {},
+		"single": {
+			fields: []string{"name"},
+			clause: "ORDER BY name ASC",
+		},
+		"prefixes": {
+			fields: []string{"-created_at", "+id"},
+			clause: "ORDER BY created_at DESC, id ASC",
+		},
+		"suffixes": {
+			fields: []string{"name desc", "id ASC"},
+			clause: "ORDER BY name DESC, id ASC",
+		},
+		"nested": {
+			fields: []string{"-blog.id", "name"},
+			clause: `ORDER BY "blog.id" DESC, name ASC`,
+		},
+		"unknown field": {
+			fields: []string{"password"},
+			err:    true,
+		},
+		"injection": {
+			fields: []string{"id; DROP TABLE users"},
+			err:    true,
+		},
+		"bad direction": {
+			fields: []string{"id sideways"},
+			err:    true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			clause, err := OrderBy[*UserWithTimestamps](tc.fields...)
+			if tc.err != (err != nil) {
+				t.Fatalf("unexpected error state: %v", err)
+			}
+
+			if clause != tc.clause {
+				t.Fatalf("wrong clause.\nExpected: %s\nGot: %s", tc.clause, clause)
+			}
+		})
+	}
+}
+
+func TestColumnValues(t *testing.T) {
+	type Row struct {
+		ID     int
+		Name   *string
+		Email  string `db:"EMAIL"`
+		Ignore string `db:"-"`
+		*PtrTimestamps
+	}
+
+	cols, vals, err := ColumnValues(Row{ID: 1, Email: "foo@example.com"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"id", "name", "EMAIL"}, cols); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if diff := cmp.Diff([]any{1, (*string)(nil), "foo@example.com"}, vals); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	cols, vals, err = ColumnValues(&Row{PtrTimestamps: &PtrTimestamps{CreatedAt: &now}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"id", "name", "EMAIL", "created_at", "updated_at"}, cols); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if vals[3] != &now {
+		t.Fatalf("expected the created_at pointer, got %v", vals[3])
+	}
+
+	if cols, _, err := ColumnValues(nil); err != nil || len(cols) != 0 {
+		t.Fatalf("expected no columns for nil, got %v %v", cols, err)
+	}
+
+	if _, _, err := ColumnValues(1); err == nil {
+		t.Fatal("expected an error for a non-struct value")
+	}
+}
+
+func TestColumns(t *testing.T) {
+	type Address struct {
+		City string
+	}
+
+	type Row struct {
+		ID      int
+		Email   string `db:"EMAIL"`
+		Ignore  string `db:"-"`
+		Address Address
+		*PtrTimestamps
+	}
+
+	if diff := cmp.Diff(`id, EMAIL, "address.city", created_at, updated_at`, Columns[Row]("")); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	expected := []string{
+		`u.id AS "u.id"`,
+		`u.EMAIL AS "u.EMAIL"`,
+		`u."address.city" AS "u.address.city"`,
+		`u.created_at AS "u.created_at"`,
+		`u.updated_at AS "u.updated_at"`,
+	}
+	list, err := ColumnList[*Row]("u")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(expected, list); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The aliases are mapped back with the prefix
+	cols := make([]string, len(expected))
+	for i, item := range expected {
+		_, alias, _ := strings.Cut(item, " AS ")
+		cols[i] = strings.Trim(alias, `"`)
+	}
+
+	rows, err := AllFromRows(context.Background(), StructMapper[Row](WithStructTagPrefix("u.")),
+		newSliceRows(cols, []any{1, "a@example.com", "Paris", nil, nil}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]Row{{ID: 1, Email: "a@example.com", Address: Address{City: "Paris"}, PtrTimestamps: &PtrTimestamps{}}}, rows); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if _, err := ColumnList[int](""); err == nil {
+		t.Fatal("expected an error for a non-struct type")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic for a non-struct type")
+		}
+	}()
+	Columns[int]("")
+}
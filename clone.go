@@ -0,0 +1,91 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Clone returns a copy of v that shares no mapped values with it,
+// e.g. to hand out values from a cache or an identity map.
+//
+// It uses the field mapping of [StructMapper], which is computed once per type,
+// so only the fields that would be scanned into are copied deeply,
+// along with the structs they are reached through.
+// Other fields are copied as they are. Values that are not structs
+// or pointers to structs are copied deeply. See [Freeze] to copy every field.
+func Clone[T any](v T) T {
+	return CustomClone(defaultStructMapper, v)
+}
+
+// CustomClone is the same as [Clone] but uses the mapping of the given [StructMapperSource]
+func CustomClone[T any](src StructMapperSource, v T) T {
+	val := reflect.ValueOf(v)
+	if !val.IsValid() {
+		return v
+	}
+
+	isPointer := val.Kind() == reflect.Pointer
+	if isPointer {
+		if val.IsNil() {
+			return v
+		}
+		val = val.Elem()
+	}
+
+	if val.Kind() != reflect.Struct {
+		return deepCopy(v)
+	}
+
+	m, err := src.getMapping(val.Type())
+	if err != nil {
+		return deepCopy(v)
+	}
+
+	// Start from a shallow copy and replace the mapped values
+	c := reflect.New(val.Type()).Elem()
+	c.Set(val)
+	cloneMapped(c, m)
+
+	if isPointer {
+		return c.Addr().Interface().(T)
+	}
+
+	return c.Interface().(T)
+}
+
+// cloneMapped replaces the mapped values of the shallow copy v with deep copies.
+// Structs reached through a pointer are copied once before their fields
+func cloneMapped(v reflect.Value, m mapping) {
+	seen := make(map[uintptr]reflect.Value)
+	inits := make(map[string]bool)
+
+	for _, info := range m {
+		if info.container {
+			continue
+		}
+
+		for _, index := range info.init {
+			key := fmt.Sprint(index)
+			if inits[key] {
+				continue
+			}
+			inits[key] = true
+
+			pv, ok := fieldByIndex(v, index)
+			if !ok || pv.IsNil() {
+				continue
+			}
+
+			p := reflect.New(pv.Type().Elem())
+			p.Elem().Set(pv.Elem())
+			pv.Set(p)
+		}
+
+		fv, ok := fieldByIndex(v, info.position)
+		if !ok {
+			continue
+		}
+
+		fv.Set(copyValue(fv, seen))
+	}
+}
@@ -0,0 +1,67 @@
+package scan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Profile struct {
+	*User
+	Tags    []string
+	Meta    map[string]string `db:"meta,json"`
+	Avatar  *string
+	Updated time.Time
+	Extra   map[string]any `db:",remain"`
+	Cache   []int          `db:"-"`
+}
+
+func TestClone(t *testing.T) {
+	avatar := "a.png"
+	original := Profile{
+		User:    &User{ID: 1, Name: "foo"},
+		Tags:    []string{"a"},
+		Meta:    map[string]string{"k": "v"},
+		Avatar:  &avatar,
+		Updated: time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+		Extra:   map[string]any{"age": 30},
+		Cache:   []int{1},
+	}
+
+	clone := Clone(original)
+	if diff := cmp.Diff(original, clone); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	clone.User.Name = "changed"
+	clone.Tags[0] = "changed"
+	clone.Meta["k"] = "changed"
+	*clone.Avatar = "changed"
+	clone.Extra["age"] = 0
+
+	if original.Name != "foo" || original.Tags[0] != "a" || original.Meta["k"] != "v" ||
+		avatar != "a.png" || original.Extra["age"] != 30 {
+		t.Fatalf("the original was changed: %+v", original)
+	}
+
+	// Fields that are not mapped are copied as they are
+	if &clone.Cache[0] != &original.Cache[0] {
+		t.Fatal("expected the unmapped field to be shared")
+	}
+
+	// Pointers to structs, nil pointers and other values
+	ptr := &Profile{Tags: []string{"b"}}
+	if c := Clone(ptr); c == ptr || c.User != nil || &c.Tags[0] == &ptr.Tags[0] {
+		t.Fatalf("expected a copy of the pointer, got %+v", c)
+	}
+
+	if c := Clone[*Profile](nil); c != nil {
+		t.Fatalf("expected nil, got %+v", c)
+	}
+
+	tags := []string{"c"}
+	if c := Clone(tags); &c[0] == &tags[0] {
+		t.Fatal("expected the slice to be copied")
+	}
+}
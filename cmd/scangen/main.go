@@ -0,0 +1,385 @@
+// Command scangen generates reflection-free mappers for structs.
+//
+// For every selected struct, it generates a MapValues method which
+// [scan.StructMapper] detects and uses instead of reflection.
+// Structs are selected with the -type flag, or by annotating
+// the type declaration with a //scan:mapper comment.
+//
+// Usage:
+//
+//	//go:generate go run github.com/stephenafamo/scan/cmd/scangen -type User,Blog
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/stephenafamo/scan"
+)
+
+const annotation = "//scan:mapper"
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("scangen: ")
+
+	cfg := config{}
+	var types string
+	flag.StringVar(&types, "type", "", "comma-separated list of type names; defaults to types annotated with "+annotation)
+	flag.StringVar(&cfg.output, "output", "scan_gen.go", "output file name")
+	flag.StringVar(&cfg.tagKey, "tag", "db", "struct tag key")
+	flag.StringVar(&cfg.separator, "sep", ".", "column separator for nested structs")
+	var initialisms string
+	flag.StringVar(&initialisms, "initialisms", "", "comma-separated list of initialisms kept as single words in column names, e.g. OAuth,SKU")
+	flag.Parse()
+
+	if types != "" {
+		cfg.types = strings.Split(types, ",")
+	}
+
+	if initialisms != "" {
+		cfg.initialisms = strings.Split(initialisms, ",")
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	src, err := generate(dir, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, cfg.output), src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type config struct {
+	types       []string
+	output      string
+	tagKey      string
+	separator   string
+	initialisms []string
+}
+
+// maxDepth is the same as the default used by the struct mapper
+// to stop recursing into self-referencing structs
+const maxDepth = 3
+
+type generator struct {
+	cfg       config
+	structs   map[string]*ast.StructType
+	scanners  map[string]bool
+	annotated []string
+	snakeCase func(string) string
+}
+
+// destination is a single case in the generated switch
+type destination struct {
+	key   string
+	path  string
+	inits []initPath
+	// json is set for fields with the json tag option
+	json bool
+}
+
+type initPath struct {
+	path string
+	typ  string
+}
+
+func generate(dir string, cfg config) ([]byte, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		cfg:       cfg,
+		structs:   make(map[string]*ast.StructType),
+		scanners:  make(map[string]bool),
+		snakeCase: scan.NewSnakeCase(cfg.initialisms...),
+	}
+
+	var pkgName string
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") ||
+			strings.HasSuffix(name, "_test.go") || name == cfg.output {
+			continue
+		}
+
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		if pkgName != "" && f.Name.Name != pkgName {
+			return nil, fmt.Errorf("found packages %s and %s in %s", pkgName, f.Name.Name, dir)
+		}
+		pkgName = f.Name.Name
+
+		g.inspect(f)
+	}
+
+	types := cfg.types
+	if len(types) == 0 {
+		types = g.annotated
+	}
+
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no types selected, use -type or annotate types with %s", annotation)
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by scangen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(buf, "package %s\n\n", pkgName)
+
+	methods := &bytes.Buffer{}
+	var usesScan bool
+	for _, typ := range types {
+		if _, ok := g.structs[typ]; !ok {
+			return nil, fmt.Errorf("struct type %q not found", typ)
+		}
+
+		var dests []destination
+		g.destinations(typ, "", "t", nil, map[string]int{}, &dests)
+		g.writeMethod(methods, typ, dests)
+
+		for _, d := range dests {
+			usesScan = usesScan || d.json
+		}
+	}
+
+	// The scan package is only imported for fields decoded with its helpers
+	if usesScan {
+		fmt.Fprintf(buf, "import (\n\"context\"\n\n\"github.com/stephenafamo/scan\"\n)\n")
+	} else {
+		fmt.Fprintf(buf, "import \"context\"\n")
+	}
+	buf.Write(methods.Bytes())
+
+	return format.Source(buf.Bytes())
+}
+
+// inspect records the struct types and types with a Scan method in the file
+func (g *generator) inspect(f *ast.File) {
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil || decl.Name.Name != "Scan" || len(decl.Recv.List) == 0 {
+				continue
+			}
+
+			if name := typeName(decl.Recv.List[0].Type); name != "" {
+				g.scanners[name] = true
+			}
+
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ts.TypeParams != nil {
+					continue
+				}
+
+				g.structs[ts.Name.Name] = st
+				if isAnnotated(decl.Doc) || isAnnotated(ts.Doc) {
+					g.annotated = append(g.annotated, ts.Name.Name)
+				}
+			}
+		}
+	}
+}
+
+// destinations follows the same rules as the struct mapper to
+// list the column keys of the struct and the path to each field
+func (g *generator) destinations(typ, prefix, path string, inits []initPath, visited map[string]int, dests *[]destination) {
+	if visited[typ] > maxDepth {
+		return
+	}
+	visited[typ]++
+
+	st := g.structs[typ]
+	var hasExported bool
+
+	for _, field := range st.Fields.List {
+		names := make([]string, 0, len(field.Names))
+		for _, n := range field.Names {
+			names = append(names, n.Name)
+		}
+
+		anonymous := len(names) == 0
+		if anonymous {
+			names = append(names, typeName(field.Type))
+		}
+
+		var tag string
+		var prefixed, isJSON, exact bool
+		if field.Tag != nil {
+			raw, _ := strconv.Unquote(field.Tag.Value)
+			parts := strings.Split(reflect.StructTag(raw).Get(g.cfg.tagKey), ",")
+			tag = parts[0]
+			for _, opt := range parts[1:] {
+				switch strings.TrimSpace(opt) {
+				case "prefix":
+					prefixed = true
+				case "json":
+					isJSON = true
+				case "exact":
+					exact = true
+				}
+			}
+		}
+
+		for _, name := range names {
+			if name == "" || !ast.IsExported(name) || tag == "-" {
+				continue
+			}
+
+			hasExported = true
+
+			key := prefix
+			if !anonymous || prefixed {
+				col := tag
+				switch {
+				case col == "" && exact:
+					col = name
+				case col == "":
+					col = g.snakeCase(name)
+				}
+
+				if prefix != "" {
+					key += g.cfg.separator
+				}
+				key += col
+			}
+
+			fieldPath := path + "." + name
+			if isJSON {
+				*dests = append(*dests, destination{key: key, path: fieldPath, inits: inits, json: true})
+				continue
+			}
+
+			local, isPointer := g.localStruct(field.Type)
+			if local == "" {
+				*dests = append(*dests, destination{key: key, path: fieldPath, inits: inits})
+				continue
+			}
+
+			fieldInits := inits
+			if isPointer {
+				fieldInits = append(append([]initPath(nil), inits...), initPath{path: fieldPath, typ: local})
+			}
+
+			child := make(map[string]int, len(visited))
+			for t, c := range visited {
+				child[t] = c
+			}
+
+			g.destinations(local, key, fieldPath, fieldInits, child, dests)
+		}
+	}
+
+	// Structs with no exported fields are scanned directly
+	if !hasExported {
+		*dests = append(*dests, destination{key: prefix, path: path, inits: inits})
+	}
+}
+
+// localStruct returns the name of the struct if the expression is a
+// struct (or pointer to a struct) declared in the package that should be
+// recursed into
+func (g *generator) localStruct(expr ast.Expr) (string, bool) {
+	var isPointer bool
+	if star, ok := expr.(*ast.StarExpr); ok {
+		isPointer = true
+		expr = star.X
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+
+	if _, ok := g.structs[ident.Name]; !ok || g.scanners[ident.Name] {
+		return "", false
+	}
+
+	return ident.Name, isPointer
+}
+
+func (g *generator) writeMethod(buf *bytes.Buffer, typ string, dests []destination) {
+	fmt.Fprintf(buf, "\n// MapValues returns the scan destination for the column key\n")
+	fmt.Fprintf(buf, "func (t *%s) MapValues(ctx context.Context, key string) any {\n", typ)
+	fmt.Fprintf(buf, "switch key {\n")
+
+	seen := make(map[string]bool, len(dests))
+	for _, d := range dests {
+		// Like the struct mapper, the first field with a key is used
+		if seen[d.key] {
+			continue
+		}
+		seen[d.key] = true
+
+		fmt.Fprintf(buf, "case %q:\n", d.key)
+		for _, i := range d.inits {
+			fmt.Fprintf(buf, "if %s == nil {\n%s = new(%s)\n}\n", i.path, i.path, i.typ)
+		}
+		switch {
+		case d.path == "t":
+			fmt.Fprintf(buf, "return t\n")
+		case d.json:
+			fmt.Fprintf(buf, "return scan.JSON(&%s)\n", d.path)
+		default:
+			fmt.Fprintf(buf, "return &%s\n", d.path)
+		}
+	}
+
+	fmt.Fprintf(buf, "}\n\nreturn nil\n}\n")
+}
+
+func typeName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return typeName(expr.X)
+	case *ast.SelectorExpr:
+		return expr.Sel.Name
+	default:
+		return ""
+	}
+}
+
+func isAnnotated(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("testdata", "models")
+
+	got, err := generate(dir, config{output: "scan_gen.go", tagKey: "db", separator: "."})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	golden := filepath.Join(dir, "scan_gen.go.golden")
+	if os.Getenv("UPDATE_GOLDEN") != "" {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(string(expected), string(got)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestGenerateUnknownType(t *testing.T) {
+	_, err := generate(filepath.Join("testdata", "models"), config{types: []string{"Missing"}})
+	if err == nil {
+		t.Fatal("expected error for unknown type")
+	}
+}
+
+func TestGenerateInitialisms(t *testing.T) {
+	dir := filepath.Join("testdata", "models")
+
+	// Initialisms are kept as single words, the same as with scan.WithInitialisms
+	got, err := generate(dir, config{output: "scan_gen.go", tagKey: "db", separator: ".", initialisms: []string{"CreatedAt"}})
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+
+	if !strings.Contains(string(got), `case "createdat":`) {
+		t.Fatalf("expected the initialism in the column names:\n%s", got)
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+//scan:mapper
+type User struct {
+	ID        int
+	Name      *string `db:"full_name"`
+	Secret    string  `db:"-"`
+	CreatedAt time.Time
+	Timestamps
+	internal int
+}
+
+type Timestamps struct {
+	UpdatedAt time.Time
+}
+
+//scan:mapper
+type Blog struct {
+	ID      int
+	Author  *User
+	Status  Status
+	Address `db:"addr,prefix"`
+	Tags    []string `db:"tags,json"`
+	Meta    *Address `db:"meta,json"`
+}
+
+type Address struct {
+	Street string
+	City   string
+}
+
+// Status implements sql.Scanner, so it is scanned as a single value
+type Status struct {
+	Value string
+}
+
+func (s *Status) Scan(v any) error {
+	var ns sql.NullString
+	err := ns.Scan(v)
+	s.Value = ns.String
+	return err
+}
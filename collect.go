@@ -0,0 +1,441 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aarondl/opt"
+)
+
+// Values holds the values of the columns of the current row, for collectors.
+// Read them with [Value], or with [Get] and the typed getters to handle errors for each value
+type Values struct {
+	columns []string
+	index   map[string]int
+	values  []any
+	dests   []any
+	err     error
+}
+
+func newValues(columns []string) *Values {
+	v := &Values{
+		columns: columns,
+		index:   make(map[string]int, len(columns)),
+		values:  make([]any, len(columns)),
+		dests:   make([]any, len(columns)),
+	}
+
+	for i, name := range columns {
+		if _, ok := v.index[name]; !ok {
+			v.index[name] = i
+		}
+		v.dests[i] = &v.values[i]
+	}
+
+	return v
+}
+
+// Columns returns the names of the columns
+func (v *Values) Columns() []string {
+	return v.columns
+}
+
+// scan scans the current row of the rows
+func (v *Values) scan(rows Rows) error {
+	for i := range v.values {
+		v.values[i] = nil
+	}
+	v.err = nil
+
+	if err := rows.Scan(v.dests...); err != nil {
+		var column string
+		if i := columnIndex(err); i >= 0 && i < len(v.columns) {
+			column = v.columns[i]
+		}
+		return columnError(ErrConversion, column, "", err)
+	}
+
+	return nil
+}
+
+// assign converts the values into the scan destinations, in the same way as [Value]
+func (v *Values) assign(dest []any) error {
+	if len(dest) != len(v.values) {
+		return fmt.Errorf("expected %d destination arguments in Scan, not %d", len(v.values), len(dest))
+	}
+
+	for i, d := range dest {
+		src := v.values[i]
+		if d, ok := d.(*any); ok {
+			*d = src
+			continue
+		}
+
+		if err := opt.ConvertAssign(d, src); err != nil {
+			return fmt.Errorf("converting column index %d, name %q: %w", i, v.columns[i], err)
+		}
+	}
+
+	return nil
+}
+
+// setErr keeps the first error from reading the values of the row
+func (v *Values) setErr(err error) {
+	if v.err == nil {
+		v.err = err
+	}
+}
+
+// Value returns the value of the column in the current row converted to T,
+// in the same way as [database/sql] converts values on Scan. NULL values return the zero value,
+// use [Null] as T or [Values.IsNull] to tell them apart.
+//
+// If the column does not exist or its value cannot be converted, the zero value is returned
+// and the error is returned by the function that collects the rows. Use [Get] to handle the error instead
+func Value[T any](v *Values, name string) T {
+	t, err := Get[T](v, name)
+	if err != nil {
+		v.setErr(err)
+	}
+
+	return t
+}
+
+// Get is like [Value] but returns the error instead of the function that collects the rows.
+// The error wraps [ErrUnknownColumn] or [ErrConversion]
+func Get[T any](v *Values, name string) (T, error) {
+	var t T
+
+	i, ok := v.index[name]
+	if !ok {
+		return t, unknownColumnError(name)
+	}
+
+	src := v.values[i]
+	if src == nil {
+		return t, nil
+	}
+
+	if val, ok := src.(T); ok {
+		return val, nil
+	}
+
+	if err := opt.ConvertAssign(&t, src); err != nil {
+		return t, columnError(ErrConversion, name, "", err)
+	}
+
+	return t, nil
+}
+
+// Has reports if the result has the column
+func (v *Values) Has(name string) bool {
+	_, ok := v.index[name]
+	return ok
+}
+
+// GetString returns the value of the column as a string. See [Get]
+func (v *Values) GetString(name string) (string, error) {
+	return Get[string](v, name)
+}
+
+// valuesTimeLayouts are the text formats parsed by [Values.GetTime]
+var valuesTimeLayouts = Locale{DateLayouts: append([]string{time.RFC3339Nano}, compositeTimeLayouts...)}
+
+// GetTime returns the value of the column as a [time.Time]. See [Get].
+// Text values, e.g. from drivers that return timestamps as text, are parsed
+// as RFC 3339 or in the formats used by Postgres and MySQL, with UTC if they have no time zone
+func (v *Values) GetTime(name string) (time.Time, error) {
+	i, ok := v.index[name]
+	if !ok {
+		return time.Time{}, unknownColumnError(name)
+	}
+
+	var text string
+	switch src := v.values[i].(type) {
+	case string:
+		text = src
+	case []byte:
+		text = string(src)
+	default:
+		return Get[time.Time](v, name)
+	}
+
+	t, err := valuesTimeLayouts.parseTime(text)
+	if err != nil {
+		return t, columnError(ErrConversion, name, "", err)
+	}
+
+	return t, nil
+}
+
+func unknownColumnError(name string) error {
+	err := fmt.Errorf("unknown column %q", name)
+	return columnError(ErrUnknownColumn, name, "", err, name)
+}
+
+// Collector extracts a value of type T from each row of a query.
+// It is called once with the columns of the result, and returns the function called for each row.
+//
+//	var names scan.Collector[string] = func(ctx context.Context, cols []string) func(*scan.Values) (string, error) {
+//	    return func(v *scan.Values) (string, error) {
+//	        return scan.Value[string](v, "first_name") + " " + scan.Value[string](v, "last_name"), nil
+//	    }
+//	}
+type Collector[T any] func(ctx context.Context, c cols) func(*Values) (T, error)
+
+// Collect runs the query and collects several values from each row, without a [Mapper].
+// collector is called once with the columns of the result, and must return a function of the form
+//
+//	func(*scan.Values) (t1, t2, ..., error)
+//
+// which is called for each row. The result holds a slice for each value, i.e. []any{[]t1, []t2, ...}
+//
+//	res, err := scan.Collect(ctx, exec, func(ctx context.Context, cols []string) any {
+//	    return func(v *scan.Values) (int, string, error) {
+//	        return scan.Value[int](v, "id"), scan.Value[string](v, "name"), nil
+//	    }
+//	}, "SELECT id, name FROM users")
+//
+//	ids, names := res[0].([]int), res[1].([]string)
+//
+// Use [CollectOf], [Collect2] or [Collect3] to collect values with their types.
+// See [AllFromRows] for how context cancellation is handled
+func Collect(ctx context.Context, exec Queryer, collector func(context.Context, cols) any, query string, args ...any) ([]any, error) {
+	rows, err := queryContext(ctx, exec, nil, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res, err := CollectFromRows(ctx, collector, rows)
+	return res, withQuery(err, query)
+}
+
+// CollectFromRows is like [Collect] for the given [Rows]
+func CollectFromRows(ctx context.Context, collector func(context.Context, cols) any, rows Rows) ([]any, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	fn := reflect.ValueOf(collector(ctx, columns))
+	if err := checkCollectFunc(fn); err != nil {
+		return nil, err
+	}
+
+	count := fn.Type().NumOut() - 1
+	slices := make([]reflect.Value, count)
+	for i := range slices {
+		slices[i] = reflect.MakeSlice(reflect.SliceOf(fn.Type().Out(i)), 0, 0)
+	}
+
+	err = collectRows(ctx, rows, columns, func(v *Values) error {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(v)})
+		if err, _ := out[count].Interface().(error); err != nil {
+			return err
+		}
+
+		for i := range slices {
+			slices[i] = reflect.Append(slices[i], out[i])
+		}
+
+		return nil
+	})
+
+	res := make([]any, count)
+	for i, s := range slices {
+		res[i] = s.Interface()
+	}
+
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	return res, err
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// checkCollectFunc checks that the function returned by a collector
+// has the form func(*Values) (t1, t2, ..., error)
+func checkCollectFunc(fn reflect.Value) error {
+	if fn.Kind() != reflect.Func {
+		return fmt.Errorf("collector must return a function, got %s", fn.Kind())
+	}
+
+	typ := fn.Type()
+	if typ.NumIn() != 1 || typ.In(0) != reflect.TypeOf((*Values)(nil)) {
+		return errors.New("collector function must take a single *scan.Values argument")
+	}
+
+	if typ.NumOut() < 2 || typ.Out(typ.NumOut()-1) != errorType {
+		return errors.New("collector function must return at least one value and an error")
+	}
+
+	return nil
+}
+
+// CollectOf runs the query and returns the value extracted from each row by the [Collector].
+// See [AllFromRows] for how context cancellation is handled
+func CollectOf[T any](ctx context.Context, exec Queryer, c Collector[T], query string, args ...any) ([]T, error) {
+	rows, err := queryContext(ctx, exec, nil, query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res, err := CollectOfFromRows(ctx, c, rows)
+	return res, withQuery(err, query)
+}
+
+// CollectOfFromRows is like [CollectOf] for the given [Rows]
+func CollectOfFromRows[T any](ctx context.Context, c Collector[T], rows Rows) ([]T, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	fn := c(ctx, columns)
+
+	var res []T
+	err = collectRows(ctx, rows, columns, func(v *Values) error {
+		t, err := fn(v)
+		if err != nil {
+			return err
+		}
+
+		res = append(res, t)
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	return res, err
+}
+
+// Collector2 is like [Collector] for two values from each row
+type Collector2[A, B any] func(ctx context.Context, c cols) func(*Values) (A, B, error)
+
+// Collector3 is like [Collector] for three values from each row
+type Collector3[A, B, C any] func(ctx context.Context, c cols) func(*Values) (A, B, C, error)
+
+// Collect2 runs the query and returns the two values extracted from each row by the [Collector2],
+// in a slice for each value. It is a typed alternative to [Collect]
+//
+//	ids, names, err := scan.Collect2(ctx, exec, func(ctx context.Context, cols []string) func(*scan.Values) (int, string, error) {
+//	    return func(v *scan.Values) (int, string, error) {
+//	        return scan.Value[int](v, "id"), scan.Value[string](v, "name"), nil
+//	    }
+//	}, "SELECT id, name FROM users")
+//
+// See [AllFromRows] for how context cancellation is handled
+func Collect2[A, B any](ctx context.Context, exec Queryer, c Collector2[A, B], query string, args ...any) ([]A, []B, error) {
+	rows, err := queryContext(ctx, exec, nil, query, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	a, b, err := Collect2FromRows(ctx, c, rows)
+	return a, b, withQuery(err, query)
+}
+
+// Collect2FromRows is like [Collect2] for the given [Rows]
+func Collect2FromRows[A, B any](ctx context.Context, c Collector2[A, B], rows Rows) ([]A, []B, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fn := c(ctx, columns)
+
+	var as []A
+	var bs []B
+	err = collectRows(ctx, rows, columns, func(v *Values) error {
+		a, b, err := fn(v)
+		if err != nil {
+			return err
+		}
+
+		as, bs = append(as, a), append(bs, b)
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, nil, err
+	}
+
+	return as, bs, err
+}
+
+// Collect3 is like [Collect2] for three values from each row
+func Collect3[A, B, C any](ctx context.Context, exec Queryer, c Collector3[A, B, C], query string, args ...any) ([]A, []B, []C, error) {
+	rows, err := queryContext(ctx, exec, nil, query, args)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+
+	a, b, cs, err := Collect3FromRows(ctx, c, rows)
+	return a, b, cs, withQuery(err, query)
+}
+
+// Collect3FromRows is like [Collect3] for the given [Rows]
+func Collect3FromRows[A, B, C any](ctx context.Context, c Collector3[A, B, C], rows Rows) ([]A, []B, []C, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	fn := c(ctx, columns)
+
+	var as []A
+	var bs []B
+	var cs []C
+	err = collectRows(ctx, rows, columns, func(v *Values) error {
+		a, b, c, err := fn(v)
+		if err != nil {
+			return err
+		}
+
+		as, bs, cs = append(as, a), append(bs, b), append(cs, c)
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, nil, nil, err
+	}
+
+	return as, bs, cs, err
+}
+
+// collectRows scans each row into the values and calls fn with them
+func collectRows(ctx context.Context, rows Rows, columns []string, fn func(*Values) error) error {
+	v := newValues(columns)
+
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := v.scan(rows)
+		if err == nil {
+			err = fn(v)
+		}
+		if err == nil {
+			err = v.err
+		}
+
+		if t, ok := rows.(rowTracer); ok {
+			t.scanned(nil, err)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
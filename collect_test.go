@@ -0,0 +1,230 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func usersWithAge(ctx context.Context, query string, args ...any) (Rows, error) {
+	return newSliceRows([]string{"id", "name", "age"},
+		[]any{1, "foo", int64(30)},
+		[]any{2, "bar", nil},
+		[]any{3, "baz", []byte("41")},
+	), nil
+}
+
+func TestCollect(t *testing.T) {
+	ctx := context.Background()
+
+	res, err := Collect(ctx, funcQ(usersWithAge), func(ctx context.Context, c cols) any {
+		return func(v *Values) (int, string, float64, error) {
+			return Value[int](v, "id"), Value[string](v, "name"), Value[float64](v, "age"), nil
+		}
+	}, "SELECT id, name, age FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []any{[]int{1, 2, 3}, []string{"foo", "bar", "baz"}, []float64{30, 0, 41}}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Empty results still have a slice for each value
+	empty := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id"}), nil
+	})
+	res, err = Collect(ctx, empty, func(ctx context.Context, c cols) any {
+		return func(v *Values) (int, error) { return Value[int](v, "id"), nil }
+	}, "SELECT id FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]any{[]int{}}, res); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	ctx := context.Background()
+
+	cases := map[string]struct {
+		collector func(context.Context, cols) any
+		kind      error
+	}{
+		"not a function": {
+			collector: func(ctx context.Context, c cols) any { return 1 },
+		},
+		"no error": {
+			collector: func(ctx context.Context, c cols) any {
+				return func(v *Values) int { return 0 }
+			},
+		},
+		"unknown column": {
+			collector: func(ctx context.Context, c cols) any {
+				return func(v *Values) (int, error) { return Value[int](v, "missing"), nil }
+			},
+			kind: ErrUnknownColumn,
+		},
+		"conversion": {
+			collector: func(ctx context.Context, c cols) any {
+				return func(v *Values) (int, error) { return Value[int](v, "name"), nil }
+			},
+			kind: ErrConversion,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Collect(ctx, funcQ(usersWithAge), tc.collector, "SELECT id, name, age FROM users")
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if tc.kind != nil && !errors.Is(err, tc.kind) {
+				t.Fatalf("expected %v, got %v", tc.kind, err)
+			}
+		})
+	}
+}
+
+func TestCollectOf(t *testing.T) {
+	ctx := context.Background()
+
+	var names Collector[string] = func(ctx context.Context, c cols) func(*Values) (string, error) {
+		return func(v *Values) (string, error) {
+			return Value[string](v, "name") + "@" + Value[string](v, "id"), nil
+		}
+	}
+
+	res, err := CollectOf(ctx, funcQ(usersWithAge), names, "SELECT id, name, age FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"foo@1", "bar@2", "baz@3"}, res); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	errStop := errors.New("stop")
+	_, err = CollectOf(ctx, funcQ(usersWithAge), func(ctx context.Context, c cols) func(*Values) (int, error) {
+		return func(v *Values) (int, error) { return 0, errStop }
+	}, "SELECT id, name, age FROM users")
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected the error from the collector, got %v", err)
+	}
+}
+
+func TestCollect2And3(t *testing.T) {
+	ctx := context.Background()
+
+	ids, names, err := Collect2(ctx, funcQ(usersWithAge), func(ctx context.Context, c cols) func(*Values) (int, string, error) {
+		return func(v *Values) (int, string, error) {
+			return Value[int](v, "id"), Value[string](v, "name"), nil
+		}
+	}, "SELECT id, name, age FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 3}, ids); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"foo", "bar", "baz"}, names); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	ids, names, ages, err := Collect3(ctx, funcQ(usersWithAge), func(ctx context.Context, c cols) func(*Values) (int, string, Null[int], error) {
+		return func(v *Values) (int, string, Null[int], error) {
+			return Value[int](v, "id"), Value[string](v, "name"), Value[Null[int]](v, "age"), nil
+		}
+	}, "SELECT id, name, age FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(ids) != 3 || len(names) != 3 {
+		t.Fatalf("expected 3 ids and names, got %d and %d", len(ids), len(names))
+	}
+
+	if diff := cmp.Diff([]Null[int]{NullOf(30), {}, NullOf(41)}, ages); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	_, _, err = Collect2(ctx, funcQ(usersWithAge), func(ctx context.Context, c cols) func(*Values) (int, int, error) {
+		return func(v *Values) (int, int, error) {
+			return Value[int](v, "id"), Value[int](v, "name"), nil
+		}
+	}, "SELECT id, name, age FROM users")
+	if !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrConversion, got %v", err)
+	}
+}
+
+func TestValuesGetters(t *testing.T) {
+	ctx := context.Background()
+	created := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	rows := newSliceRows([]string{"id", "name", "created", "updated", "deleted"},
+		[]any{int64(1), []byte("foo"), created, "2024-03-02 08:00:00", nil},
+		[]any{int64(2), "bar", []byte("2024-03-01T12:30:00Z"), "yesterday", nil},
+	)
+
+	type event struct {
+		ID      int
+		Name    string
+		Created time.Time
+		Updated time.Time
+	}
+
+	var errs []error
+	res, err := CollectOfFromRows(ctx, func(ctx context.Context, c cols) func(*Values) (event, error) {
+		return func(v *Values) (event, error) {
+			var e event
+			var err error
+			if e.ID, err = Get[int](v, "id"); err != nil {
+				return e, err
+			}
+			if e.Name, err = v.GetString("name"); err != nil {
+				return e, err
+			}
+			if e.Created, err = v.GetTime("created"); err != nil {
+				return e, err
+			}
+			if e.Updated, err = v.GetTime("updated"); err != nil {
+				errs = append(errs, err)
+			}
+			if deleted, err := v.GetTime("deleted"); err != nil || !deleted.IsZero() {
+				t.Fatalf("expected a zero time for NULL, got %v, %v", deleted, err)
+			}
+			if _, err := Get[int](v, "missing"); !errors.Is(err, ErrUnknownColumn) {
+				t.Fatalf("expected ErrUnknownColumn, got %v", err)
+			}
+			if !v.Has("name") || v.Has("missing") {
+				t.Fatal("unexpected result from Has")
+			}
+			return e, nil
+		}
+	}, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []event{
+		{ID: 1, Name: "foo", Created: created, Updated: time.Date(2024, 3, 2, 8, 0, 0, 0, time.UTC)},
+		{ID: 2, Name: "bar", Created: created},
+	}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], ErrConversion) {
+		t.Fatalf("expected a conversion error for the second row, got %v", errs)
+	}
+}
@@ -0,0 +1,172 @@
+package scan
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aarondl/opt"
+)
+
+// compositeTimeLayouts are the text formats of Postgres time values
+var compositeTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// decodeComposite decodes the text representation of a Postgres composite value
+// e.g. (1,foo,t) into the struct pointed to by dest.
+// The values are set to the fields of the struct in order.
+// Fields that are skipped by the mapping source are also skipped here
+func (s *mapperSourceImpl) decodeComposite(data []byte, dest any) error {
+	v := reflect.ValueOf(dest).Elem()
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode a composite value into %s", v.Type())
+	}
+
+	values, err := parseComposite(string(data))
+	if err != nil {
+		return err
+	}
+
+	fields := s.compositeFields(v.Type())
+	if len(fields) != len(values) {
+		return fmt.Errorf("composite value has %d fields but %s has %d", len(values), v.Type(), len(fields))
+	}
+
+	for i, index := range fields {
+		fv := exposeField(v.Field(index))
+		if err := s.assignCompositeField(fv, values[i]); err != nil {
+			return fmt.Errorf("field %s: %w", v.Type().Field(index).Name, err)
+		}
+	}
+
+	return nil
+}
+
+// compositeFields returns the index of the fields that receive composite values
+func (s *mapperSourceImpl) compositeFields(typ reflect.Type) []int {
+	fields := make([]int, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() && !s.unexported[typ] {
+			continue
+		}
+
+		if name, _ := parseTag(s.fieldTag(field)); name == "-" {
+			continue
+		}
+
+		fields = append(fields, i)
+	}
+
+	return fields
+}
+
+func (s *mapperSourceImpl) assignCompositeField(fv reflect.Value, value *string) error {
+	ptr := fv.Addr().Interface()
+
+	if scanner, ok := ptr.(sql.Scanner); ok {
+		if value == nil {
+			return scanner.Scan(nil)
+		}
+		return scanner.Scan(*value)
+	}
+
+	if value == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	typ := fv.Type()
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch {
+	case typ == reflect.TypeOf(time.Time{}):
+		t, err := parseCompositeTime(*value)
+		if err != nil {
+			return err
+		}
+		return opt.ConvertAssign(ptr, t)
+
+	case typ.Kind() == reflect.Struct:
+		return s.decodeComposite([]byte(*value), ptr)
+
+	default:
+		return opt.ConvertAssign(ptr, *value)
+	}
+}
+
+func parseCompositeTime(s string) (time.Time, error) {
+	for _, layout := range compositeTimeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse time %q", s)
+}
+
+// parseComposite splits the text representation of a composite value into its fields.
+// NULL fields are returned as nil
+func parseComposite(s string) ([]*string, error) {
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf("invalid composite value %q", s)
+	}
+	s = s[1 : len(s)-1]
+
+	var values []*string
+	var current strings.Builder
+	var quoted, inQuotes bool
+
+	end := func() {
+		if !quoted && current.Len() == 0 {
+			values = append(values, nil)
+		} else {
+			val := current.String()
+			values = append(values, &val)
+		}
+		current.Reset()
+		quoted = false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			current.WriteByte(s[i])
+		case c == '"' && inQuotes && i+1 < len(s) && s[i+1] == '"':
+			i++
+			current.WriteByte('"')
+		case c == '"':
+			inQuotes = !inQuotes
+			quoted = true
+		case c == ',' && !inQuotes:
+			end()
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quote in composite value %q", s)
+	}
+	end()
+
+	return values, nil
+}
@@ -0,0 +1,212 @@
+package scan
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/aarondl/opt"
+)
+
+// Converters is a [TypeConverter] that converts column values with the functions
+// registered for the type of each field, so types can be scanned into
+// without implementing [database/sql.Scanner].
+// Fields of other types are scanned as usual.
+//
+// Use it with [WithTypeConverter]. Converters should not be modified once in use.
+//
+//	conv := scan.NewConverters()
+//	scan.RegisterConverter(conv, scan.JSONConverter[Settings]())
+//	scan.RegisterConverter(conv, scan.EnumConverter(StatusActive, StatusBanned))
+//
+//	users, _ := stdscan.All(ctx, db, scan.StructMapper[User](scan.WithTypeConverter(conv)), query)
+type Converters struct {
+	funcs map[reflect.Type]func(src any) (reflect.Value, error)
+}
+
+// NewConverters returns an empty set of converters
+func NewConverters() *Converters {
+	return &Converters{funcs: make(map[reflect.Type]func(src any) (reflect.Value, error))}
+}
+
+// RegisterConverter registers fn to convert the values scanned into fields of type T.
+// It is also used for fields of type *T, which are left nil for NULL values.
+//
+// src is the value passed to [database/sql.Scanner.Scan] by the driver. For [database/sql] it is one of
+// int64, float64, bool, []byte, string, time.Time or nil.
+// A []byte may be reused by the driver, and must be copied if it is retained
+func RegisterConverter[T any](c *Converters, fn func(src any) (T, error)) {
+	c.funcs[typeOf[T]()] = func(src any) (reflect.Value, error) {
+		v, err := fn(src)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	}
+}
+
+// structConverter is implemented by type converters that can scan
+// a struct field as a single value instead of mapping its fields
+type structConverter interface {
+	convertsType(reflect.Type) bool
+}
+
+func (c *Converters) convertsType(typ reflect.Type) bool {
+	if _, ok := c.funcs[typ]; ok {
+		return true
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		_, ok := c.funcs[typ.Elem()]
+		return ok
+	}
+
+	return false
+}
+
+// TypeToDestination implements [TypeConverter]
+func (c *Converters) TypeToDestination(typ reflect.Type) reflect.Value {
+	if fn, ok := c.funcs[typ]; ok {
+		return reflect.ValueOf(&convertDest{typ: typ, fn: fn})
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		if fn, ok := c.funcs[typ.Elem()]; ok {
+			return reflect.ValueOf(&convertDest{typ: typ, fn: fn, isPointer: true})
+		}
+	}
+
+	return reflect.New(typ)
+}
+
+// ValueFromDestination implements [TypeConverter]
+func (c *Converters) ValueFromDestination(val reflect.Value) reflect.Value {
+	if dest, ok := val.Interface().(*convertDest); ok {
+		return dest.value()
+	}
+
+	return val.Elem()
+}
+
+// convertDest is the scan destination for a field with a registered converter
+type convertDest struct {
+	typ       reflect.Type
+	fn        func(src any) (reflect.Value, error)
+	isPointer bool
+	val       reflect.Value
+}
+
+func (d *convertDest) Scan(src any) error {
+	if d.isPointer && src == nil {
+		d.val = reflect.Value{}
+		return nil
+	}
+
+	v, err := d.fn(src)
+	if err != nil {
+		return fmt.Errorf("converting to %s: %w", d.typ, err)
+	}
+
+	if d.isPointer {
+		ptr := reflect.New(d.typ.Elem())
+		ptr.Elem().Set(v)
+		v = ptr
+	}
+
+	d.val = v
+	return nil
+}
+
+func (d *convertDest) value() reflect.Value {
+	if !d.val.IsValid() {
+		return reflect.Zero(d.typ)
+	}
+
+	return d.val
+}
+
+// JSONConverter returns a converter that unmarshals JSON columns into T.
+// NULL values are converted to the zero value of T
+func JSONConverter[T any]() func(src any) (T, error) {
+	return func(src any) (T, error) {
+		var t T
+
+		var data []byte
+		switch src := src.(type) {
+		case nil:
+			return t, nil
+		case []byte:
+			data = src
+		case string:
+			data = []byte(src)
+		default:
+			return t, fmt.Errorf("cannot unmarshal JSON from %T", src)
+		}
+
+		err := json.Unmarshal(data, &t)
+		return t, err
+	}
+}
+
+// EnumConverter returns a converter for string columns into the typed constants of T.
+// It returns an error if the value is not one of the given values
+func EnumConverter[T ~string](values ...T) func(src any) (T, error) {
+	valid := make(map[T]bool, len(values))
+	for _, v := range values {
+		valid[v] = true
+	}
+
+	return func(src any) (T, error) {
+		var t T
+		switch src := src.(type) {
+		case []byte:
+			t = T(src)
+		case string:
+			t = T(src)
+		default:
+			return t, fmt.Errorf("cannot convert %T to %T", src, t)
+		}
+
+		if !valid[t] {
+			return t, fmt.Errorf("invalid value %q", string(t))
+		}
+
+		return t, nil
+	}
+}
+
+// setValue sets the value of a destination into the field, or into the value it points to.
+// Values that cannot be assigned to the field but implement [driver.Valuer],
+// such as a [database/sql.NullString] from a [TypeConverter] for a string field, are converted
+// from their driver value, and NULL values set the field to its zero value, e.g. nil
+func setValue(fv, val reflect.Value) error {
+	switch {
+	case val.Type().AssignableTo(fv.Type()):
+		fv.Set(val)
+		return nil
+	case fv.Kind() == reflect.Pointer && val.Type().AssignableTo(fv.Type().Elem()):
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv.Elem().Set(val)
+		return nil
+	}
+
+	valuer, ok := val.Interface().(driver.Valuer)
+	if !ok {
+		return fmt.Errorf("cannot assign %s to a field of type %s", val.Type(), fv.Type())
+	}
+
+	src, err := valuer.Value()
+	if err != nil {
+		return err
+	}
+
+	if src == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+
+	return opt.ConvertAssign(fv.Addr().Interface(), src)
+}
@@ -0,0 +1,133 @@
+package scan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Status string
+
+const (
+	StatusActive Status = "active"
+	StatusBanned Status = "banned"
+)
+
+type Settings struct {
+	Theme string `json:"theme"`
+}
+
+type UserWithSettings struct {
+	ID       int
+	Status   Status
+	Settings Settings
+	Previous *Settings
+}
+
+func TestConverters(t *testing.T) {
+	conv := NewConverters()
+	RegisterConverter(conv, JSONConverter[Settings]())
+	RegisterConverter(conv, EnumConverter(StatusActive, StatusBanned))
+
+	m := StructMapper[UserWithSettings](WithTypeConverter(conv))
+	cols := []string{"id", "status", "settings", "previous"}
+
+	rows := newSliceRows(cols,
+		[]any{1, "active", []byte(`{"theme":"dark"}`), nil},
+		[]any{2, "banned", `{"theme":"light"}`, []byte(`{"theme":"dark"}`)},
+	)
+
+	users, err := AllFromRows(context.Background(), m, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []UserWithSettings{
+		{ID: 1, Status: StatusActive, Settings: Settings{Theme: "dark"}},
+		{ID: 2, Status: StatusBanned, Settings: Settings{Theme: "light"}, Previous: &Settings{Theme: "dark"}},
+	}
+	if diff := cmp.Diff(expected, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows(cols, []any{3, "deleted", nil, nil})
+	if _, err := AllFromRows(context.Background(), m, rows); err == nil {
+		t.Fatal("expected an error for an invalid enum value")
+	}
+}
+
+// nullConverter scans every field into the sql.Null type of its kind
+type nullConverter struct{}
+
+func (nullConverter) TypeToDestination(typ reflect.Type) reflect.Value {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(&sql.NullString{})
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(&sql.NullInt64{})
+	default:
+		return reflect.New(typ)
+	}
+}
+
+func (nullConverter) ValueFromDestination(val reflect.Value) reflect.Value {
+	return val.Elem()
+}
+
+func TestNullValuesFromConverter(t *testing.T) {
+	type row struct {
+		Name  string
+		Count int32
+		Note  *string
+		Total *int64
+		Score float64
+	}
+
+	rows := newSliceRows([]string{"name", "count", "note", "total", "score"},
+		[]any{"a", int64(1), "hi", int64(2), 1.5},
+		[]any{nil, nil, nil, nil, 0.0},
+	)
+
+	res, err := AllFromRows(context.Background(), StructMapper[row](WithTypeConverter(nullConverter{})), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []row{
+		{Name: "a", Count: 1, Note: toPtr("hi"), Total: toPtr(int64(2)), Score: 1.5},
+		{},
+	}
+	if diff := cmp.Diff(expected, res); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Values that cannot be converted return an error instead of panicking
+	type invalid struct {
+		Flag bool
+	}
+
+	_, err = AllFromRows(context.Background(), StructMapper[invalid](WithTypeConverter(wrongConverter{})),
+		newSliceRows([]string{"flag"}, []any{true}))
+	if !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrConversion, got %v", err)
+	}
+}
+
+// wrongConverter returns values of the wrong type
+type wrongConverter struct{}
+
+func (wrongConverter) TypeToDestination(typ reflect.Type) reflect.Value {
+	return reflect.ValueOf(new(any))
+}
+
+func (wrongConverter) ValueFromDestination(val reflect.Value) reflect.Value {
+	return reflect.ValueOf(struct{}{})
+}
@@ -15,6 +15,7 @@ type cursor[T any] struct {
 	v      *Row
 	before func(*Row) (any, error)
 	after  func(any) (T, error)
+	query  string
 }
 
 func (c *cursor[T]) Close() error {
@@ -30,5 +31,6 @@ func (c *cursor[T]) Next() bool {
 }
 
 func (c *cursor[T]) Get() (T, error) {
-	return scanOneRow(c.v, c.before, c.after)
+	t, err := scanOneRow(c.v, c.before, c.after)
+	return t, withQuery(err, c.query)
 }
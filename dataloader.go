@@ -0,0 +1,96 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BatchQuery builds the query and args used to load a batch of keys
+type BatchQuery[K any] func(keys []K) (query string, args []any)
+
+// InQuery returns a [BatchQuery] that replaces the %s verb in the template
+// with one "?" placeholder per key, for use in an IN clause
+//
+//	scan.InQuery[int]("SELECT * FROM users WHERE id IN (%s)")
+func InQuery[K any](template string) BatchQuery[K] {
+	return func(keys []K) (string, []any) {
+		placeholders := make([]string, len(keys))
+		args := make([]any, len(keys))
+		for i, k := range keys {
+			placeholders[i] = "?"
+			args[i] = k
+		}
+
+		return fmt.Sprintf(template, strings.Join(placeholders, ", ")), args
+	}
+}
+
+// BatchOne returns a batch function that loads one value per key with a single query,
+// as expected by dataloaders (e.g. for gqlgen resolvers).
+// The key of each row is found with the key extractor.
+//
+// The returned values and errors are in the same order as the keys.
+// Keys with no matching row get [ErrNoRows]
+func BatchOne[K comparable, V any](exec Queryer, m Mapper[V], key func(V) K, q BatchQuery[K]) func(ctx context.Context, keys []K) ([]V, []error) {
+	return func(ctx context.Context, keys []K) ([]V, []error) {
+		values := make([]V, len(keys))
+		errs := make([]error, len(keys))
+
+		query, args := q(keys)
+		all, err := All(ctx, exec, m, query, args...)
+		if err != nil {
+			for i := range errs {
+				errs[i] = err
+			}
+			return values, errs
+		}
+
+		byKey := make(map[K]V, len(all))
+		for _, v := range all {
+			byKey[key(v)] = v
+		}
+
+		for i, k := range keys {
+			v, ok := byKey[k]
+			if !ok {
+				errs[i] = ErrNoRows
+				continue
+			}
+			values[i] = v
+		}
+
+		return values, errs
+	}
+}
+
+// BatchMany is like [BatchOne] but loads all the values for each key,
+// for one-to-many relationships.
+// Keys with no matching row get an empty slice
+func BatchMany[K comparable, V any](exec Queryer, m Mapper[V], key func(V) K, q BatchQuery[K]) func(ctx context.Context, keys []K) ([][]V, []error) {
+	return func(ctx context.Context, keys []K) ([][]V, []error) {
+		values := make([][]V, len(keys))
+		errs := make([]error, len(keys))
+
+		query, args := q(keys)
+		all, err := All(ctx, exec, m, query, args...)
+		if err != nil {
+			for i := range errs {
+				errs[i] = err
+			}
+			return values, errs
+		}
+
+		byKey := make(map[K][]V, len(keys))
+		for _, v := range all {
+			k := key(v)
+			byKey[k] = append(byKey[k], v)
+		}
+
+		for i, k := range keys {
+			values[i] = byKey[k]
+		}
+
+		return values, errs
+	}
+}
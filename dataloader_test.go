@@ -0,0 +1,74 @@
+package scan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestInQuery(t *testing.T) {
+	query, args := InQuery[int]("SELECT * FROM users WHERE id IN (%s)")([]int{1, 2, 3})
+	if query != "SELECT * FROM users WHERE id IN (?, ?, ?)" {
+		t.Fatalf("wrong query: %s", query)
+	}
+
+	if diff := cmp.Diff([]any{1, 2, 3}, args); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestBatchOne(t *testing.T) {
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "name"}, []any{3, "baz"}, []any{1, "foo"}), nil
+	})
+
+	load := BatchOne(exec, StructMapper[User](), func(u User) int { return u.ID },
+		InQuery[int]("SELECT * FROM users WHERE id IN (%s)"))
+
+	users, errs := load(context.Background(), []int{1, 2, 3})
+
+	if diff := cmp.Diff([]User{{ID: 1, Name: "foo"}, {}, {ID: 3, Name: "baz"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if errs[0] != nil || errs[2] != nil || !errors.Is(errs[1], sql.ErrNoRows) {
+		t.Fatalf("wrong errors: %v", errs)
+	}
+}
+
+func TestBatchMany(t *testing.T) {
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "amount"}, []any{1, 10}, []any{2, 20}, []any{1, 30}), nil
+	})
+
+	load := BatchMany(exec, StructMapper[Order](), func(o Order) int { return o.ID },
+		InQuery[int]("SELECT * FROM orders WHERE id IN (%s)"))
+
+	orders, errs := load(context.Background(), []int{1, 2, 3})
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	expected := [][]Order{
+		{{ID: 1, Amount: 10}, {ID: 1, Amount: 30}},
+		{{ID: 2, Amount: 20}},
+		nil,
+	}
+	if diff := cmp.Diff(expected, orders); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	failing := BatchMany(funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return nil, errors.New("boom")
+	}), StructMapper[Order](), func(o Order) int { return o.ID }, InQuery[int]("%s"))
+
+	_, errs = failing(context.Background(), []int{1, 2})
+	if len(errs) != 2 || errs[0] == nil || errs[1] == nil {
+		t.Fatalf("expected the error for every key, got %v", errs)
+	}
+}
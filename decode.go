@@ -0,0 +1,364 @@
+package scan
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aarondl/opt"
+)
+
+// decodeFunc decodes the value of a column into dest, which is a pointer to a field.
+// It is not called for NULL values
+type decodeFunc = func(src any, dest any) error
+
+// textDecoder returns a decodeFunc for columns with a text or binary value
+func textDecoder(decode func(data []byte, dest any) error) decodeFunc {
+	return func(src any, dest any) error {
+		switch src := src.(type) {
+		case []byte:
+			return decode(src, dest)
+		case string:
+			return decode([]byte(src), dest)
+		default:
+			return fmt.Errorf("cannot decode %T into %s", src, reflect.TypeOf(dest).Elem())
+		}
+	}
+}
+
+// tagDecoders are the struct tag options that decode column values into a field.
+// The first option in the list that a field has is used
+var tagDecoders = []struct {
+	option string
+	decode decodeFunc
+}{
+	{option: "json", decode: decodeJSON},
+	{option: "xml", decode: textDecoder(xml.Unmarshal)},
+	{option: "hex", decode: textDecoder(decodeBytes(hex.DecodeString))},
+	{option: "base64", decode: textDecoder(decodeBytes(base64.StdEncoding.DecodeString))},
+}
+
+var decodeJSON = textDecoder(json.Unmarshal)
+
+// JSON returns a scan destination that unmarshals JSON values into dest, which must be a pointer.
+// NULL values set dest to its zero value.
+// It is used by the mappers generated for fields with the json tag option
+//
+//	err := row.Scan(&id, scan.JSON(&settings))
+func JSON(dest any) sql.Scanner {
+	return &decodeDest{dest: reflect.ValueOf(dest), decode: decodeJSON}
+}
+
+// decodeBytes returns a decoder for text encoded binary values,
+// which sets the decoded bytes to a []byte or *[]byte field
+func decodeBytes(decode func(string) ([]byte, error)) func(data []byte, dest any) error {
+	return func(data []byte, dest any) error {
+		v := reflect.ValueOf(dest).Elem()
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		if v.Kind() != reflect.Slice || v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("cannot decode bytes into %s", v.Type())
+		}
+
+		b, err := decode(string(data))
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(b).Convert(v.Type()))
+		return nil
+	}
+}
+
+// fieldDecoder returns the decoder for the options of a struct tag
+// or nil if the field is scanned as usual
+func (s *mapperSourceImpl) fieldDecoder(opts tagOptions) decodeFunc {
+	decode := s.optionDecoder(opts)
+
+	trim, nullIfEmpty := opts.has("trim"), opts.has("nullifempty")
+	if trim || nullIfEmpty {
+		return normalizeText(decode, trim, nullIfEmpty)
+	}
+
+	return decode
+}
+
+// optionDecoder returns the decoder for the options that decode the value of a field
+func (s *mapperSourceImpl) optionDecoder(opts tagOptions) decodeFunc {
+	for _, d := range tagDecoders {
+		if opts.has(d.option) {
+			return d.decode
+		}
+	}
+
+	// Composite values are decoded using the same rules as the mapping
+	if opts.has("composite") {
+		return textDecoder(s.decodeComposite)
+	}
+
+	if opts.has("array") {
+		return textDecoder(s.decodeArray)
+	}
+
+	if opts.has("money") {
+		return s.moneyDecoder(opts)
+	}
+
+	if unit := opts["unit"]; unit != "" {
+		return s.unitDecoder(unit)
+	}
+
+	return nil
+}
+
+// normalizeText returns a decoder for the trim and nullifempty options, which changes
+// text values before they are decoded with decode, or converted as usual if it is nil.
+// trim removes the trailing spaces that pad CHAR(N) values, and
+// nullifempty sets the field to its zero value, e.g. nil, for empty text
+func normalizeText(decode decodeFunc, trim, nullIfEmpty bool) decodeFunc {
+	return func(src any, dest any) error {
+		var text string
+		switch s := src.(type) {
+		case string:
+			text = s
+		case []byte:
+			text = string(s)
+		default:
+			return decodeValue(decode, src, dest)
+		}
+
+		if trim {
+			text = strings.TrimRight(text, " ")
+		}
+
+		if nullIfEmpty && text == "" {
+			v := reflect.ValueOf(dest).Elem()
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		if _, ok := src.([]byte); ok {
+			return decodeValue(decode, []byte(text), dest)
+		}
+
+		return decodeValue(decode, text, dest)
+	}
+}
+
+// decodeValue decodes src with decode, or converts it as usual if decode is nil
+func decodeValue(decode decodeFunc, src any, dest any) error {
+	if decode == nil {
+		return opt.ConvertAssign(dest, src)
+	}
+
+	return decode(src, dest)
+}
+
+// decodeDest is the scan destination of a field with a decoder.
+// NULL values set the field to its default value if it has one, or else to its zero value
+type decodeDest struct {
+	dest   reflect.Value
+	decode decodeFunc
+	def    *string
+}
+
+func (d *decodeDest) Scan(src any) error {
+	if src == nil {
+		if d.def != nil {
+			return d.decode(*d.def, d.dest.Interface())
+		}
+
+		d.dest.Elem().Set(reflect.Zero(d.dest.Elem().Type()))
+		return nil
+	}
+
+	return d.decode(src, d.dest.Interface())
+}
+
+// scanDest returns the scan destination for a pointer to the field
+func (info mapinfo) scanDest(ptr reflect.Value) reflect.Value {
+	if info.decode == nil {
+		return ptr
+	}
+
+	return reflect.ValueOf(&decodeDest{dest: ptr, decode: info.decode, def: info.def})
+}
+
+// convertValue is the decoder of fields that are converted as usual
+func convertValue(src any, dest any) error {
+	return opt.ConvertAssign(dest, src)
+}
+
+// withDefaults sets a decoder for the fields of the mapping with a default value
+// that do not have one, so that NULL values are replaced by the default.
+// It must be called after the other decoders are set
+func withDefaults(m mapping) {
+	for i, info := range m {
+		if info.def != nil && info.decode == nil {
+			m[i].decode = convertValue
+		}
+	}
+}
+
+// absentDefaults returns the fields of the mapping with a default value
+// that are not in the filtered mapping, i.e. that have no column in the query
+func absentDefaults(m, filtered mapping) mapping {
+	var absent mapping
+	for _, info := range m {
+		if info.def == nil {
+			continue
+		}
+
+		found := false
+		for _, f := range filtered {
+			if samePosition(f.position, info.position) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			absent = append(absent, info)
+		}
+	}
+
+	return absent
+}
+
+// checkDefaults returns an error if the default value of a field cannot be decoded into it
+func checkDefaults(m mapping, typ reflect.Type, isPointer bool) error {
+	if isPointer {
+		typ = typ.Elem()
+	}
+
+	for _, info := range m {
+		if info.def == nil {
+			continue
+		}
+
+		dest := reflect.New(typ.FieldByIndex(info.position).Type)
+		if err := info.scanDest(dest).Interface().(*decodeDest).Scan(nil); err != nil {
+			field := fieldPaths(mapping{info}, typ, false)[0]
+			err = fmt.Errorf("Default value %q of field %s: %w", *info.def, field, err)
+			return columnError(ErrConversion, info.name, field, err, "default", *info.def)
+		}
+	}
+
+	return nil
+}
+
+// setDefault sets the field of the row to its default value if it is still zero.
+// Fields of structs reached through a nil pointer are left as they are
+func setDefault(row reflect.Value, info mapinfo) error {
+	for _, init := range info.init {
+		if fieldOf(row, init).IsNil() {
+			return nil
+		}
+	}
+
+	fv := fieldOf(row, info.position)
+	if !fv.IsZero() {
+		return nil
+	}
+
+	return info.scanDest(fv.Addr()).Interface().(*decodeDest).Scan(nil)
+}
+
+// withTimeLayouts sets a decoder that parses text values with the layouts
+// for the time fields of the mapping that do not have a decoder
+func withTimeLayouts(m mapping, layouts []string) {
+	locale := Locale{DateLayouts: layouts}
+	decode := func(src any, dest any) error {
+		v := reflect.ValueOf(dest).Elem()
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		var t time.Time
+		var err error
+		switch src := src.(type) {
+		case time.Time:
+			t = src
+		case string:
+			t, err = locale.parseTime(src)
+		case []byte:
+			t, err = locale.parseTime(string(src))
+		default:
+			err = fmt.Errorf("cannot convert %T to time.Time", src)
+		}
+		if err != nil {
+			return err
+		}
+
+		v.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	for i, info := range m {
+		if info.decode != nil || info.typ == nil {
+			continue
+		}
+
+		if info.typ == timeType || info.typ.Kind() == reflect.Pointer && info.typ.Elem() == timeType {
+			m[i].decode = decode
+		}
+	}
+}
+
+// withJSONColumns returns a copy of the mapping where the fields mapped to the columns
+// are decoded with the JSON decoder. The fields of structs that are decoded are removed
+func (m mapping) withJSONColumns(columns []string) mapping {
+	var decoded [][]int
+	for _, info := range m {
+		for _, col := range columns {
+			if info.name == col && info.decode == nil {
+				decoded = append(decoded, info.position)
+				break
+			}
+		}
+	}
+
+	filtered := make(mapping, 0, len(m))
+	for _, info := range m {
+		if isDecodedField(info.position, decoded) {
+			continue
+		}
+
+		for _, pos := range decoded {
+			if reflect.DeepEqual(info.position, pos) {
+				info.decode = decodeJSON
+				info.container = false
+				break
+			}
+		}
+
+		filtered = append(filtered, info)
+	}
+
+	return filtered
+}
+
+// isDecodedField reports if the field at the position is inside one of the decoded fields
+func isDecodedField(position []int, decoded [][]int) bool {
+	for _, pos := range decoded {
+		if len(position) > len(pos) && reflect.DeepEqual(position[:len(pos)], pos) {
+			return true
+		}
+	}
+
+	return false
+}
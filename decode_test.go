@@ -0,0 +1,402 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Item struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+type OrderWithItems struct {
+	ID    int
+	Items []Item          `db:"items,json"`
+	Meta  *map[string]any `db:"meta,json"`
+}
+
+func TestJSONColumns(t *testing.T) {
+	rows := newSliceRows([]string{"id", "items", "meta"},
+		[]any{1, []byte(`[{"sku":"a","qty":2},{"sku":"b","qty":1}]`), `{"gift":true}`},
+		[]any{2, nil, nil},
+	)
+
+	orders, err := AllFromRows(context.Background(), StructMapper[OrderWithItems](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []OrderWithItems{
+		{ID: 1, Items: []Item{{SKU: "a", Qty: 2}, {SKU: "b", Qty: 1}}, Meta: &map[string]any{"gift": true}},
+		{ID: 2},
+	}
+	if diff := cmp.Diff(expected, orders); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The decoder is also used with a type converter
+	rows = newSliceRows([]string{"id", "items"}, []any{1, `[{"sku":"a","qty":2}]`})
+	orders, err = AllFromRows(context.Background(), StructMapper[OrderWithItems](WithTypeConverter(NewConverters())), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]OrderWithItems{{ID: 1, Items: []Item{{SKU: "a", Qty: 2}}}}, orders); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows([]string{"id", "items"}, []any{1, `not json`})
+	if _, err = AllFromRows(context.Background(), StructMapper[OrderWithItems](), rows); err == nil {
+		t.Fatal("expected an error for invalid JSON")
+	}
+}
+
+func TestWithJSONColumns(t *testing.T) {
+	type settings struct {
+		Theme string `json:"theme"`
+	}
+
+	type account struct {
+		ID       int
+		Settings settings
+		Tags     []string
+		Labels   map[string]string
+	}
+
+	rows := newSliceRows([]string{"id", "settings", "tags", "labels"},
+		[]any{1, `{"theme":"dark"}`, []byte(`["a","b"]`), `{"k":"v"}`},
+		[]any{2, nil, nil, nil},
+	)
+
+	m := StructMapper[account](WithJSONColumns("settings", "tags", "labels"))
+	accounts, err := AllFromRows(context.Background(), m, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []account{
+		{ID: 1, Settings: settings{Theme: "dark"}, Tags: []string{"a", "b"}, Labels: map[string]string{"k": "v"}},
+		{ID: 2},
+	}
+	if diff := cmp.Diff(expected, accounts); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The fields of the decoded struct are no longer mapped
+	rows = newSliceRows([]string{"id", "settings.theme"}, []any{1, "dark"})
+	if _, err := AllFromRows(context.Background(), m, rows); err == nil {
+		t.Fatal("expected an error for the column of a decoded struct")
+	}
+}
+
+func TestJSONScanner(t *testing.T) {
+	var items []Item
+	if err := JSON(&items).Scan(`[{"sku":"a","qty":2}]`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]Item{{SKU: "a", Qty: 2}}, items); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if err := JSON(&items).Scan(nil); err != nil || items != nil {
+		t.Fatalf("expected NULL to reset the value, got %v and %v", items, err)
+	}
+}
+
+type Invoice struct {
+	Number string `xml:"number,attr"`
+	Lines  []struct {
+		Amount int `xml:"amount"`
+	} `xml:"line"`
+}
+
+type Document struct {
+	ID      int
+	Payload *Invoice `db:"payload,xml"`
+}
+
+func TestXMLColumns(t *testing.T) {
+	rows := newSliceRows([]string{"id", "payload"},
+		[]any{1, []byte(`<invoice number="A1"><line><amount>5</amount></line><line><amount>7</amount></line></invoice>`)},
+		[]any{2, nil},
+	)
+
+	docs, err := AllFromRows(context.Background(), StructMapper[Document](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(docs) != 2 || docs[0].Payload == nil || docs[1].Payload != nil {
+		t.Fatalf("unexpected documents: %#v", docs)
+	}
+
+	if docs[0].Payload.Number != "A1" || len(docs[0].Payload.Lines) != 2 || docs[0].Payload.Lines[1].Amount != 7 {
+		t.Fatalf("unexpected payload: %#v", docs[0].Payload)
+	}
+
+	rows = newSliceRows([]string{"id", "payload"}, []any{1, `<invoice`})
+	if _, err = AllFromRows(context.Background(), StructMapper[Document](), rows); err == nil {
+		t.Fatal("expected an error for invalid XML")
+	}
+}
+
+type Signed struct {
+	ID   int
+	Sig  []byte  `db:"sig,hex"`
+	Blob *[]byte `db:"blob,base64"`
+}
+
+func TestEncodedBytesColumns(t *testing.T) {
+	rows := newSliceRows([]string{"id", "sig", "blob"},
+		[]any{1, "cafe01", []byte("aGVsbG8=")},
+		[]any{2, nil, nil},
+	)
+
+	signed, err := AllFromRows(context.Background(), StructMapper[Signed](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	blob := []byte("hello")
+	expected := []Signed{
+		{ID: 1, Sig: []byte{0xca, 0xfe, 0x01}, Blob: &blob},
+		{ID: 2},
+	}
+	if diff := cmp.Diff(expected, signed); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows([]string{"id", "sig"}, []any{1, "xyz"})
+	if _, err = AllFromRows(context.Background(), StructMapper[Signed](), rows); err == nil {
+		t.Fatal("expected an error for invalid hex")
+	}
+
+	type wrongType struct {
+		Sig string `db:"sig,hex"`
+	}
+	rows = newSliceRows([]string{"sig"}, []any{"cafe"})
+	if _, err = AllFromRows(context.Background(), StructMapper[wrongType](), rows); err == nil {
+		t.Fatal("expected an error for a field that is not []byte")
+	}
+}
+
+type Dimensions struct {
+	Width  int
+	Height int
+}
+
+type Package struct {
+	Label   string
+	Fragile bool
+	Note    *string
+	Sent    time.Time
+	Size    Dimensions
+}
+
+type Shipment struct {
+	ID      int
+	Package Package  `db:"package,composite"`
+	Return  *Package `db:"return,composite"`
+}
+
+func TestCompositeColumns(t *testing.T) {
+	rows := newSliceRows([]string{"id", "package", "return"},
+		[]any{1, `("big \"box\", heavy",t,,"2023-01-02 15:04:05+00","(10,20)")`, nil},
+	)
+
+	shipments, err := AllFromRows(context.Background(), StructMapper[Shipment](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Shipment{{
+		ID: 1,
+		Package: Package{
+			Label:   `big "box", heavy`,
+			Fragile: true,
+			Sent:    time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC),
+			Size:    Dimensions{Width: 10, Height: 20},
+		},
+	}}
+	if diff := cmp.Diff(expected, shipments, cmp.Comparer(func(a, b time.Time) bool { return a.Equal(b) })); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows([]string{"id", "package"}, []any{1, `(a,t)`})
+	if _, err = AllFromRows(context.Background(), StructMapper[Shipment](), rows); err == nil {
+		t.Fatal("expected an error for the wrong number of fields")
+	}
+}
+
+func TestParseComposite(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	cases := map[string][]*string{
+		`()`:              {nil},
+		`(1,,"")`:         {str("1"), nil, str("")},
+		`("a""b",c\,d)`:   {str(`a"b`), str("c,d")},
+		`("(1,2)",x)`:     {str("(1,2)"), str("x")},
+		`( spaced , x )`:  {str(" spaced "), str(" x ")},
+		`("with ""q"" ")`: {str(`with "q" `)},
+	}
+
+	for input, expected := range cases {
+		got, err := parseComposite(input)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", input, err)
+		}
+		if diff := cmp.Diff(expected, got); diff != "" {
+			t.Fatalf("%s: diff: %s", input, diff)
+		}
+	}
+
+	if _, err := parseComposite(`(1,"unterminated)`); err == nil {
+		t.Fatal("expected an error for an unterminated quote")
+	}
+}
+
+type Post struct {
+	ID       int
+	Tags     []string     `db:"tags,array"`
+	Scores   []int64      `db:"scores,array"`
+	Flags    *[]bool      `db:"flags,array"`
+	Ratings  []*float64   `db:"ratings,array"`
+	Matrix   [][]int      `db:"matrix,array"`
+	Packages []Dimensions `db:"packages,array"`
+}
+
+func TestArrayColumns(t *testing.T) {
+	rows := newSliceRows([]string{"id", "tags", "scores", "flags", "ratings", "matrix", "packages"},
+		[]any{
+			1, `{go,"sql, db","with \"quotes\"",NULL}`, []byte(`{1,2,3}`), `{t,f}`,
+			`{1.5,NULL}`, `{{1,2},{3,4}}`, `{"(1,2)","(3,4)"}`,
+		},
+		[]any{2, `{}`, nil, nil, nil, `[0:0]={{5}}`, nil},
+	)
+
+	posts, err := AllFromRows(context.Background(), StructMapper[Post](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rating := 1.5
+	expected := []Post{
+		{
+			ID:       1,
+			Tags:     []string{"go", "sql, db", `with "quotes"`, ""},
+			Scores:   []int64{1, 2, 3},
+			Flags:    &[]bool{true, false},
+			Ratings:  []*float64{&rating, nil},
+			Matrix:   [][]int{{1, 2}, {3, 4}},
+			Packages: []Dimensions{{Width: 1, Height: 2}, {Width: 3, Height: 4}},
+		},
+		{ID: 2, Tags: []string{}, Matrix: [][]int{{5}}},
+	}
+	if diff := cmp.Diff(expected, posts); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	for _, value := range []string{`{1,x}`, `{1,2`, `{"unterminated}`} {
+		rows = newSliceRows([]string{"id", "scores"}, []any{1, value})
+		if _, err = AllFromRows(context.Background(), StructMapper[Post](), rows); err == nil {
+			t.Fatalf("expected an error for %s", value)
+		}
+	}
+}
+
+func TestTrimColumns(t *testing.T) {
+	type account struct {
+		Code     string       `db:"code,trim"`
+		Nickname *string      `db:"nickname,trim,nullifempty"`
+		Note     Null[string] `db:",nullifempty"`
+		Sig      []byte       `db:"sig,trim,hex"`
+		Spaced   string       `db:"spaced"`
+	}
+
+	rows := newSliceRows([]string{"code", "nickname", "note", "sig", "spaced"},
+		[]any{"AB   ", []byte("bob  "), "hi", "cafe  ", "x  "},
+		[]any{[]byte("CD"), "     ", "", "", "y"},
+		[]any{"", nil, nil, nil, ""},
+	)
+
+	accounts, err := AllFromRows(context.Background(), StructMapper[account](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []account{
+		{Code: "AB", Nickname: toPtr("bob"), Note: NullOf("hi"), Sig: []byte{0xca, 0xfe}, Spaced: "x  "},
+		{Code: "CD", Sig: []byte{}, Spaced: "y"},
+		{},
+	}
+	if diff := cmp.Diff(expected, accounts); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestDefaultValues(t *testing.T) {
+	type profile struct {
+		Status  string          `db:"status,default=active"`
+		Limit   int             `db:"limit,default=10"`
+		Note    *string         `db:"note,default=none"`
+		Admin   bool            `db:"admin,default=N"`
+		Tags    []string        `db:"tags,json,default=[\"new\"]"`
+		Plain   string          `db:"plain"`
+		Options *settingsHolder `db:"options"`
+	}
+
+	ctx := context.Background()
+	m := StructMapper[profile](WithBoolValues(BoolValues{True: []string{"Y"}, False: []string{"N"}}), WithEnforceAllFields(true))
+
+	rows := newSliceRows([]string{"status", "note", "admin", "tags", "plain"},
+		[]any{"blocked", "hi", "Y", `["a"]`, "x"},
+		[]any{nil, nil, nil, nil, ""},
+	)
+
+	profiles, err := AllFromRows(ctx, m, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []profile{
+		{Status: "blocked", Limit: 10, Note: toPtr("hi"), Admin: true, Tags: []string{"a"}, Plain: "x"},
+		{Status: "active", Limit: 10, Note: toPtr("none"), Tags: []string{"new"}},
+	}
+	if diff := cmp.Diff(expected, profiles); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Fields of a struct reached through a nil pointer are not set
+	holders, err := AllFromRows(ctx, StructMapper[settingsHolder](), newSliceRows([]string{"id"}, []any{1}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]settingsHolder{{ID: 1, Theme: "light"}}, holders); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Defaults that cannot be decoded return an error when building the mapper
+	type invalid struct {
+		Limit int `db:"limit,default=many"`
+	}
+
+	_, err = AllFromRows(ctx, StructMapper[invalid](), newSliceRows([]string{"limit"}, []any{1}))
+	if !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrConversion, got %v", err)
+	}
+}
+
+type settingsHolder struct {
+	ID     int
+	Theme  string `db:"theme,default=light"`
+	Nested *struct {
+		Size int `db:"size,default=3"`
+	} `db:"nested"`
+}
@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Dialect is the placeholder style of a database, used by [Rebind]
+type Dialect int
+
+const (
+	// MySQL uses ? placeholders
+	MySQL Dialect = iota
+	// SQLite uses ? placeholders
+	SQLite
+	// Postgres uses $1, $2, ... placeholders
+	Postgres
+	// MSSQL uses @p1, @p2, ... placeholders
+	MSSQL
+)
+
+// String returns the name of the dialect
+func (d Dialect) String() string {
+	switch d {
+	case MySQL:
+		return "MySQL"
+	case SQLite:
+		return "SQLite"
+	case Postgres:
+		return "Postgres"
+	case MSSQL:
+		return "MSSQL"
+	default:
+		return fmt.Sprintf("Dialect(%d)", int(d))
+	}
+}
+
+// placeholder returns the placeholder for the nth arg, starting at 1
+func (d Dialect) placeholder(n int) string {
+	switch d {
+	case Postgres:
+		return "$" + strconv.Itoa(n)
+	case MSSQL:
+		return "@p" + strconv.Itoa(n)
+	default:
+		return "?"
+	}
+}
+
+// Rebind rewrites the ? placeholders of the query into the placeholders of the dialect,
+// so that the same query text can run with several drivers, e.g. with the output of [Where] or [Named].
+// Placeholders inside quoted strings, quoted identifiers and comments are left as is,
+// and ?? is written as a single ? for operators such as the jsonb ? in Postgres.
+//
+//	scan.Rebind(scan.Postgres, "SELECT * FROM users WHERE id = ? AND status = ?")
+//	// SELECT * FROM users WHERE id = $1 AND status = $2
+func Rebind(d Dialect, query string) string {
+	var n int
+	rebound, _ := rewriteQuery(query, func(rest string) (string, int, error) {
+		if rest[0] != '?' {
+			return "", 0, nil
+		}
+
+		if len(rest) > 1 && rest[1] == '?' {
+			return "?", 2, nil
+		}
+
+		n++
+		return d.placeholder(n), 1, nil
+	})
+
+	return rebound
+}
@@ -0,0 +1,29 @@
+package scan
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	query := "SELECT '?', \"a?\" FROM t WHERE id = ? AND data ?? 'key' AND name = ? -- ?"
+
+	cases := map[Dialect]string{
+		MySQL:    "SELECT '?', \"a?\" FROM t WHERE id = ? AND data ? 'key' AND name = ? -- ?",
+		SQLite:   "SELECT '?', \"a?\" FROM t WHERE id = ? AND data ? 'key' AND name = ? -- ?",
+		Postgres: "SELECT '?', \"a?\" FROM t WHERE id = $1 AND data ? 'key' AND name = $2 -- ?",
+		MSSQL:    "SELECT '?', \"a?\" FROM t WHERE id = @p1 AND data ? 'key' AND name = @p2 -- ?",
+	}
+
+	for d, expected := range cases {
+		if got := Rebind(d, query); got != expected {
+			t.Fatalf("%s: expected %q, got %q", d, expected, got)
+		}
+	}
+
+	named, args, err := Named(MySQL, "SELECT * FROM users WHERE id = :id OR parent_id = :id", map[string]any{"id": 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := Rebind(Postgres, named); got != "SELECT * FROM users WHERE id = $1 OR parent_id = $2" || len(args) != 2 {
+		t.Fatalf("unexpected query %q with args %v", got, args)
+	}
+}
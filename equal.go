@@ -0,0 +1,152 @@
+package scan
+
+import "reflect"
+
+// EqualMapped reports if a and b have the same values in the fields that
+// [StructMapper] maps to columns. Other fields, such as those tagged with `db:"-"`, are ignored.
+// This is useful for deduplication, change detection and tests of scanned data.
+//
+// Values with an Equal method, such as [time.Time], are compared with it.
+// Values that are not structs or pointers to structs are compared with [reflect.DeepEqual]
+func EqualMapped[T any](a, b T) bool {
+	return CustomEqualMapped(defaultStructMapper, a, b)
+}
+
+// CustomEqualMapped is the same as [EqualMapped] but uses the mapping of the given [StructMapperSource]
+func CustomEqualMapped[T any](src StructMapperSource, a, b T) bool {
+	return len(mappedDiffs(src, a, b, true)) == 0
+}
+
+// MappedDiff is a difference between two values in a field that is mapped to a column.
+// Pointers are dereferenced, and the value is nil if the field is a nil pointer
+// or is inside a struct reached through a nil pointer
+type MappedDiff struct {
+	// Column is the column the field is mapped to.
+	// It is empty if the values are not structs, or if one of them is nil
+	Column string
+	A, B   any
+}
+
+// DiffMapped returns the differences between a and b in the fields that [StructMapper] maps to columns,
+// in the order of the fields. The values are compared the same way as [EqualMapped]
+func DiffMapped[T any](a, b T) []MappedDiff {
+	return CustomDiffMapped(defaultStructMapper, a, b)
+}
+
+// CustomDiffMapped is the same as [DiffMapped] but uses the mapping of the given [StructMapperSource]
+func CustomDiffMapped[T any](src StructMapperSource, a, b T) []MappedDiff {
+	return mappedDiffs(src, a, b, false)
+}
+
+// mappedDiffs returns the differences between the mapped fields of a and b.
+// If first is set, it stops at the first difference
+func mappedDiffs[T any](src StructMapperSource, a, b T, first bool) []MappedDiff {
+	whole := []MappedDiff{{A: a, B: b}}
+
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !va.IsValid() || !vb.IsValid() {
+		if va.IsValid() == vb.IsValid() {
+			return nil
+		}
+		return whole
+	}
+
+	if va.Kind() == reflect.Pointer {
+		if va.IsNil() || vb.IsNil() {
+			if va.IsNil() == vb.IsNil() {
+				return nil
+			}
+			return whole
+		}
+		va, vb = va.Elem(), vb.Elem()
+	}
+
+	if va.Kind() != reflect.Struct {
+		if reflect.DeepEqual(a, b) {
+			return nil
+		}
+		return whole
+	}
+
+	m, err := src.getMapping(va.Type())
+	if err != nil {
+		if reflect.DeepEqual(a, b) {
+			return nil
+		}
+		return whole
+	}
+
+	// Unexported fields can only be read from an addressable value
+	va, vb = addressable(va), addressable(vb)
+
+	var diffs []MappedDiff
+	for _, info := range m {
+		if info.container {
+			continue
+		}
+
+		fa, okA := fieldByIndex(va, info.position)
+		fb, okB := fieldByIndex(vb, info.position)
+		if okA == okB && (!okA || equalValues(fa, fb)) {
+			continue
+		}
+
+		diffs = append(diffs, MappedDiff{
+			Column: info.name,
+			A:      displayValue(fa, okA),
+			B:      displayValue(fb, okB),
+		})
+
+		if first {
+			break
+		}
+	}
+
+	return diffs
+}
+
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v
+	}
+
+	addr := reflect.New(v.Type()).Elem()
+	addr.Set(v)
+	return addr
+}
+
+// equalValues compares the values with their Equal method if they have one,
+// or with [reflect.DeepEqual]
+func equalValues(a, b reflect.Value) bool {
+	if a.Kind() == reflect.Pointer {
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+
+	if eq := a.MethodByName("Equal"); eq.IsValid() {
+		typ := eq.Type()
+		if typ.NumIn() == 1 && typ.In(0) == b.Type() && typ.NumOut() == 1 && typ.Out(0).Kind() == reflect.Bool {
+			return eq.Call([]reflect.Value{b})[0].Bool()
+		}
+	}
+
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// displayValue returns the value of the field to show in a [MappedDiff]
+func displayValue(v reflect.Value, ok bool) any {
+	if !ok {
+		return nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	return v.Interface()
+}
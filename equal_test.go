@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Snapshot struct {
+	ID      int
+	Tags    []string
+	Taken   time.Time
+	Expires *time.Time
+	Owner   *User
+	Loaded  time.Time `db:"-"`
+	private int
+}
+
+func TestEqualMapped(t *testing.T) {
+	now := time.Now()
+	utc := now.UTC()
+
+	a := Snapshot{ID: 1, Tags: []string{"a"}, Taken: now, Expires: &now, Owner: &User{ID: 1}, Loaded: now, private: 1}
+	b := Snapshot{ID: 1, Tags: []string{"a"}, Taken: utc, Expires: &utc, Owner: &User{ID: 1}, private: 2}
+
+	if !EqualMapped(a, b) {
+		t.Fatal("expected the values to be equal")
+	}
+
+	if !EqualMapped(&a, &b) || !EqualMapped[*Snapshot](nil, nil) {
+		t.Fatal("expected the pointers to be equal")
+	}
+
+	cases := map[string]func(s *Snapshot){
+		"id":          func(s *Snapshot) { s.ID = 2 },
+		"tags":        func(s *Snapshot) { s.Tags = append(s.Tags, "b") },
+		"time":        func(s *Snapshot) { s.Taken = s.Taken.Add(time.Second) },
+		"nil pointer": func(s *Snapshot) { s.Expires = nil },
+		"nested":      func(s *Snapshot) { s.Owner = &User{ID: 1, Name: "foo"} },
+		"nil struct":  func(s *Snapshot) { s.Owner = nil },
+	}
+
+	for name, change := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := b
+			change(&c)
+			if EqualMapped(a, c) {
+				t.Fatal("expected the values to differ")
+			}
+		})
+	}
+
+	if EqualMapped(&a, nil) {
+		t.Fatal("expected a nil pointer to differ")
+	}
+
+	if !EqualMapped([]int{1}, []int{1}) || EqualMapped(1, 2) {
+		t.Fatal("expected other values to be compared deeply")
+	}
+}
+
+func TestDiffMapped(t *testing.T) {
+	name := "foo"
+	a := PtrUser2{ID: 1, Name: &name, PtrTimestamps: &PtrTimestamps{}}
+	b := PtrUser2{ID: 2}
+
+	expected := []MappedDiff{
+		{Column: "id", A: 1, B: 2},
+		{Column: "name", A: "foo", B: nil},
+		{Column: "created_at", A: nil, B: nil},
+		{Column: "updated_at", A: nil, B: nil},
+	}
+	if diff := cmp.Diff(expected, DiffMapped(a, b)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if diffs := DiffMapped(a, a); diffs != nil {
+		t.Fatalf("expected no differences, got %v", diffs)
+	}
+
+	if diff := cmp.Diff([]MappedDiff{{A: 1, B: 2}}, DiffMapped(1, 2)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
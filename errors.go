@@ -0,0 +1,125 @@
+package scan
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// The kinds of [Error], to check with [errors.Is]
+var (
+	// ErrNoDestination is returned when a column has no field or value to scan into.
+	// See [WithAllowUnknownColumns] to ignore such columns
+	ErrNoDestination = errors.New("no destination for column")
+	// ErrUnknownColumn is returned when a scan is scheduled for a column that is not in the result set
+	ErrUnknownColumn = errors.New("unknown column")
+	// ErrConversion is returned when a column value cannot be converted to its destination
+	ErrConversion = errors.New("cannot convert column value")
+)
+
+// Error is returned when a row cannot be mapped. It holds the column, field and query
+// where the mapping failed when they are known, and wraps the original error.
+//
+// Use [errors.Is] with [ErrNoDestination], [ErrUnknownColumn] or [ErrConversion] to check the kind of error
+//
+//	var scanErr *scan.Error
+//	if errors.As(err, &scanErr) && errors.Is(err, scan.ErrConversion) {
+//	    log.Printf("bad value in column %s of %s", scanErr.Column(), scanErr.Query())
+//	}
+type Error struct {
+	kind   error
+	column string
+	field  string
+	query  string
+	meta   []string // easy compare
+	cause  error
+}
+
+// MappingError is the previous name of [Error]
+//
+// Deprecated: use [Error]
+type MappingError = Error
+
+// Returns an [Error] with some optional metadata
+func createError(err error, meta ...string) error {
+	if me, ok := err.(*Error); ok && len(meta) == 0 {
+		return me
+	}
+
+	return &Error{cause: err, meta: meta}
+}
+
+// columnError returns an [Error] of the kind for the column and field
+func columnError(kind error, column, field string, err error, meta ...string) *Error {
+	return &Error{kind: kind, column: column, field: field, cause: err, meta: meta}
+}
+
+// Column returns the name of the column, if known
+func (e *Error) Column() string {
+	return e.column
+}
+
+// Field returns the path of the struct field e.g. Address.Street, if known
+func (e *Error) Field() string {
+	return e.field
+}
+
+// Query returns the query that was run, if known
+func (e *Error) Query() string {
+	return e.query
+}
+
+// Is reports if the error is of the given kind
+func (e *Error) Is(target error) bool {
+	return e.kind != nil && e.kind == target
+}
+
+// Unwrap returns the wrapped error
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.cause == nil {
+		return ""
+	}
+
+	return e.cause.Error()
+}
+
+// withQuery returns a copy of err with the query, if it is an [Error]
+func withQuery(err error, query string) error {
+	e, ok := err.(*Error)
+	if !ok || e.query != "" {
+		return err
+	}
+
+	c := *e
+	c.query = query
+	return &c
+}
+
+// columnIndexRe matches the index of the column in
+// the scan errors of database/sql and pgx
+var columnIndexRe = regexp.MustCompile(`column index (\d+)|dest\[(\d+)\]`)
+
+// columnIndex returns the index of the column that failed to scan, or -1 if unknown
+func columnIndex(err error) int {
+	match := columnIndexRe.FindStringSubmatch(err.Error())
+	if match == nil {
+		return -1
+	}
+
+	index := match[1]
+	if index == "" {
+		index = match[2]
+	}
+
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return -1
+	}
+
+	return i
+}
@@ -0,0 +1,96 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	ctx := context.Background()
+	query := "SELECT * FROM users"
+
+	exec := func(cols []string, vals ...any) Queryer {
+		return funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+			return newSliceRows(cols, vals), nil
+		})
+	}
+
+	type userWithAddress struct {
+		ID      int
+		Address struct {
+			Street string
+			Zip    int
+		}
+	}
+
+	cases := map[string]struct {
+		exec   Queryer
+		mapper Mapper[userWithAddress]
+		kind   error
+		column string
+		field  string
+	}{
+		"no destination": {
+			exec:   exec([]string{"id", "email"}, 1, "a@b.c"),
+			mapper: StructMapper[userWithAddress](),
+			kind:   ErrNoDestination,
+			column: "email",
+		},
+		"conversion": {
+			exec:   exec([]string{"id", "address.zip"}, 1, "not a number"),
+			mapper: StructMapper[userWithAddress](),
+			kind:   ErrConversion,
+			column: "address.zip",
+			field:  "Address.Zip",
+		},
+		"null value": {
+			exec:   exec([]string{"id", "address.street"}, 1, nil),
+			mapper: StructMapper[userWithAddress](WithNullHandling(NullError)),
+			kind:   ErrConversion,
+			column: "address.street",
+			field:  "Address.Street",
+		},
+		"unknown column": {
+			exec: exec([]string{"id"}, 1),
+			mapper: func(ctx context.Context, c cols) (BeforeFunc, func(any) (userWithAddress, error)) {
+				return func(v *Row) (any, error) {
+						v.ScheduleScan("id", new(int))
+						v.ScheduleScan("missing", new(int))
+						return nil, nil
+					}, func(any) (userWithAddress, error) {
+						return userWithAddress{}, nil
+					}
+			},
+			kind:   ErrUnknownColumn,
+			column: "missing",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := One(ctx, tc.exec, tc.mapper, query)
+
+			var scanErr *Error
+			if !errors.As(err, &scanErr) {
+				t.Fatalf("expected a *scan.Error, got %T: %v", err, err)
+			}
+
+			if !errors.Is(err, tc.kind) {
+				t.Fatalf("expected the error to be %v, got %v", tc.kind, err)
+			}
+
+			if scanErr.Column() != tc.column {
+				t.Fatalf("expected column %q, got %q", tc.column, scanErr.Column())
+			}
+
+			if scanErr.Field() != tc.field {
+				t.Fatalf("expected field %q, got %q", tc.field, scanErr.Field())
+			}
+
+			if scanErr.Query() != query {
+				t.Fatalf("expected query %q, got %q", query, scanErr.Query())
+			}
+		})
+	}
+}
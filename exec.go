@@ -3,19 +3,21 @@ package scan
 import (
 	"context"
 	"database/sql"
+	"errors"
 )
 
 // One scans a single row from the query and maps it to T using a [Queryer]
 func One[T any](ctx context.Context, exec Queryer, m Mapper[T], query string, args ...any) (T, error) {
 	var t T
 
-	rows, err := exec.QueryContext(ctx, query, args...)
+	rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
 	if err != nil {
 		return t, err
 	}
 	defer rows.Close()
 
-	return OneFromRows(ctx, m, rows)
+	t, err = OneFromRows(ctx, m, rows)
+	return t, withQuery(err, query)
 }
 
 // OneFromRows scans a single row from the given [Rows] result and maps it to T using a [Queryer]
@@ -28,13 +30,13 @@ func OneFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) (T, error)
 		return t, err
 	}
 
-	before, after := m(ctx, v.columnsCopy())
+	before, after := buildMapper(ctx, m, v, v.columnsCopy())
 
 	if !rows.Next() {
 		if err = rows.Err(); err != nil {
 			return t, err
 		}
-		return t, sql.ErrNoRows
+		return t, ErrNoRows
 	}
 
 	t, err = scanOneRow(v, before, after)
@@ -45,18 +47,119 @@ func OneFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) (T, error)
 	return t, rows.Err()
 }
 
-// All scans all rows from the query and returns a slice []T of all rows using a [Queryer]
+// ErrNoRows is returned by [One] and the other functions that scan a single row
+// when the query returns no rows. It is the same error as [sql.ErrNoRows],
+// so existing checks against either keep working
+var ErrNoRows = sql.ErrNoRows
+
+// ErrTooManyRows is returned by [ExactlyOne] when the query returns more than one row
+var ErrTooManyRows = errors.New("more than one row")
+
+// OneOrZero is like [One], but returns false instead of [ErrNoRows]
+// if the query returns no rows
+//
+//	user, found, err := scan.OneOrZero(ctx, exec, scan.StructMapper[User](), query, id)
+func OneOrZero[T any](ctx context.Context, exec Queryer, m Mapper[T], query string, args ...any) (T, bool, error) {
+	return oneOrZero(One(ctx, exec, m, query, args...))
+}
+
+// OneOrZeroFromRows is like [OneFromRows], but returns false instead of [ErrNoRows]
+// if there are no rows
+func OneOrZeroFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) (T, bool, error) {
+	return oneOrZero(OneFromRows(ctx, m, rows))
+}
+
+func oneOrZero[T any](t T, err error) (T, bool, error) {
+	if errors.Is(err, ErrNoRows) {
+		return t, false, nil
+	}
+
+	if err != nil {
+		return t, false, err
+	}
+
+	return t, true, nil
+}
+
+// First scans the first row from the query and maps it to T, ignoring the other rows.
+// It is the same as [One], for callers that want to make it clear that more rows may match
+func First[T any](ctx context.Context, exec Queryer, m Mapper[T], query string, args ...any) (T, error) {
+	return One(ctx, exec, m, query, args...)
+}
+
+// FirstFromRows scans the first row from the given [Rows] result and maps it to T,
+// ignoring the other rows
+func FirstFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) (T, error) {
+	return OneFromRows(ctx, m, rows)
+}
+
+// ExactlyOne is like [One], but returns [ErrTooManyRows] if the query returns
+// more than one row, so that callers can assert that a row is unique
+func ExactlyOne[T any](ctx context.Context, exec Queryer, m Mapper[T], query string, args ...any) (T, error) {
+	var t T
+
+	rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
+	if err != nil {
+		return t, err
+	}
+	defer rows.Close()
+
+	t, err = ExactlyOneFromRows(ctx, m, rows)
+	return t, withQuery(err, query)
+}
+
+// ExactlyOneFromRows is like [OneFromRows], but returns [ErrTooManyRows]
+// if there is more than one row
+func ExactlyOneFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) (T, error) {
+	t, err := OneFromRows(ctx, m, rows)
+	if err != nil {
+		return t, err
+	}
+
+	if rows.Next() {
+		var zero T
+		return zero, ErrTooManyRows
+	}
+
+	return t, rows.Err()
+}
+
+// Count runs a query that returns a single number, such as SELECT COUNT(*), and returns it.
+// A NULL value is returned as 0
+//
+//	n, err := scan.Count(ctx, exec, "SELECT COUNT(*) FROM users WHERE status = $1", "active")
+func Count(ctx context.Context, exec Queryer, query string, args ...any) (int64, error) {
+	n, err := One(ctx, exec, SingleColumnMapper[Null[int64]], query, args...)
+	return n.V, err
+}
+
+// Exists runs a query that returns a single boolean, such as SELECT EXISTS (...), and returns it.
+// A query that returns no rows, such as SELECT 1 ... LIMIT 1, returns false,
+// and a NULL value is returned as false
+//
+//	found, err := scan.Exists(ctx, exec, "SELECT EXISTS (SELECT 1 FROM users WHERE email = $1)", email)
+func Exists(ctx context.Context, exec Queryer, query string, args ...any) (bool, error) {
+	b, _, err := OneOrZero(ctx, exec, SingleColumnMapper[Null[bool]], query, args...)
+	return b.V, err
+}
+
+// All scans all rows from the query and returns a slice []T of all rows using a [Queryer].
+// See [AllFromRows] for how context cancellation is handled
 func All[T any](ctx context.Context, exec Queryer, m Mapper[T], query string, args ...any) ([]T, error) {
-	rows, err := exec.QueryContext(ctx, query, args...)
+	rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	return AllFromRows(ctx, m, rows)
+	results, err := AllFromRows(ctx, m, rows)
+	return results, withQuery(err, query)
 }
 
 // AllFromRows scans all rows from the given [Rows] and returns a slice []T of all rows using a [Queryer]
+//
+// The context is checked between rows. If it is done, scanning stops and
+// the rows scanned so far are returned with the context's error
 func AllFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) ([]T, error) {
 	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
 	v, err := wrapRows(rows, allowUnknown)
@@ -64,11 +167,19 @@ func AllFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) ([]T, error
 		return nil, err
 	}
 
-	before, after := m(ctx, v.columnsCopy())
+	before, after := buildMapper(ctx, m, v, v.columnsCopy())
 
 	var results []T
 	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			accountResults(ctx, results)
+			return results, err
+		}
+
 		one, err := scanOneRow(v, before, after)
+		if isSkipped(err) {
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -76,17 +187,157 @@ func AllFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) ([]T, error
 		results = append(results, one)
 	}
 
+	accountResults(ctx, results)
 	return results, rows.Err()
 }
 
+// AllIndexed scans all rows from the query and returns them in a map keyed by keyFn,
+// e.g. by the ID of each row. If several rows have the same key, the last one is kept.
+// See [AllFromRows] for how context cancellation is handled
+func AllIndexed[K comparable, T any](ctx context.Context, exec Queryer, m Mapper[T], keyFn func(T) K, query string, args ...any) (map[K]T, error) {
+	rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results, err := AllIndexedFromRows(ctx, m, keyFn, rows)
+	return results, withQuery(err, query)
+}
+
+// AllIndexedFromRows scans all rows from the given [Rows] and returns them in a map keyed by keyFn
+func AllIndexedFromRows[K comparable, T any](ctx context.Context, m Mapper[T], keyFn func(T) K, rows Rows) (map[K]T, error) {
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+	v, err := wrapRows(rows, allowUnknown)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after := buildMapper(ctx, m, v, v.columnsCopy())
+
+	results := make(map[K]T)
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		one, err := scanOneRow(v, before, after)
+		if isSkipped(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		results[keyFn(one)] = one
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// AllWhile scans the rows from the query while keep returns true for the mapped rows,
+// and returns them in a slice. The first row for which keep returns false is not included,
+// and no more rows are fetched, e.g. to consume a tailing query until a timestamp.
+// See [AllFromRows] for how context cancellation is handled
+func AllWhile[T any](ctx context.Context, exec Queryer, m Mapper[T], keep func(T) bool, query string, args ...any) ([]T, error) {
+	rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results, err := AllWhileFromRows(ctx, m, keep, rows)
+	return results, withQuery(err, query)
+}
+
+// AllWhileFromRows is like [AllWhile] for the given [Rows].
+// The rows are closed as soon as keep returns false
+func AllWhileFromRows[T any](ctx context.Context, m Mapper[T], keep func(T) bool, rows Rows) ([]T, error) {
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+	v, err := wrapRows(rows, allowUnknown)
+	if err != nil {
+		return nil, err
+	}
+
+	before, after := buildMapper(ctx, m, v, v.columnsCopy())
+
+	var results []T
+	for rows.Next() {
+		if err := ctx.Err(); err != nil {
+			accountResults(ctx, results)
+			return results, err
+		}
+
+		one, err := scanOneRow(v, before, after)
+		if isSkipped(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !keep(one) {
+			accountResults(ctx, results)
+			return results, rows.Close()
+		}
+
+		results = append(results, one)
+	}
+
+	accountResults(ctx, results)
+	return results, rows.Err()
+}
+
+// ctxKeyMappingOptions holds the [MappingOption]s for a single query
+var ctxKeyMappingOptions contextKey = "mapping options"
+
+// WithMappingOptions returns a context that applies the options to every
+// struct mapper used with it, after the options the mapper was created with
+func WithMappingOptions(ctx context.Context, opts ...MappingOption) context.Context {
+	prev, _ := ctx.Value(ctxKeyMappingOptions).([]MappingOption)
+	merged := make([]MappingOption, 0, len(prev)+len(opts))
+	merged = append(merged, prev...)
+	merged = append(merged, opts...)
+
+	return context.WithValue(ctx, ctxKeyMappingOptions, merged)
+}
+
+// OneWithOptions is like [One] but applies the [MappingOption]s
+// to the struct mapper for only this query
+func OneWithOptions[T any](ctx context.Context, exec Queryer, m Mapper[T], opts []MappingOption, query string, args ...any) (T, error) {
+	return One(WithMappingOptions(ctx, opts...), exec, m, query, args...)
+}
+
+// AllWithOptions is like [All] but applies the [MappingOption]s
+// to the struct mapper for only this query
+func AllWithOptions[T any](ctx context.Context, exec Queryer, m Mapper[T], opts []MappingOption, query string, args ...any) ([]T, error) {
+	return All(WithMappingOptions(ctx, opts...), exec, m, query, args...)
+}
+
+// CursorWithOptions is like [Cursor] but applies the [MappingOption]s
+// to the struct mapper for only this query
+func CursorWithOptions[T any](ctx context.Context, exec Queryer, m Mapper[T], opts []MappingOption, query string, args ...any) (ICursor[T], error) {
+	return Cursor(WithMappingOptions(ctx, opts...), exec, m, query, args...)
+}
+
 // Cursor runs a query and returns a cursor that works similar to *sql.Rows
 func Cursor[T any](ctx context.Context, exec Queryer, m Mapper[T], query string, args ...any) (ICursor[T], error) {
-	rows, err := exec.QueryContext(ctx, query, args...)
+	rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
 	if err != nil {
 		return nil, err
 	}
 
-	return CursorFromRows(ctx, m, rows)
+	c, err := CursorFromRows(ctx, m, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	c.(*cursor[T]).query = query
+	return c, nil
 }
 
 // CursorFromRows returns a cursor from [Rows] that works similar to *sql.Rows
@@ -97,7 +348,7 @@ func CursorFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) (ICursor
 		return nil, err
 	}
 
-	before, after := m(ctx, v.columnsCopy())
+	before, after := buildMapper(ctx, m, v, v.columnsCopy())
 
 	return &cursor[T]{
 		v:      v,
@@ -107,6 +358,16 @@ func CursorFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) (ICursor
 }
 
 func scanOneRow[T any](v *Row, before func(*Row) (any, error), after func(any) (T, error)) (T, error) {
+	t, err := mapOneRow(v, before, after)
+	if isSkipped(err) {
+		traceRow(v, nil, nil)
+	} else {
+		traceRow(v, t, err)
+	}
+	return t, err
+}
+
+func mapOneRow[T any](v *Row, before func(*Row) (any, error), after func(any) (T, error)) (T, error) {
 	val, err := before(v)
 	if err != nil {
 		var t T
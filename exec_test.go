@@ -3,6 +3,7 @@ package scan
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
@@ -190,6 +191,15 @@ func TestColumnValue(t *testing.T) {
 		expectAll: []int{1, 2, 3, 5, 8, 13, 21},
 	})
 
+	testQuery(t, "ignore other columns", queryCase[string]{
+		columns:   strstr{{"id", "int64"}, {"name", "string"}, {"email", "string"}},
+		rows:      rows{[]any{1, "foo", "foo@example.com"}, []any{2, "bar", "bar@example.com"}},
+		query:     []string{"id", "name", "email"},
+		mapper:    ColumnMapper[string]("name"),
+		expectOne: "foo",
+		expectAll: []string{"foo", "bar"},
+	})
+
 	testQuery(t, "unknown", queryCase[int]{
 		columns:     strstr{{"id", "int64"}},
 		rows:        singleRows(1, 2, 3, 5, 8, 13, 21),
@@ -314,3 +324,341 @@ func TestAllowUnknownColumns(t *testing.T) {
 		expectAll: []testStruct{{ID: 1, Int: 1}, {ID: 2, Int: 2}},
 	})
 }
+
+func TestMappingOptions(t *testing.T) {
+	type testStruct struct {
+		ID  int64
+		Int int64
+	}
+
+	testQuery(t, "allowunknownoption", queryCase[testStruct]{
+		columns:   strstr{{"id", "int64"}, {"ignored_int", "int64"}, {"int", "int64"}},
+		rows:      rows{{1, 10, 1}, {2, 20, 2}},
+		query:     []string{"id", "ignored_int", "int"},
+		mapper:    StructMapper[testStruct](WithAllowUnknownColumns(true)),
+		expectOne: testStruct{ID: 1, Int: 1},
+		expectAll: []testStruct{{ID: 1, Int: 1}, {ID: 2, Int: 2}},
+	})
+
+	testQuery(t, "perqueryoptions", queryCase[testStruct]{
+		ctx: WithMappingOptions(context.Background(),
+			WithStructTagPrefix("t_"),
+			WithAllowUnknownColumns(true),
+		),
+		columns:   strstr{{"t_id", "int64"}, {"id", "int64"}, {"t_int", "int64"}},
+		rows:      rows{{1, 10, 1}, {2, 20, 2}},
+		query:     []string{"t_id", "id", "t_int"},
+		mapper:    StructMapper[testStruct](),
+		expectOne: testStruct{ID: 1, Int: 1},
+		expectAll: []testStruct{{ID: 1, Int: 1}, {ID: 2, Int: 2}},
+	})
+
+	testQuery(t, "perqueryoptionsmappable", queryCase[MappableUser]{
+		ctx:       WithMappingOptions(context.Background(), WithAllowUnknownColumns(true)),
+		columns:   strstr{{"identifier", "int64"}, {"name", "string"}, {"extra", "string"}},
+		rows:      rows{{1, "foo", "x"}, {2, "bar", "y"}},
+		query:     []string{"identifier", "name", "extra"},
+		mapper:    StructMapper[MappableUser](),
+		expectOne: MappableUser{ID: 1, Name: "foo"},
+		expectAll: []MappableUser{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}},
+	})
+
+	testQuery(t, "perqueryoptionswithoutmapvalues", queryCase[MappableUser]{
+		ctx:       WithMappingOptions(context.Background(), WithoutMapValues()),
+		columns:   strstr{{"id", "int64"}, {"name", "string"}},
+		rows:      rows{{1, "foo"}, {2, "bar"}},
+		query:     []string{"id", "name"},
+		mapper:    StructMapper[MappableUser](),
+		expectOne: MappableUser{ID: 1, Name: "foo"},
+		expectAll: []MappableUser{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}},
+	})
+}
+
+func TestOneWithOptions(t *testing.T) {
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"u.id", "u.name", "other"}, []any{1, "foo", "bar"}), nil
+	})
+
+	opts := []MappingOption{WithStructTagPrefix("u."), WithAllowUnknownColumns(true)}
+	user, err := OneWithOptions(context.Background(), exec, StructMapper[User](), opts, "SELECT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(User{ID: 1, Name: "foo"}, user); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The options should not leak into other queries
+	_, err = One(context.Background(), funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"u.id", "u.name", "other"}, []any{1, "foo", "bar"}), nil
+	}), StructMapper[User](), "SELECT")
+	if err == nil {
+		t.Fatal("expected error without the per-query options")
+	}
+}
+
+func TestEnforceAllFields(t *testing.T) {
+	type testStruct struct {
+		ID   int64
+		Int  int64
+		Opts *struct {
+			Value int64
+		}
+	}
+
+	testQuery(t, "missingfield", queryCase[testStruct]{
+		columns:     strstr{{"id", "int64"}},
+		rows:        rows{{1}, {2}},
+		query:       []string{"id"},
+		mapper:      StructMapper[testStruct](WithEnforceAllFields(true)),
+		expectedErr: createError(nil, "missing fields", "int"),
+	})
+
+	testQuery(t, "missingfieldctx", queryCase[testStruct]{
+		ctx:         context.WithValue(context.Background(), CtxKeyEnforceAllFields, true),
+		columns:     strstr{{"id", "int64"}},
+		rows:        rows{{1}, {2}},
+		query:       []string{"id"},
+		mapper:      StructMapper[testStruct](),
+		expectedErr: createError(nil, "missing fields", "int"),
+	})
+
+	testQuery(t, "allfields", queryCase[testStruct]{
+		columns:   strstr{{"id", "int64"}, {"int", "int64"}},
+		rows:      rows{{1, 10}, {2, 20}},
+		query:     []string{"id", "int"},
+		mapper:    StructMapper[testStruct](WithEnforceAllFields(true)),
+		expectOne: testStruct{ID: 1, Int: 10},
+		expectAll: []testStruct{{ID: 1, Int: 10}, {ID: 2, Int: 20}},
+	})
+}
+
+func TestAllContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cancelAfterFirst := AfterRow(func(ctx context.Context, u User) error {
+		cancel()
+		return nil
+	})
+
+	rows := newSliceRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"}, []any{3, "baz"})
+	users, err := AllFromRows(ctx, StructMapper[User](WithMapperMods(cancelAfterFirst)), rows)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if diff := cmp.Diff([]User{{ID: 1, Name: "foo"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestAllWhile(t *testing.T) {
+	ctx := context.Background()
+	rows := newSliceRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"}, []any{3, "baz"})
+
+	var mapped int
+	users, err := AllWhileFromRows(ctx, StructMapper[User](), func(u User) bool {
+		mapped++
+		return u.ID < 2
+	}, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]User{{ID: 1, Name: "foo"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if mapped != 2 || !rows.closed {
+		t.Fatalf("expected the rows to be closed after 2 rows, got %d rows", mapped)
+	}
+
+	users, err = AllWhile(ctx, funcQ(usersByID), StructMapper[User](), func(User) bool { return true }, "", 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(users) != 2 {
+		t.Fatalf("expected all rows, got %d", len(users))
+	}
+}
+
+func TestAllIndexed(t *testing.T) {
+	ctx := context.Background()
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"}, []any{1, "baz"}), nil
+	})
+
+	users, err := AllIndexed(ctx, exec, StructMapper[User](), func(u User) int { return u.ID }, "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := map[int]User{
+		1: {ID: 1, Name: "baz"},
+		2: {ID: 2, Name: "bar"},
+	}
+	if diff := cmp.Diff(expected, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	empty := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "name"}), nil
+	})
+
+	users, err = AllIndexed(ctx, empty, StructMapper[User](), func(u User) int { return u.ID }, "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if users == nil || len(users) != 0 {
+		t.Fatalf("expected an empty map, got %v", users)
+	}
+}
+
+func TestFirstAndExactlyOne(t *testing.T) {
+	ctx := context.Background()
+	rowsOf := func(n int) Queryer {
+		return funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+			rows := make([][]any, n)
+			for i := range rows {
+				rows[i] = []any{i + 1, "user"}
+			}
+			return newSliceRows([]string{"id", "name"}, rows...), nil
+		})
+	}
+
+	first, err := First(ctx, rowsOf(2), StructMapper[User](), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(User{ID: 1, Name: "user"}, first); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	one, err := ExactlyOne(ctx, rowsOf(1), StructMapper[User](), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(User{ID: 1, Name: "user"}, one); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	one, err = ExactlyOne(ctx, rowsOf(2), StructMapper[User](), "SELECT id, name FROM users")
+	if !errors.Is(err, ErrTooManyRows) {
+		t.Fatalf("expected ErrTooManyRows, got %v", err)
+	}
+
+	if diff := cmp.Diff(User{}, one); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if _, err := ExactlyOne(ctx, rowsOf(0), StructMapper[User](), "SELECT id, name FROM users"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+}
+
+func TestOneOrZero(t *testing.T) {
+	ctx := context.Background()
+	users := func(rows ...[]any) Queryer {
+		return funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+			return newSliceRows([]string{"id", "name"}, rows...), nil
+		})
+	}
+
+	user, found, err := OneOrZero(ctx, users([]any{1, "foo"}), StructMapper[User](), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !found {
+		t.Fatal("expected a row")
+	}
+
+	if diff := cmp.Diff(User{ID: 1, Name: "foo"}, user); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	user, found, err = OneOrZero(ctx, users(), StructMapper[User](), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if found {
+		t.Fatal("expected no row")
+	}
+
+	if diff := cmp.Diff(User{}, user); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	_, found, err = OneOrZero(ctx, users([]any{"x", "foo"}), StructMapper[User](), "SELECT id, name FROM users")
+	if err == nil || found {
+		t.Fatalf("expected a conversion error, got %v", err)
+	}
+
+	if _, err := One(ctx, users(), StructMapper[User](), "SELECT id, name FROM users"); !errors.Is(err, ErrNoRows) || !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %v", err)
+	}
+}
+
+func TestCountAndExists(t *testing.T) {
+	ctx := context.Background()
+	single := func(values ...any) Queryer {
+		return funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+			rows := make([][]any, len(values))
+			for i, v := range values {
+				rows[i] = []any{v}
+			}
+			return newSliceRows([]string{"value"}, rows...), nil
+		})
+	}
+
+	n, err := Count(ctx, single(int64(42)), "SELECT COUNT(*) FROM users")
+	if err != nil || n != 42 {
+		t.Fatalf("expected 42, got %d, %v", n, err)
+	}
+
+	if n, err := Count(ctx, single([]byte("7")), ""); err != nil || n != 7 {
+		t.Fatalf("expected 7, got %d, %v", n, err)
+	}
+
+	if n, err := Count(ctx, single(nil), ""); err != nil || n != 0 {
+		t.Fatalf("expected 0 for NULL, got %d, %v", n, err)
+	}
+
+	if _, err := Count(ctx, single(), ""); !errors.Is(err, ErrNoRows) {
+		t.Fatalf("expected ErrNoRows, got %v", err)
+	}
+
+	cases := map[string]struct {
+		q        Queryer
+		expected bool
+	}{
+		"true":    {q: single(true), expected: true},
+		"false":   {q: single(false)},
+		"int":     {q: single(int64(1)), expected: true},
+		"null":    {q: single(nil)},
+		"no rows": {q: single()},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			found, err := Exists(ctx, tc.q, "SELECT EXISTS (SELECT 1 FROM users)")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if found != tc.expected {
+				t.Fatalf("expected %t, got %t", tc.expected, found)
+			}
+		})
+	}
+
+	if _, err := Exists(ctx, single("maybe"), ""); err == nil {
+		t.Fatal("expected an error for a value that is not a boolean")
+	}
+}
@@ -0,0 +1,127 @@
+package scan
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+
+	"github.com/aarondl/opt"
+)
+
+// FacetCounts holds the counts for each value of each facet
+// e.g. {"brand": {"acme": 10, "globex": 3}}
+type FacetCounts = map[string]map[string]int64
+
+// FacetColumns names the columns used to split data rows from facet rows
+// in a query that combines them, usually with UNION ALL.
+//
+//	SELECT NULL AS kind, id, name, NULL AS facet_value, NULL AS facet_count FROM products
+//	UNION ALL
+//	SELECT 'brand', NULL, NULL, brand, COUNT(*) FROM products GROUP BY brand
+type FacetColumns struct {
+	// Kind is the discriminator column.
+	// For facet rows, it holds the name of the facet
+	Kind string
+	// DataKind is the value of the Kind column for data rows.
+	// NULL is treated the same as an empty string
+	DataKind string
+	// Value is the column with the value of the facet
+	Value string
+	// Count is the column with the count for the facet value
+	Count string
+}
+
+// Faceted runs a query that returns both data rows and facet count rows
+// and splits them in a single pass.
+// The mapper is called with only the data columns. Since facet rows have NULLs
+// in the data columns, NULL values are not scanned into the mapped destinations
+func Faceted[T any](ctx context.Context, exec Queryer, m Mapper[T], fc FacetColumns, query string, args ...any) ([]T, FacetCounts, error) {
+	rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	results, counts, err := FacetedFromRows(ctx, m, fc, rows)
+	return results, counts, withQuery(err, query)
+}
+
+// FacetedFromRows splits the data rows and facet count rows of the given [Rows]
+func FacetedFromRows[T any](ctx context.Context, m Mapper[T], fc FacetColumns, rows Rows) ([]T, FacetCounts, error) {
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+	v, err := wrapRows(rows, allowUnknown)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dataCols := make([]string, 0, len(v.columns))
+	for _, c := range v.columns {
+		if c != fc.Kind && c != fc.Value && c != fc.Count {
+			dataCols = append(dataCols, c)
+		}
+	}
+
+	before, after := buildMapper(ctx, m, v, dataCols)
+
+	var results []T
+	counts := make(FacetCounts)
+
+	for rows.Next() {
+		link, err := before(v)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for i, dest := range v.scanDestinations {
+			if dest != zeroValue {
+				v.scanDestinations[i] = reflect.ValueOf(&skipNull{dest: dest.Interface()})
+			}
+		}
+
+		var kind, value sql.NullString
+		var count sql.NullInt64
+		v.ScheduleScan(fc.Kind, &kind)
+		v.ScheduleScan(fc.Value, &value)
+		v.ScheduleScan(fc.Count, &count)
+
+		err = v.scanCurrentRow()
+		traceRow(v, nil, err)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if kind.String == fc.DataKind {
+			one, err := after(link)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			results = append(results, one)
+			continue
+		}
+
+		if counts[kind.String] == nil {
+			counts[kind.String] = make(map[string]int64)
+		}
+		counts[kind.String][value.String] = count.Int64
+	}
+
+	return results, counts, rows.Err()
+}
+
+// skipNull wraps a scan destination and leaves it untouched for NULL values
+type skipNull struct {
+	dest any
+}
+
+func (s *skipNull) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+
+	if scanner, ok := s.dest.(sql.Scanner); ok {
+		return scanner.Scan(value)
+	}
+
+	return opt.ConvertAssign(s.dest, value)
+}
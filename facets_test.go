@@ -0,0 +1,40 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFaceted(t *testing.T) {
+	rows := newSliceRows(
+		[]string{"kind", "id", "name", "facet_value", "facet_count"},
+		[]any{nil, 1, "foo", nil, nil},
+		[]any{nil, 2, "bar", nil, nil},
+		[]any{"brand", nil, nil, "acme", 10},
+		[]any{"brand", nil, nil, "globex", 3},
+		[]any{"year", nil, nil, 2022, 7},
+	)
+
+	users, counts, err := FacetedFromRows(context.Background(), StructMapper[User](), FacetColumns{
+		Kind:  "kind",
+		Value: "facet_value",
+		Count: "facet_count",
+	}, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]User{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	expected := FacetCounts{
+		"brand": {"acme": 10, "globex": 3},
+		"year":  {"2022": 7},
+	}
+	if diff := cmp.Diff(expected, counts); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
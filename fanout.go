@@ -0,0 +1,117 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ShardError is the error of a single [Queryer] in [FanOut]
+type ShardError struct {
+	// Shard is the index of the Queryer in the execs given to FanOut
+	Shard int
+	Err   error
+}
+
+func (e *ShardError) Error() string {
+	return fmt.Sprintf("shard %d: %v", e.Shard, e.Err)
+}
+
+func (e *ShardError) Unwrap() error {
+	return e.Err
+}
+
+// FanOutError is returned by [FanOut] when the query fails on some of the Queryers.
+// It has the error of each of them, in the order of the Queryers.
+// Use [errors.As] with a *[ShardError] to get the first one
+type FanOutError struct {
+	Shards int
+	Errors []*ShardError
+}
+
+func (e *FanOutError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return fmt.Sprintf("query failed on %d of %d shards: %s", len(e.Errors), e.Shards, strings.Join(msgs, "; "))
+}
+
+// Is reports whether the error of any of the shards matches target, see [errors.Is].
+// It does not rely on unwrapping multiple errors, which needs Go 1.20
+func (e *FanOutError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As sets target to the first error of the shards that matches it, see [errors.As]
+func (e *FanOutError) As(target any) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FanOut runs the same query concurrently on each of the Queryers, e.g. the shards
+// or replicas of a database, and returns the rows of all of them mapped to T,
+// in the order of the Queryers.
+//
+// If the query fails on some of them, the rows of the others are returned
+// with a *[FanOutError] that has the error of each failed one.
+// Cancel the context to stop the queries that are still running.
+//
+//	users, err := scan.FanOut(ctx, shards, scan.StructMapper[User](), "SELECT * FROM users WHERE active")
+//	var fe *scan.FanOutError
+//	if errors.As(err, &fe) {
+//	    for _, e := range fe.Errors {
+//	        log.Printf("shard %d: %v", e.Shard, e.Err)
+//	    }
+//	}
+func FanOut[T any](ctx context.Context, execs []Queryer, m Mapper[T], query string, args ...any) ([]T, error) {
+	results := make([][]T, len(execs))
+	errs := make([]error, len(execs))
+
+	var wg sync.WaitGroup
+	for i, exec := range execs {
+		wg.Add(1)
+		go func(i int, exec Queryer) {
+			defer wg.Done()
+			results[i], errs[i] = All(ctx, exec, m, query, args...)
+		}(i, exec)
+	}
+	wg.Wait()
+
+	var n int
+	var failed []*ShardError
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, &ShardError{Shard: i, Err: err})
+			continue
+		}
+		n += len(results[i])
+	}
+
+	merged := make([]T, 0, n)
+	for i, rows := range results {
+		if errs[i] == nil {
+			merged = append(merged, rows...)
+		}
+	}
+
+	if len(failed) > 0 {
+		return merged, &FanOutError{Shards: len(execs), Errors: failed}
+	}
+
+	return merged, nil
+}
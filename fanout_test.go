@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFanOut(t *testing.T) {
+	ctx := context.Background()
+	errShard := errors.New("shard down")
+
+	shard := func(ids ...any) Queryer {
+		return funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+			if ids == nil {
+				return nil, errShard
+			}
+			return usersByID(ctx, query, ids...)
+		})
+	}
+
+	users, err := FanOut(ctx, []Queryer{shard(1, 2), shard(3), shard(4, 5)}, StructMapper[User](), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 3, 4, 5}, ids(users)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The rows of the other shards are returned with the errors
+	users, err = FanOut(ctx, []Queryer{shard(1), shard(), shard(2), shard()}, StructMapper[User](), "")
+	if diff := cmp.Diff([]int{1, 2}, ids(users)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	var fe *FanOutError
+	if !errors.As(err, &fe) {
+		t.Fatalf("expected a FanOutError, got %v", err)
+	}
+
+	if fe.Shards != 4 || len(fe.Errors) != 2 || fe.Errors[0].Shard != 1 || fe.Errors[1].Shard != 3 {
+		t.Fatalf("unexpected shard errors: %v", err)
+	}
+
+	if !errors.Is(err, errShard) {
+		t.Fatalf("expected the shard error, got %v", err)
+	}
+
+	var se *ShardError
+	if !errors.As(err, &se) || se.Shard != 1 {
+		t.Fatalf("expected the error of the first failed shard, got %v", se)
+	}
+
+	if err.Error() != "query failed on 2 of 4 shards: shard 1: shard down; shard 3: shard down" {
+		t.Fatalf("unexpected message: %v", err)
+	}
+
+	if users, err := FanOut(ctx, nil, StructMapper[User](), ""); err != nil || len(users) != 0 {
+		t.Fatalf("expected no rows, got %v, %v", users, err)
+	}
+}
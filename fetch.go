@@ -0,0 +1,177 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// FetchSizer is implemented by a [Queryer] whose driver can set the number of rows
+// fetched from the database in each round trip, such as the wrapper in the chscan package
+type FetchSizer interface {
+	// WithFetchSize returns a [Queryer] that fetches size rows in each round trip
+	WithFetchSize(size int) Queryer
+}
+
+// FetchSize returns a [Queryer] that fetches size rows in each round trip.
+// If q does not implement [FetchSizer], it is returned as it is
+func FetchSize(q Queryer, size int) Queryer {
+	if fs, ok := q.(FetchSizer); ok && size > 0 {
+		return fs.WithFetchSize(size)
+	}
+
+	return q
+}
+
+// AdaptiveFetchOption configures an [AdaptiveFetchSize] queryer
+type AdaptiveFetchOption func(*adaptiveFetch)
+
+// WithFetchTarget sets the number of bytes to fetch in each round trip. The default is 1MiB
+func WithFetchTarget(bytes int) AdaptiveFetchOption {
+	return func(a *adaptiveFetch) {
+		a.target = bytes
+	}
+}
+
+// WithFetchLimits sets the smallest and largest fetch size,
+// and the size used for a query that has not been run yet.
+// The defaults are 100, 100000 and 1000
+func WithFetchLimits(min, max, initial int) AdaptiveFetchOption {
+	return func(a *adaptiveFetch) {
+		a.min, a.max, a.initial = min, max, initial
+	}
+}
+
+// AdaptiveFetchSize returns a [Queryer] that tunes the fetch size of each query
+// based on the width of its rows seen in previous runs, so that every round trip
+// fetches about the same number of bytes. This reduces round trips for narrow rows
+// and memory spikes for wide ones.
+//
+// The width of a row is estimated from the values scanned into the destinations.
+// Queries are told apart by their [Fingerprint].
+// If q does not implement [FetchSizer], it is returned as it is
+func AdaptiveFetchSize(q Queryer, opts ...AdaptiveFetchOption) Queryer {
+	fs, ok := q.(FetchSizer)
+	if !ok {
+		return q
+	}
+
+	a := &adaptiveFetch{
+		q:       fs,
+		target:  1 << 20,
+		min:     100,
+		max:     100000,
+		initial: 1000,
+		widths:  make(map[string]int),
+	}
+	for _, o := range opts {
+		o(a)
+	}
+
+	return a
+}
+
+type adaptiveFetch struct {
+	q       FetchSizer
+	target  int
+	min     int
+	max     int
+	initial int
+
+	mu     sync.Mutex
+	widths map[string]int
+}
+
+func (a *adaptiveFetch) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	key := Fingerprint(query)
+
+	rows, err := a.q.WithFetchSize(a.size(key)).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	measured := &measuredRows{Rows: rows, done: func(width int) { a.observe(key, width) }}
+	if multi, ok := rows.(MultiRows); ok {
+		return measuredMultiRows{measuredRows: measured, multi: multi}, nil
+	}
+
+	return measured, nil
+}
+
+// size returns the fetch size for the query with the key
+func (a *adaptiveFetch) size(key string) int {
+	a.mu.Lock()
+	width, ok := a.widths[key]
+	a.mu.Unlock()
+
+	if !ok || width <= 0 {
+		return a.initial
+	}
+
+	size := a.target / width
+	if size < a.min {
+		size = a.min
+	}
+	if size > a.max {
+		size = a.max
+	}
+
+	return size
+}
+
+// observe records the average width of the rows of the query with the key
+func (a *adaptiveFetch) observe(key string, width int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Smooth the width so that a single unusual run does not swing the size
+	if prev, ok := a.widths[key]; ok {
+		width = (prev + width) / 2
+	}
+
+	a.widths[key] = width
+}
+
+// measuredRows measures the average width of the scanned rows
+// and reports it when the rows are closed
+type measuredRows struct {
+	Rows
+	done func(width int)
+
+	rows   int
+	bytes  int
+	closed bool
+}
+
+func (r *measuredRows) Scan(dest ...any) error {
+	if err := r.Rows.Scan(dest...); err != nil {
+		return err
+	}
+
+	r.rows++
+	for _, d := range dest {
+		r.bytes += int(indirectSize(reflect.ValueOf(d), 0))
+	}
+
+	return nil
+}
+
+func (r *measuredRows) Close() error {
+	err := r.Rows.Close()
+	if !r.closed && r.rows > 0 {
+		r.done(r.bytes / r.rows)
+	}
+	r.closed = true
+
+	return err
+}
+
+// measuredMultiRows keeps support for multiple result sets
+type measuredMultiRows struct {
+	*measuredRows
+	multi MultiRows
+}
+
+func (r measuredMultiRows) NextResultSet() bool {
+	return r.multi.NextResultSet()
+}
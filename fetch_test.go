@@ -0,0 +1,80 @@
+package scan
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// fetchSizer records the fetch size of each query
+type fetchSizer struct {
+	funcQ
+	sizes []int
+}
+
+func (f *fetchSizer) WithFetchSize(size int) Queryer {
+	f.sizes = append(f.sizes, size)
+	return f.funcQ
+}
+
+func TestFetchSize(t *testing.T) {
+	ctx := context.Background()
+	exec := &fetchSizer{funcQ: usersByID}
+
+	if _, err := All(ctx, FetchSize(exec, 500), StructMapper[User](), "SELECT", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(exec.sizes) != 1 || exec.sizes[0] != 500 {
+		t.Fatalf("expected a fetch size of 500, got %v", exec.sizes)
+	}
+
+	// Queryers that cannot set the fetch size are returned as they are
+	if q := FetchSize(funcQ(usersByID), 500); q == nil {
+		t.Fatal("expected the queryer")
+	}
+}
+
+func TestAdaptiveFetchSize(t *testing.T) {
+	ctx := context.Background()
+
+	name := "narrow"
+	exec := &fetchSizer{funcQ: func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "name"}, []any{1, name}, []any{2, name}), nil
+	}}
+
+	q := AdaptiveFetchSize(exec, WithFetchTarget(64*1024), WithFetchLimits(10, 1000, 50))
+
+	run := func() {
+		t.Helper()
+		if _, err := All(ctx, q, StructMapper[User](), "SELECT id, name FROM users"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The first run uses the initial size
+	run()
+	if exec.sizes[0] != 50 {
+		t.Fatalf("expected the initial size, got %d", exec.sizes[0])
+	}
+
+	// Narrow rows fetch up to the largest size
+	run()
+	if exec.sizes[1] != 1000 {
+		t.Fatalf("expected the largest size for narrow rows, got %d", exec.sizes[1])
+	}
+
+	// Wide rows fetch fewer rows in each round trip
+	name = strings.Repeat("x", 100*1024)
+	run()
+	run()
+	if exec.sizes[3] >= 1000 {
+		t.Fatalf("expected a smaller size for wide rows, got %d", exec.sizes[3])
+	}
+
+	run()
+	run()
+	if exec.sizes[5] != 10 {
+		t.Fatalf("expected the smallest size for very wide rows, got %d", exec.sizes[5])
+	}
+}
@@ -0,0 +1,108 @@
+package scan
+
+import "context"
+
+// ctxKeyRowFilter holds the row filter for a single query
+var ctxKeyRowFilter contextKey = "row filter"
+
+// WithRowFilter returns a context that skips the rows of the queries run with it
+// for which keep returns false, before they are mapped.
+// See [FilterRows] for how the rows are read
+//
+//	ctx = scan.WithRowFilter(ctx, func(v *scan.Values) bool {
+//	    return scan.Value[string](v, "status") != "deleted"
+//	})
+func WithRowFilter(ctx context.Context, keep func(*Values) bool) context.Context {
+	return context.WithValue(ctx, ctxKeyRowFilter, keep)
+}
+
+// FilterRows returns [Rows] that skip the rows for which keep returns false,
+// so that they are never mapped. This is cheaper than filtering the mapped values
+// when a simple check on the columns discards most rows.
+//
+// The values of each row are read with [Values], and then converted into the
+// scan destinations of the rows that are kept in the same way as [Value].
+// An error recorded while reading the values in keep stops the rows
+func FilterRows(rows Rows, keep func(*Values) bool) Rows {
+	filtered := &filteredRows{Rows: rows, keep: keep}
+	if multi, ok := rows.(MultiRows); ok {
+		return filteredMultiRows{filteredRows: filtered, multi: multi}
+	}
+
+	return filtered
+}
+
+// filterRows applies the row filter in the context to the rows, if any
+func filterRows(ctx context.Context, rows Rows) Rows {
+	keep, _ := ctx.Value(ctxKeyRowFilter).(func(*Values) bool)
+	if keep == nil {
+		return rows
+	}
+
+	return FilterRows(rows, keep)
+}
+
+type filteredRows struct {
+	Rows
+	keep   func(*Values) bool
+	values *Values
+	err    error
+}
+
+func (r *filteredRows) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	if r.values == nil {
+		columns, err := r.Rows.Columns()
+		if err != nil {
+			r.err = err
+			return false
+		}
+		r.values = newValues(columns)
+	}
+
+	for r.Rows.Next() {
+		if err := r.values.scan(r.Rows); err != nil {
+			r.err = err
+			return false
+		}
+
+		keep := r.keep(r.values)
+		if r.values.err != nil {
+			r.err = r.values.err
+			return false
+		}
+
+		if keep {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *filteredRows) Scan(dest ...any) error {
+	return r.values.assign(dest)
+}
+
+func (r *filteredRows) Err() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	return r.Rows.Err()
+}
+
+// filteredMultiRows keeps support for multiple result sets
+type filteredMultiRows struct {
+	*filteredRows
+	multi MultiRows
+}
+
+func (r filteredMultiRows) NextResultSet() bool {
+	// The next result set may have different columns
+	r.values = nil
+	return r.multi.NextResultSet()
+}
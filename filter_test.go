@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRowFilter(t *testing.T) {
+	type aged struct {
+		ID   int
+		Name string
+		Age  int
+	}
+
+	var mapped int
+	m := func(ctx context.Context, c cols) (BeforeFunc, func(any) (aged, error)) {
+		before, after := StructMapper[aged]()(ctx, c)
+		return func(r *Row) (any, error) {
+			mapped++
+			return before(r)
+		}, after
+	}
+
+	ctx := WithRowFilter(context.Background(), func(v *Values) bool {
+		return !v.IsNull("age")
+	})
+
+	users, err := All(ctx, funcQ(usersWithAge), m, "SELECT id, name, age FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []aged{{ID: 1, Name: "foo", Age: 30}, {ID: 3, Name: "baz", Age: 41}}
+	if diff := cmp.Diff(expected, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if mapped != 2 {
+		t.Fatalf("expected only the kept rows to be mapped, got %d", mapped)
+	}
+}
+
+func TestFilterRows(t *testing.T) {
+	ctx := context.Background()
+
+	rows, _ := usersWithAge(ctx, "")
+	rows = FilterRows(rows, func(v *Values) bool {
+		return Value[int](v, "age") > 35
+	})
+
+	ages, err := AllFromRows(ctx, ColumnMapper[int64]("age"), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int64{41}, ages); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows, _ = usersWithAge(ctx, "")
+	rows = FilterRows(rows, func(v *Values) bool {
+		return Value[int](v, "missing") > 0
+	})
+
+	if _, err := AllFromRows(ctx, ColumnMapper[int64]("age"), rows); !errors.Is(err, ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn, got %v", err)
+	}
+}
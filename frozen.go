@@ -0,0 +1,124 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+)
+
+// Frozen is a read-only view of a scanned value, for results that are cached
+// or shared across goroutines. [Frozen.Get] returns a deep copy of the value,
+// so changes made by one caller are never seen by another.
+//
+// Exported fields are copied deeply, while unexported fields are copied as they are,
+// so types such as [time.Time] keep their internal pointers
+type Frozen[T any] struct {
+	value T
+}
+
+// Freeze returns a [Frozen] view of a deep copy of v
+func Freeze[T any](v T) Frozen[T] {
+	return Frozen[T]{value: deepCopy(v)}
+}
+
+// Get returns a deep copy of the value
+func (f Frozen[T]) Get() T {
+	return deepCopy(f.value)
+}
+
+// FrozenMapper returns a mapper that returns the values mapped by m as [Frozen] views.
+//
+//	users, err := scan.All(ctx, db, scan.FrozenMapper(scan.StructMapper[*User]()), query)
+//	cache.Set("users", users) // safe to share
+func FrozenMapper[T any](m Mapper[T]) Mapper[Frozen[T]] {
+	return func(ctx context.Context, c cols) (BeforeFunc, func(any) (Frozen[T], error)) {
+		before, after := m(ctx, c)
+
+		return before, func(link any) (Frozen[T], error) {
+			v, err := after(link)
+			if err != nil {
+				return Frozen[T]{}, err
+			}
+
+			// The value was just scanned, so there are no other references to copy from
+			return Frozen[T]{value: v}, nil
+		}
+	}
+}
+
+func deepCopy[T any](v T) T {
+	val := reflect.ValueOf(&v).Elem()
+	copied := copyValue(val, make(map[uintptr]reflect.Value))
+	return copied.Interface().(T)
+}
+
+// copyValue returns a deep copy of v. Pointers that were already copied
+// are reused so that shared and cyclic values keep their shape
+func copyValue(v reflect.Value, seen map[uintptr]reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		if p, ok := seen[v.Pointer()]; ok && p.Type() == v.Type() {
+			return p
+		}
+
+		p := reflect.New(v.Type().Elem())
+		seen[v.Pointer()] = p
+		p.Elem().Set(copyValue(v.Elem(), seen))
+		return p
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.New(v.Type()).Elem()
+		c.Set(copyValue(v.Elem(), seen))
+		return c
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), seen))
+		}
+		return c
+
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(copyValue(v.Index(i), seen))
+		}
+		return c
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(copyValue(iter.Key(), seen), copyValue(iter.Value(), seen))
+		}
+		return c
+
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			c.Field(i).Set(copyValue(v.Field(i), seen))
+		}
+		return c
+
+	default:
+		return v
+	}
+}
@@ -0,0 +1,76 @@
+package scan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Catalog struct {
+	Name     string
+	Tags     []string
+	Prices   map[string]int
+	Owner    *User
+	Created  time.Time
+	Previous *Catalog
+}
+
+func TestFrozen(t *testing.T) {
+	now := time.Now()
+	owner := &User{ID: 1, Name: "foo"}
+	original := Catalog{
+		Name:    "books",
+		Tags:    []string{"a", "b"},
+		Prices:  map[string]int{"x": 1},
+		Owner:   owner,
+		Created: now,
+	}
+	original.Previous = &Catalog{Name: "old", Owner: owner}
+
+	frozen := Freeze(original)
+
+	// Changing the original does not change the frozen value
+	original.Tags[0] = "changed"
+	original.Prices["x"] = 100
+	original.Owner.Name = "changed"
+
+	got := frozen.Get()
+	if got.Tags[0] != "a" || got.Prices["x"] != 1 || got.Owner.Name != "foo" || !got.Created.Equal(now) {
+		t.Fatalf("the frozen value was changed: %+v", got)
+	}
+
+	// Pointers that were shared are still shared in the copy
+	if got.Owner != got.Previous.Owner {
+		t.Fatal("expected the shared pointer to be copied once")
+	}
+
+	// Changing a copy does not change the frozen value
+	got.Tags[1] = "changed"
+	got.Owner.ID = 100
+	if again := frozen.Get(); again.Tags[1] != "b" || again.Owner.ID != 1 {
+		t.Fatalf("the frozen value was changed: %+v", again)
+	}
+
+	// Cycles are copied
+	cyclic := &Catalog{Name: "cyclic"}
+	cyclic.Previous = cyclic
+	if c := Freeze(cyclic).Get(); c.Previous != c || c == cyclic {
+		t.Fatal("expected the cycle to be copied")
+	}
+}
+
+func TestFrozenMapper(t *testing.T) {
+	rows := newSliceRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"})
+
+	users, err := AllFromRows(context.Background(), FrozenMapper(StructMapper[*User]()), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	users[0].Get().Name = "changed"
+	if diff := cmp.Diff(&User{ID: 1, Name: "foo"}, users[0].Get()); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
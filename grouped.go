@@ -0,0 +1,92 @@
+package scan
+
+import (
+	"context"
+)
+
+// Group describes how joined rows are folded into parents with their children.
+// Every row is mapped into both a parent and a child. Rows that have the same
+// parent key are collapsed into a single parent, and each child is attached to it.
+//
+// The parent and child mappers are applied to the same row, so they should
+// map distinct columns. This is usually done with [WithStructTagPrefix]
+type Group[P any, C any, K comparable] struct {
+	// Parent maps the parent from each row
+	Parent Mapper[P]
+	// Child maps the child from each row
+	Child Mapper[C]
+	// Key returns the value that identifies a parent
+	Key func(P) K
+	// Attach adds the child to the parent and returns the modified parent.
+	// With a LEFT JOIN, the child may be the zero value, for example if it is
+	// rejected by a [RowValidator]. It is up to Attach to skip it
+	Attach func(P, C) P
+}
+
+// Grouped runs the query and folds the rows into parents using [Group].
+// The parents are returned in the order they are first seen
+func Grouped[P any, C any, K comparable](ctx context.Context, exec Queryer, g Group[P, C, K], query string, args ...any) ([]P, error) {
+	rows, err := queryContext(ctx, exec, typeOf[P](), query, args)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	parents, err := GroupedFromRows(ctx, g, rows)
+	return parents, withQuery(err, query)
+}
+
+// GroupedFromRows folds the given [Rows] into parents using [Group].
+func GroupedFromRows[P any, C any, K comparable](ctx context.Context, g Group[P, C, K], rows Rows) ([]P, error) {
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+	v, err := wrapRows(rows, allowUnknown)
+	if err != nil {
+		return nil, err
+	}
+
+	parentBefore, parentAfter := buildMapper(ctx, g.Parent, v, v.columnsCopy())
+	childBefore, childAfter := buildMapper(ctx, g.Child, v, v.columnsCopy())
+
+	var parents []P
+	index := make(map[K]int)
+
+	for rows.Next() {
+		parentLink, err := parentBefore(v)
+		if err != nil {
+			return nil, err
+		}
+
+		childLink, err := childBefore(v)
+		if err != nil {
+			return nil, err
+		}
+
+		err = v.scanCurrentRow()
+		traceRow(v, nil, err)
+		if err != nil {
+			return nil, err
+		}
+
+		parent, err := parentAfter(parentLink)
+		if err != nil {
+			return nil, err
+		}
+
+		child, err := childAfter(childLink)
+		if err != nil {
+			return nil, err
+		}
+
+		key := g.Key(parent)
+		i, ok := index[key]
+		if !ok {
+			i = len(parents)
+			index[key] = i
+			parents = append(parents, parent)
+		}
+
+		parents[i] = g.Attach(parents[i], child)
+	}
+
+	return parents, rows.Err()
+}
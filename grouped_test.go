@@ -0,0 +1,64 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Order struct {
+	ID     int
+	Amount int
+}
+
+// PtrOrder is used to scan orders from a LEFT JOIN
+type PtrOrder struct {
+	ID     *int
+	Amount *int
+}
+
+type UserWithOrders struct {
+	ID     int
+	Name   string
+	Orders []Order
+}
+
+func TestGrouped(t *testing.T) {
+	ctx := context.Background()
+	rows := newSliceRows(
+		[]string{"id", "name", "order.id", "order.amount"},
+		[]any{1, "foo", 10, 100},
+		[]any{2, "bar", 20, 200},
+		[]any{1, "foo", 11, 110},
+		[]any{3, "baz", nil, nil},
+		[]any{2, "bar", 21, 210},
+	)
+
+	g := Group[UserWithOrders, PtrOrder, int]{
+		Parent: StructMapper[UserWithOrders](),
+		Child:  StructMapper[PtrOrder](WithStructTagPrefix("order.")),
+		Key:    func(u UserWithOrders) int { return u.ID },
+		Attach: func(u UserWithOrders, o PtrOrder) UserWithOrders {
+			if o.ID != nil {
+				u.Orders = append(u.Orders, Order{ID: *o.ID, Amount: *o.Amount})
+			}
+			return u
+		},
+	}
+
+	users, err := GroupedFromRows(ctx, g, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []UserWithOrders{
+		{ID: 1, Name: "foo", Orders: []Order{{ID: 10, Amount: 100}, {ID: 11, Amount: 110}}},
+		{ID: 2, Name: "bar", Orders: []Order{{ID: 20, Amount: 200}, {ID: 21, Amount: 210}}},
+		{ID: 3, Name: "baz"},
+	}
+
+	if diff := cmp.Diff(expected, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
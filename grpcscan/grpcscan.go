@@ -0,0 +1,59 @@
+// Package grpcscan sends the results of queries over gRPC server streams
+package grpcscan
+
+import (
+	"context"
+
+	"github.com/stephenafamo/scan"
+)
+
+// Pump sends every row from the cursor with the send function of a gRPC
+// server stream, converting each row to a message with transform.
+//
+//	err := grpcscan.Pump(stream.Context(), cursor, stream.Send, toProto)
+//
+// Rows are only fetched after the previous message has been sent, so a slow
+// client applies backpressure on the query through gRPC flow control.
+// It stops with the context error if the context is done.
+// The cursor is not closed
+func Pump[T any, M any](ctx context.Context, c scan.ICursor[T], send func(M) error, transform func(T) (M, error)) error {
+	for c.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := c.Get()
+		if err != nil {
+			return err
+		}
+
+		msg, err := transform(row)
+		if err != nil {
+			return err
+		}
+
+		if err := send(msg); err != nil {
+			return err
+		}
+	}
+
+	return c.Err()
+}
+
+// PumpMessages is like [Pump] for cursors that already map rows into
+// the message type, e.g. with scan.StructMapper[*pb.User]()
+func PumpMessages[M any](ctx context.Context, c scan.ICursor[M], send func(M) error) error {
+	return Pump(ctx, c, send, func(m M) (M, error) { return m, nil })
+}
+
+// Stream runs the query and sends every row with the send function
+// of a gRPC server stream. See [Pump]
+func Stream[T any, M any](ctx context.Context, exec scan.Queryer, m scan.Mapper[T], send func(M) error, transform func(T) (M, error), query string, args ...any) error {
+	c, err := scan.Cursor(ctx, exec, m, query, args...)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	return Pump(ctx, c, send, transform)
+}
@@ -0,0 +1,82 @@
+package grpcscan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stephenafamo/scan"
+)
+
+type rows struct {
+	ids   []int
+	index int
+}
+
+func (r *rows) Scan(dest ...any) error {
+	*(dest[0].(*int)) = r.ids[r.index-1]
+	return nil
+}
+
+func (r *rows) Columns() ([]string, error) { return []string{"id"}, nil }
+func (r *rows) Next() bool                 { r.index++; return r.index <= len(r.ids) }
+func (r *rows) Close() error               { return nil }
+func (r *rows) Err() error                 { return nil }
+
+type message struct {
+	ID int
+}
+
+type stream struct {
+	ctx    context.Context
+	cancel func()
+	sent   []*message
+}
+
+func (s *stream) Send(m *message) error {
+	s.sent = append(s.sent, m)
+	if len(s.sent) == 2 && s.cancel != nil {
+		s.cancel()
+	}
+	return nil
+}
+
+func TestPump(t *testing.T) {
+	ctx := context.Background()
+	c, err := scan.CursorFromRows(ctx, scan.SingleColumnMapper[int], &rows{ids: []int{1, 2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &stream{ctx: ctx}
+	err = Pump(s.ctx, c, s.Send, func(id int) (*message, error) {
+		return &message{ID: id}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(s.sent) != 3 || s.sent[2].ID != 3 {
+		t.Fatalf("wrong messages sent: %v", s.sent)
+	}
+}
+
+func TestPumpCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c, err := scan.CursorFromRows(ctx, scan.StructMapper[*message](), &rows{ids: []int{1, 2, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &stream{ctx: ctx, cancel: cancel}
+	err = PumpMessages(s.ctx, c, s.Send)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	if len(s.sent) != 2 {
+		t.Fatalf("expected 2 messages before cancellation, got %d", len(s.sent))
+	}
+}
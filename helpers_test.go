@@ -40,6 +40,62 @@ func (s stdQ) QueryContext(ctx context.Context, query string, args ...any) (Rows
 	return s.DB.QueryContext(ctx, query, args...)
 }
 
+// funcQ is a Queryer that calls the function with the query and args
+type funcQ func(ctx context.Context, query string, args ...any) (Rows, error)
+
+func (f funcQ) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return f(ctx, query, args...)
+}
+
+// sliceRows is an in-memory implementation of Rows
+type sliceRows struct {
+	cols   []string
+	rows   rows
+	index  int
+	closed bool
+}
+
+func newSliceRows(cols []string, r ...[]any) *sliceRows {
+	return &sliceRows{cols: cols, rows: r}
+}
+
+func (s *sliceRows) Scan(dest ...any) error {
+	if len(dest) != len(s.cols) {
+		return fmt.Errorf("expected %d destinations, got %d", len(s.cols), len(dest))
+	}
+
+	for i, d := range dest {
+		if err := opt.ConvertAssign(d, s.rows[s.index-1][i]); err != nil {
+			// The same format as database/sql
+			return fmt.Errorf("sql: Scan error on column index %d, name %q: %w", i, s.cols[i], err)
+		}
+	}
+
+	return nil
+}
+
+func (s *sliceRows) Columns() ([]string, error) {
+	return s.cols, nil
+}
+
+func (s *sliceRows) Next() bool {
+	if s.closed || s.index >= len(s.rows) {
+		return false
+	}
+
+	s.index++
+	return true
+}
+
+func (s *sliceRows) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *sliceRows) Err() error {
+	return nil
+}
+
 type Timestamps struct {
 	CreatedAt time.Time
 	UpdatedAt time.Time
@@ -85,6 +141,16 @@ type Tagged struct {
 	Exclude int    `db:"-" custom:"-"`
 }
 
+type Address struct {
+	Street string
+	City   string
+}
+
+type UserWithAddress struct {
+	User
+	*Address `db:"addr,prefix"`
+}
+
 type ScannableUser struct {
 	ID   int
 	Name string
@@ -231,3 +297,31 @@ func compareErrors(xe, ye error) bool {
 
 	return xe.Error() == ye.Error()
 }
+
+// MappableUser maps "identifier" to ID to check that
+// its MapValues method is used instead of reflection
+type MappableUser struct {
+	ID   int
+	Name string
+}
+
+func (u *MappableUser) MapValues(ctx context.Context, key string) any {
+	switch key {
+	case "identifier":
+		return &u.ID
+	case "name":
+		return &u.Name
+	}
+
+	return nil
+}
+
+// Account keeps its storage fields unexported
+type Account struct {
+	ID        int
+	balance   int
+	createdBy string `db:"creator"`
+}
+
+func (a Account) Balance() int      { return a.balance }
+func (a Account) CreatedBy() string { return a.createdBy }
@@ -0,0 +1,172 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Event is an event of the lifecycle of a query, sent to the [Hook]s of the [Queryer].
+// It is one of [QueryStart], [QueryEnd], [MappingBuilt], [RowMapped] or [ScanEnd]
+type Event interface {
+	event()
+}
+
+// QueryStart is sent before the query is sent to the [Queryer]
+type QueryStart struct {
+	Query string
+	Args  []any
+	// Type is the type the rows are mapped to, if known
+	Type reflect.Type
+}
+
+// QueryEnd is sent when the [Queryer] returns, before the rows are read
+type QueryEnd struct {
+	Query    string
+	Duration time.Duration
+	Err      error
+}
+
+// MappingBuilt is sent when the mapper is built for the columns of the query.
+// A prepared query sends it only when its mapping is not cached
+type MappingBuilt struct {
+	Query   string
+	Type    reflect.Type
+	Columns []string
+	// Duration is the time it took to build the mapper
+	Duration time.Duration
+}
+
+// RowMapped is sent after each row is scanned and mapped
+type RowMapped struct {
+	Query string
+	// Row is the index of the row, starting at 0
+	Row int
+	// Value is the mapped row, if it was mapped to a single value without an error
+	Value any
+	Err   error
+}
+
+// ScanEnd is sent once the rows are closed
+type ScanEnd struct {
+	Query string
+	// Rows is the number of rows that were mapped without an error
+	Rows int
+	// Duration is the time from sending the query to closing the rows
+	Duration time.Duration
+	// Err is the first error of the query, if any
+	Err error
+}
+
+func (QueryStart) event()   {}
+func (QueryEnd) event()     {}
+func (MappingBuilt) event() {}
+func (RowMapped) event()    {}
+func (ScanEnd) event()      {}
+
+// Hook receives the events of the queries run with a [Queryer] from [WithHooks],
+// so that tracing, metrics, debugging and auditing can be built in one place.
+//
+// The context returned by Handle is passed with the next events of the query,
+// and the one returned for [QueryStart] is used to run the query,
+// so it can hold e.g. an OpenTelemetry span.
+// Handle is called by the goroutine reading the rows
+type Hook interface {
+	Handle(ctx context.Context, e Event) context.Context
+}
+
+// HookFunc is a function that implements [Hook]
+type HookFunc func(ctx context.Context, e Event) context.Context
+
+// Handle calls the function
+func (f HookFunc) Handle(ctx context.Context, e Event) context.Context {
+	return f(ctx, e)
+}
+
+// WithHooks returns a [Queryer] that sends the events of the queries run with it
+// by [One], [All], [Cursor] and the other query functions to the hooks, in order.
+// If q already has hooks, they are called after these ones.
+// If q can prepare statements, the returned Queryer can too.
+//
+// The hooks are found on the Queryer passed to the query functions,
+// so wrappers that do not keep the hooks of the Queryer they wrap must be applied before WithHooks
+//
+//	exec := scan.WithHooks(stdscan.Wrap(db), scan.HookFunc(func(ctx context.Context, e scan.Event) context.Context {
+//	    if end, ok := e.(scan.ScanEnd); ok {
+//	        log.Printf("%s: %d rows in %s", end.Query, end.Rows, end.Duration)
+//	    }
+//	    return ctx
+//	}))
+func WithHooks(q Queryer, hooks ...Hook) Queryer {
+	h := hookedQueryer{q: q, hooks: append(hooks[:len(hooks):len(hooks)], hooksOf(q)...)}
+	if p, ok := q.(Preparer); ok {
+		return hookedPreparer{hookedQueryer: h, p: p}
+	}
+
+	return h
+}
+
+// hookSource is implemented by the queryers that have hooks
+type hookSource interface {
+	scanHooks() []Hook
+}
+
+// hooksOf returns the hooks of the queryer, if any
+func hooksOf(q Queryer) []Hook {
+	if s, ok := q.(hookSource); ok {
+		return s.scanHooks()
+	}
+
+	return nil
+}
+
+type hookedQueryer struct {
+	q     Queryer
+	hooks []Hook
+}
+
+func (h hookedQueryer) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return h.q.QueryContext(ctx, query, args...)
+}
+
+func (h hookedQueryer) scanHooks() []Hook {
+	return h.hooks
+}
+
+type hookedPreparer struct {
+	hookedQueryer
+	p Preparer
+}
+
+func (h hookedPreparer) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	return h.p.PrepareContext(ctx, query)
+}
+
+// sendEvent sends the event to the hooks and returns the context for the next events
+func sendEvent(ctx context.Context, hooks []Hook, e Event) context.Context {
+	for _, h := range hooks {
+		ctx = h.Handle(ctx, e)
+	}
+
+	return ctx
+}
+
+// mappingObserver is implemented by rows that send an event when a mapper is built
+type mappingObserver interface {
+	mappingBuilt(typ reflect.Type, c cols, d time.Duration)
+}
+
+// buildMapper builds the mapper for the columns of the rows,
+// and sends the [MappingBuilt] event to the hooks, if any
+func buildMapper[T any](ctx context.Context, m Mapper[T], v *Row, c cols) (func(*Row) (any, error), func(any) (T, error)) {
+	o, ok := v.r.(mappingObserver)
+	if !ok {
+		return m(ctx, c)
+	}
+
+	start := time.Now()
+	before, after := m(ctx, c)
+	o.mappingBuilt(typeOf[T](), c, time.Since(start))
+
+	return before, after
+}
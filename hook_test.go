@@ -0,0 +1,162 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// recordingHook records the events it receives
+type recordingHook struct {
+	name   string
+	events []string
+}
+
+func (r *recordingHook) Handle(ctx context.Context, e Event) context.Context {
+	var event string
+	switch e := e.(type) {
+	case QueryStart:
+		event = fmt.Sprintf("start %s %v %v", e.Query, e.Args, e.Type)
+		ctx = context.WithValue(ctx, traceKey{}, r.name)
+	case QueryEnd:
+		event = fmt.Sprintf("end %s %v", e.Query, e.Err != nil)
+	case MappingBuilt:
+		event = fmt.Sprintf("mapping %v %v", e.Type, e.Columns)
+	case RowMapped:
+		event = fmt.Sprintf("row %d %v %v", e.Row, e.Value, e.Err != nil)
+	case ScanEnd:
+		event = fmt.Sprintf("scan end %s %d %v", e.Query, e.Rows, e.Err != nil)
+	}
+
+	r.events = append(r.events, fmt.Sprintf("%s %s", event, ctx.Value(traceKey{})))
+	return ctx
+}
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+
+	var queryCtx context.Context
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		queryCtx = ctx
+		if query == "fail" {
+			return nil, errors.New("failed")
+		}
+		return usersByID(ctx, query, args...)
+	})
+
+	hook := &recordingHook{name: "span"}
+	hooked := WithHooks(exec, hook)
+
+	if _, err := All(ctx, hooked, StructMapper[User](), "all", 1, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if queryCtx.Value(traceKey{}) != "span" {
+		t.Fatal("expected the query to run with the context from QueryStart")
+	}
+
+	if _, err := One(ctx, hooked, StructMapper[User](), "fail"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	expected := []string{
+		"start all [1 2] scan.User span",
+		"end all false span",
+		"mapping scan.User [id name] span",
+		"row 0 {1 user} false span",
+		"row 1 {2 user} false span",
+		"scan end all 2 false span",
+		"start fail [] scan.User span",
+		"end fail true span",
+		"scan end fail 0 true span",
+	}
+	if diff := cmp.Diff(expected, hook.events); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Hooks of the registry run before the hooks of the wrapped queryer
+	reg := NewQueryRegistry()
+	reg.MustRegister("one", "one")
+	audit := &recordingHook{name: "audit"}
+	reg.Use(audit)
+
+	hook.events = nil
+	if _, err := One(ctx, Allowlist(hooked, reg), StructMapper[User](), "one", 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []string{
+		"start one [3] scan.User span",
+		"end one false span",
+		"mapping scan.User [id name] span",
+		"row 0 {3 user} false span",
+		"scan end one 1 false span",
+	}
+	if diff := cmp.Diff(expected, hook.events); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+	if len(audit.events) != 5 || audit.events[0] != "start one [3] scan.User audit" {
+		t.Fatalf("expected 5 events for the registry hook, got %v", audit.events)
+	}
+
+	// Prepared queries keep the hooks, and build the mapping once
+	hook.events = nil
+	q, err := Prepare(ctx, WithHooks(&fakePreparer{funcQ: usersByID}, hook), StructMapper[User](), "prepared")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := q.All(ctx, 4); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var mappings int
+	for _, e := range hook.events {
+		if e[:7] == "mapping" {
+			mappings++
+		}
+	}
+	if len(hook.events) != 9 || mappings != 1 {
+		t.Fatalf("expected 9 events with 1 mapping, got %v", hook.events)
+	}
+}
+
+func TestContextHooks(t *testing.T) {
+	tracer := &recordingTracer{}
+	stats := NewQueryStats()
+	ctx := WithQueryStats(WithTracer(context.Background(), tracer), stats)
+
+	hook := &recordingHook{name: "hook"}
+	exec := WithHooks(funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "name"}, []any{1, "foo"}), nil
+	}), hook)
+
+	if _, err := All(ctx, exec, StructMapper[User](), "all"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The tracer and the hooks of the queryer share the context of the events
+	expected := []string{
+		"before all []",
+		"row 0 false hook",
+		"after all 1 false hook",
+	}
+	if diff := cmp.Diff(expected, tracer.events); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if len(hook.events) != 5 {
+		t.Fatalf("expected 5 events for the hook, got %v", hook.events)
+	}
+
+	snapshot := stats.Snapshot()
+	if len(snapshot) != 1 || snapshot[0].Queries != 1 || snapshot[0].Rows.Sum != 1 {
+		t.Fatalf("unexpected stats: %+v", snapshot)
+	}
+}
@@ -0,0 +1,238 @@
+// Package httpscan serves the results of queries as JSON over HTTP
+package httpscan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/stephenafamo/scan"
+)
+
+// Page is the requested page of results, taken from the
+// "limit" and "offset" URL query parameters
+type Page struct {
+	Limit  int
+	Offset int
+}
+
+// ParamBinder returns the args for the query from the request and the requested page.
+// The registered query is expected to use the page for its LIMIT and OFFSET.
+// Errors are returned to the client with a 400 status code
+type ParamBinder func(r *http.Request, p Page) ([]any, error)
+
+// Option configures a [Handler]
+type Option func(*settings)
+
+type settings struct {
+	defaultLimit int
+	maxLimit     int
+	flushEvery   int
+	heartbeat    time.Duration
+	onError      func(w http.ResponseWriter, r *http.Request, status int, err error)
+}
+
+func newSettings(opts []Option) settings {
+	s := settings{
+		defaultLimit: 100,
+		maxLimit:     1000,
+		flushEvery:   100,
+		heartbeat:    15 * time.Second,
+		onError:      defaultErrorHandler,
+	}
+	for _, o := range opts {
+		o(&s)
+	}
+
+	return s
+}
+
+// WithDefaultLimit sets the limit used when the request does not have one.
+// The default is 100
+func WithDefaultLimit(n int) Option {
+	return func(s *settings) {
+		s.defaultLimit = n
+	}
+}
+
+// WithMaxLimit sets the largest limit a request can ask for.
+// The default is 1000
+func WithMaxLimit(n int) Option {
+	return func(s *settings) {
+		s.maxLimit = n
+	}
+}
+
+// WithFlushEvery flushes the response after every n rows.
+// [Live] also flushes whenever it is waiting for the next row.
+// The default is 100
+func WithFlushEvery(n int) Option {
+	return func(s *settings) {
+		s.flushEvery = n
+	}
+}
+
+// WithHeartbeat sets how often a heartbeat is sent by [Live] while waiting for rows,
+// so that proxies and clients do not close idle connections.
+// The default is 15 seconds. A zero duration disables heartbeats
+func WithHeartbeat(d time.Duration) Option {
+	return func(s *settings) {
+		s.heartbeat = d
+	}
+}
+
+// WithErrorHandler sets the function used to write errors that happen
+// before the results are streamed.
+// By default, the status text is written as plain text
+func WithErrorHandler(fn func(w http.ResponseWriter, r *http.Request, status int, err error)) Option {
+	return func(s *settings) {
+		s.onError = fn
+	}
+}
+
+func defaultErrorHandler(w http.ResponseWriter, _ *http.Request, status int, _ error) {
+	http.Error(w, http.StatusText(status), status)
+}
+
+// Handler returns an [http.Handler] that runs the registered query with the
+// args from the binder and streams the results as a JSON array.
+//
+// The page is set in the X-Page-Limit and X-Page-Offset headers, with a link
+// to the previous page in the Link header.
+// The number of returned rows is sent in the X-Result-Count trailer.
+// If the page is full, the link to the next page is sent in the Link trailer,
+// since it is only known once the rows have been streamed.
+//
+// If an error happens after streaming has started, the response is aborted.
+// Convert a *sql.DB or a *pgxpool.Pool into a [scan.Queryer] with stdscan.Wrap or pgxscan.Wrap
+//
+//	http.Handle("/users", httpscan.Handler(stdscan.Wrap(db), q, scan.StructMapper[User](), bind))
+func Handler[T any](exec scan.Queryer, q scan.RegisteredQuery, m scan.Mapper[T], bind ParamBinder, opts ...Option) http.Handler {
+	s := newSettings(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, err := s.page(r.URL.Query())
+		if err != nil {
+			s.onError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		args, err := bind(r, page)
+		if err != nil {
+			s.onError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		c, err := scan.Cursor(r.Context(), exec, m, q.SQL, args...)
+		if err != nil {
+			s.onError(w, r, http.StatusInternalServerError, err)
+			return
+		}
+		defer c.Close()
+
+		setPageHeaders(w.Header(), r.URL, page)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Trailer", "X-Result-Count")
+
+		count, err := stream(w, c, s.flushEvery)
+		if err != nil {
+			panic(http.ErrAbortHandler)
+		}
+
+		w.Header().Set("X-Result-Count", strconv.Itoa(count))
+		if count >= page.Limit {
+			w.Header().Set(http.TrailerPrefix+"Link", pageLink(r.URL, page.Limit, page.Offset+page.Limit, "next"))
+		}
+	})
+}
+
+func (s settings) page(query url.Values) (Page, error) {
+	p := Page{Limit: s.defaultLimit}
+
+	if l := query.Get("limit"); l != "" {
+		limit, err := strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			return p, fmt.Errorf("invalid limit %q", l)
+		}
+		p.Limit = limit
+	}
+
+	if p.Limit > s.maxLimit {
+		p.Limit = s.maxLimit
+	}
+
+	if o := query.Get("offset"); o != "" {
+		offset, err := strconv.Atoi(o)
+		if err != nil || offset < 0 {
+			return p, fmt.Errorf("invalid offset %q", o)
+		}
+		p.Offset = offset
+	}
+
+	return p, nil
+}
+
+// stream writes the rows from the cursor as a JSON array
+func stream[T any](w http.ResponseWriter, c scan.ICursor[T], flushEvery int) (int, error) {
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	if _, err := io.WriteString(w, "["); err != nil {
+		return 0, err
+	}
+
+	var count int
+	for c.Next() {
+		row, err := c.Get()
+		if err != nil {
+			return count, err
+		}
+
+		if count > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return count, err
+			}
+		}
+
+		if err := enc.Encode(row); err != nil {
+			return count, err
+		}
+
+		count++
+		if flusher != nil && flushEvery > 0 && count%flushEvery == 0 {
+			flusher.Flush()
+		}
+	}
+
+	if err := c.Err(); err != nil {
+		return count, err
+	}
+
+	_, err := io.WriteString(w, "]\n")
+	return count, err
+}
+
+func setPageHeaders(h http.Header, u *url.URL, p Page) {
+	h.Set("X-Page-Limit", strconv.Itoa(p.Limit))
+	h.Set("X-Page-Offset", strconv.Itoa(p.Offset))
+
+	if p.Offset > 0 {
+		prev := p.Offset - p.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		h.Add("Link", pageLink(u, p.Limit, prev, "prev"))
+	}
+}
+
+// pageLink returns a Link header value for the page of the URL at the offset
+func pageLink(u *url.URL, limit, offset int, rel string) string {
+	q := u.Query()
+	q.Set("limit", strconv.Itoa(limit))
+	q.Set("offset", strconv.Itoa(offset))
+	return fmt.Sprintf("<%s?%s>; rel=%q", u.Path, q.Encode(), rel)
+}
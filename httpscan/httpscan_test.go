@@ -0,0 +1,118 @@
+package httpscan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stephenafamo/scan"
+)
+
+type user struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type queryer struct {
+	query string
+	args  []any
+}
+
+func (q *queryer) QueryContext(ctx context.Context, query string, args ...any) (scan.Rows, error) {
+	q.query = query
+	q.args = args
+	return &rows{data: [][2]any{{1, "foo"}, {2, "bar"}}}, nil
+}
+
+type rows struct {
+	data  [][2]any
+	index int
+}
+
+func (r *rows) Scan(dest ...any) error {
+	row := r.data[r.index-1]
+	*(dest[0].(*int)) = row[0].(int)
+	*(dest[1].(*string)) = row[1].(string)
+	return nil
+}
+
+func (r *rows) Columns() ([]string, error) { return []string{"id", "name"}, nil }
+func (r *rows) Next() bool                 { r.index++; return r.index <= len(r.data) }
+func (r *rows) Close() error               { return nil }
+func (r *rows) Err() error                 { return nil }
+
+func TestHandler(t *testing.T) {
+	reg := scan.NewQueryRegistry()
+	q := reg.MustRegister("users", "SELECT id, name FROM users WHERE name LIKE ? LIMIT ? OFFSET ?")
+
+	exec := &queryer{}
+	h := Handler(exec, q, scan.StructMapper[user](), func(r *http.Request, p Page) ([]any, error) {
+		return []any{r.URL.Query().Get("name"), p.Limit, p.Offset}, nil
+	}, WithMaxLimit(50))
+
+	req := httptest.NewRequest(http.MethodGet, "/users?name=f%25&limit=100&offset=10", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("wrong status: %d", rec.Code)
+	}
+
+	if exec.query != q.SQL {
+		t.Fatalf("wrong query: %s", exec.query)
+	}
+
+	if len(exec.args) != 3 || exec.args[0] != "f%" || exec.args[1] != 50 || exec.args[2] != 10 {
+		t.Fatalf("wrong args: %v", exec.args)
+	}
+
+	expected := `[{"id":1,"name":"foo"}` + "\n" + `,{"id":2,"name":"bar"}` + "\n" + "]\n"
+	if rec.Body.String() != expected {
+		t.Fatalf("wrong body.\nExpected: %s\nGot: %s", expected, rec.Body.String())
+	}
+
+	headers := rec.Result().Header
+	if headers.Get("X-Page-Limit") != "50" || headers.Get("X-Page-Offset") != "10" {
+		t.Fatalf("wrong page headers: %v", headers)
+	}
+
+	if links := headers.Values("Link"); len(links) != 1 || links[0] != `</users?limit=50&name=f%25&offset=0>; rel="prev"` {
+		t.Fatalf("expected a prev link, got %v", links)
+	}
+
+	trailer := rec.Result().Trailer
+	if count := trailer.Get("X-Result-Count"); count != strconv.Itoa(2) {
+		t.Fatalf("wrong result count: %s", count)
+	}
+
+	// The page is not full, so there is no next page
+	if next := trailer.Values("Link"); len(next) != 0 {
+		t.Fatalf("expected no next link, got %v", next)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?limit=2", nil))
+
+	if links := rec.Result().Header.Values("Link"); len(links) != 0 {
+		t.Fatalf("expected no prev link on the first page, got %v", links)
+	}
+
+	if next := rec.Result().Trailer.Get("Link"); next != `</users?limit=2&offset=2>; rel="next"` {
+		t.Fatalf("wrong next link: %s", next)
+	}
+}
+
+func TestHandlerBadPage(t *testing.T) {
+	h := Handler(&queryer{}, scan.RegisteredQuery{}, scan.StructMapper[user](), func(r *http.Request, p Page) ([]any, error) {
+		return nil, nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users?limit=-1", nil))
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("wrong status: %d", rec.Code)
+	}
+}
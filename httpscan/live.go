@@ -0,0 +1,219 @@
+package httpscan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stephenafamo/scan"
+)
+
+// The events sent by [Live]
+const (
+	// EventRow is sent with each row encoded as JSON
+	EventRow = "row"
+	// EventHeartbeat is sent with no data while waiting for rows
+	EventHeartbeat = "heartbeat"
+	// EventEnd is sent with the number of rows, e.g. {"count":10}, after the last row
+	EventEnd = "end"
+	// EventError is sent by [EventsHandler] if the query fails
+	EventError = "error"
+)
+
+// Sender sends the events of a live stream to a client,
+// e.g. as Server-Sent Events or WebSocket messages
+type Sender interface {
+	Send(event string, data []byte) error
+	Flush() error
+}
+
+// Live runs the query and sends each row to the sender as soon as it is scanned,
+// for clients tailing long-running queries.
+// A heartbeat is sent while waiting for rows (see [WithHeartbeat]).
+// The sender is flushed whenever there is no row ready, and every n rows (see [WithFlushEvery]).
+//
+// It returns the number of rows sent. The query is canceled if sending fails
+func Live[T any](ctx context.Context, exec scan.Queryer, m scan.Mapper[T], sender Sender, opts []Option, query string, args ...any) (int, error) {
+	s := newSettings(opts)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	c, err := scan.Cursor(ctx, exec, m, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := make(chan liveRow[T])
+	done := make(chan struct{})
+	go readRows(c, rows, done)
+
+	defer func() {
+		cancel()
+		close(done)
+		for range rows {
+		}
+		c.Close()
+	}()
+
+	var heartbeat <-chan time.Time
+	if s.heartbeat > 0 {
+		ticker := time.NewTicker(s.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	var count, unflushed int
+	for {
+		var row liveRow[T]
+		var ok bool
+
+		select {
+		case row, ok = <-rows:
+		default:
+			// Flush before waiting for the next row
+			if unflushed > 0 {
+				if err := sender.Flush(); err != nil {
+					return count, err
+				}
+				unflushed = 0
+			}
+
+			select {
+			case row, ok = <-rows:
+			case <-heartbeat:
+				if err := sender.Send(EventHeartbeat, nil); err != nil {
+					return count, err
+				}
+				unflushed++
+				continue
+			case <-ctx.Done():
+				return count, ctx.Err()
+			}
+		}
+
+		if !ok {
+			if err := sender.Send(EventEnd, []byte(fmt.Sprintf(`{"count":%d}`, count))); err != nil {
+				return count, err
+			}
+			return count, sender.Flush()
+		}
+
+		if row.err != nil {
+			return count, row.err
+		}
+
+		data, err := json.Marshal(row.val)
+		if err != nil {
+			return count, err
+		}
+
+		if err := sender.Send(EventRow, data); err != nil {
+			return count, err
+		}
+
+		count++
+		unflushed++
+		if s.flushEvery > 0 && unflushed >= s.flushEvery {
+			if err := sender.Flush(); err != nil {
+				return count, err
+			}
+			unflushed = 0
+		}
+	}
+}
+
+type liveRow[T any] struct {
+	val T
+	err error
+}
+
+// readRows sends the rows of the cursor until it is done or the done channel is closed.
+// The rows channel is closed when it returns
+func readRows[T any](c scan.ICursor[T], rows chan<- liveRow[T], done <-chan struct{}) {
+	defer close(rows)
+
+	for c.Next() {
+		val, err := c.Get()
+		select {
+		case rows <- liveRow[T]{val: val, err: err}:
+		case <-done:
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+
+	if err := c.Err(); err != nil {
+		select {
+		case rows <- liveRow[T]{err: err}:
+		case <-done:
+		}
+	}
+}
+
+// NewEventStream returns a [Sender] that writes Server-Sent Events to w
+func NewEventStream(w http.ResponseWriter) Sender {
+	flusher, _ := w.(http.Flusher)
+	return eventStream{w: w, flusher: flusher}
+}
+
+type eventStream struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (e eventStream) Send(event string, data []byte) error {
+	_, err := fmt.Fprintf(e.w, "event: %s\ndata: %s\n\n", event, data)
+	return err
+}
+
+func (e eventStream) Flush() error {
+	if e.flusher != nil {
+		e.flusher.Flush()
+	}
+	return nil
+}
+
+// EventsHandler returns an [http.Handler] that runs the registered query with the
+// args from the binder and sends the rows as Server-Sent Events using [Live].
+//
+// If the query fails, an [EventError] is sent with the status text as a JSON string
+func EventsHandler[T any](exec scan.Queryer, q scan.RegisteredQuery, m scan.Mapper[T], bind ParamBinder, opts ...Option) http.Handler {
+	s := newSettings(opts)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, err := s.page(r.URL.Query())
+		if err != nil {
+			s.onError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		args, err := bind(r, page)
+		if err != nil {
+			s.onError(w, r, http.StatusBadRequest, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		sender := NewEventStream(w)
+		if _, err := Live(r.Context(), exec, m, sender, opts, q.SQL, args...); err != nil {
+			if r.Context().Err() != nil {
+				return
+			}
+
+			msg, _ := json.Marshal(http.StatusText(http.StatusInternalServerError))
+			if sender.Send(EventError, msg) == nil {
+				sender.Flush()
+			}
+		}
+	})
+}
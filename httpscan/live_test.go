@@ -0,0 +1,118 @@
+package httpscan
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stephenafamo/scan"
+)
+
+type recordSender struct {
+	events  []string
+	flushes int
+	err     error
+}
+
+func (s *recordSender) Send(event string, data []byte) error {
+	s.events = append(s.events, event+" "+string(data))
+	return s.err
+}
+
+func (s *recordSender) Flush() error {
+	s.flushes++
+	return nil
+}
+
+// slowRows waits before returning each row
+type slowRows struct {
+	rows
+	delay time.Duration
+}
+
+func (r *slowRows) Next() bool {
+	time.Sleep(r.delay)
+	return r.rows.Next()
+}
+
+type slowQueryer struct {
+	delay time.Duration
+}
+
+func (q slowQueryer) QueryContext(ctx context.Context, query string, args ...any) (scan.Rows, error) {
+	return &slowRows{rows: rows{data: [][2]any{{1, "foo"}}}, delay: q.delay}, nil
+}
+
+func TestLive(t *testing.T) {
+	sender := &recordSender{}
+	count, err := Live(context.Background(), &queryer{}, scan.StructMapper[user](), sender, nil, "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if count != 2 {
+		t.Fatalf("wrong count: %d", count)
+	}
+
+	expected := []string{
+		`row {"id":1,"name":"foo"}`,
+		`row {"id":2,"name":"bar"}`,
+		`end {"count":2}`,
+	}
+	if strings.Join(sender.events, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("wrong events: %v", sender.events)
+	}
+
+	if sender.flushes == 0 {
+		t.Fatal("sender was not flushed")
+	}
+}
+
+func TestLiveHeartbeat(t *testing.T) {
+	sender := &recordSender{}
+	opts := []Option{WithHeartbeat(5 * time.Millisecond)}
+	_, err := Live(context.Background(), slowQueryer{delay: 30 * time.Millisecond}, scan.StructMapper[user](), sender, opts, "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if sender.events[0] != EventHeartbeat+" " {
+		t.Fatalf("expected a heartbeat first, got %v", sender.events)
+	}
+}
+
+func TestLiveSendError(t *testing.T) {
+	sendErr := errors.New("closed")
+	sender := &recordSender{err: sendErr}
+	_, err := Live(context.Background(), &queryer{}, scan.StructMapper[user](), sender, nil, "SELECT id, name FROM users")
+	if !errors.Is(err, sendErr) {
+		t.Fatalf("expected the send error, got %v", err)
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	reg := scan.NewQueryRegistry()
+	q := reg.MustRegister("users", "SELECT id, name FROM users")
+
+	h := EventsHandler(&queryer{}, q, scan.StructMapper[user](), func(r *http.Request, p Page) ([]any, error) {
+		return nil, nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/live", nil))
+
+	if ct := rec.Result().Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("wrong content type: %s", ct)
+	}
+
+	expected := "event: row\ndata: {\"id\":1,\"name\":\"foo\"}\n\n" +
+		"event: row\ndata: {\"id\":2,\"name\":\"bar\"}\n\n" +
+		"event: end\ndata: {\"count\":2}\n\n"
+	if rec.Body.String() != expected {
+		t.Fatalf("wrong body.\nExpected: %s\nGot: %s", expected, rec.Body.String())
+	}
+}
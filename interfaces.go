@@ -40,4 +40,5 @@ type RowValidator = func(cols []string, vals []reflect.Value) bool
 
 type StructMapperSource interface {
 	getMapping(reflect.Type) (mapping, error)
+	getMappingDepth(typ reflect.Type, depth int) (mapping, []recursion, error)
 }
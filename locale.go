@@ -0,0 +1,218 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aarondl/opt"
+)
+
+// Locale describes how numbers and dates are written in text columns,
+// for text-protocol sources such as CSV files. Use it with [LocaleConverter]
+type Locale struct {
+	// DecimalSeparator separates the fractional part of numbers. The default is '.'
+	DecimalSeparator rune
+	// GroupSeparators are the digit group separators that are ignored in numbers
+	GroupSeparators string
+	// DateLayouts are tried in order to parse dates and times, see [time.Parse].
+	// Values without a time zone are parsed as UTC
+	DateLayouts []string
+}
+
+// Some common locales
+var (
+	LocaleUS = Locale{
+		DecimalSeparator: '.',
+		GroupSeparators:  ",",
+		DateLayouts:      []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02", "01/02/2006 15:04:05", "01/02/2006"},
+	}
+	LocaleUK = Locale{
+		DecimalSeparator: '.',
+		GroupSeparators:  ",",
+		DateLayouts:      []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02", "02/01/2006 15:04:05", "02/01/2006"},
+	}
+	LocaleDE = Locale{
+		DecimalSeparator: ',',
+		GroupSeparators:  ".",
+		DateLayouts:      []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02", "02.01.2006 15:04:05", "02.01.2006"},
+	}
+	LocaleFR = Locale{
+		DecimalSeparator: ',',
+		GroupSeparators:  " \u00a0\u202f",
+		DateLayouts:      []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02", "02/01/2006 15:04:05", "02/01/2006"},
+	}
+)
+
+// ctxKeyLocale holds the [Locale] for a single query
+var ctxKeyLocale contextKey = "locale"
+
+// WithLocale returns a context that makes every [LocaleConverter] used with it
+// parse values with the given locale instead of its own
+func WithLocale(ctx context.Context, l Locale) context.Context {
+	return context.WithValue(ctx, ctxKeyLocale, l)
+}
+
+// contextConverter is implemented by type converters that depend on the context of the query
+type contextConverter interface {
+	withContext(ctx context.Context) TypeConverter
+}
+
+// LocaleConverter returns a [TypeConverter] that parses text values of number and [time.Time] fields
+// (and pointers to them) with the locale, e.g. 1.234,5 or 31.12.2023 for [LocaleDE].
+// Values that are not text are converted as usual, and other fields are scanned as usual.
+//
+// The locale can be changed for a query with [WithLocale].
+//
+//	m := scan.StructMapper[Sale](scan.WithTypeConverter(scan.LocaleConverter(scan.LocaleDE)))
+func LocaleConverter(l Locale) TypeConverter {
+	return localeConverter{locale: l}
+}
+
+type localeConverter struct {
+	locale Locale
+}
+
+func (c localeConverter) withContext(ctx context.Context) TypeConverter {
+	if l, ok := ctx.Value(ctxKeyLocale).(Locale); ok {
+		return localeConverter{locale: l}
+	}
+
+	return c
+}
+
+// TypeToDestination implements [TypeConverter]
+func (c localeConverter) TypeToDestination(typ reflect.Type) reflect.Value {
+	isPointer := typ.Kind() == reflect.Pointer
+	base := typ
+	if isPointer {
+		base = typ.Elem()
+	}
+
+	if !isLocalized(base) {
+		return reflect.New(typ)
+	}
+
+	return reflect.ValueOf(&convertDest{
+		typ:       typ,
+		isPointer: isPointer,
+		fn: func(src any) (reflect.Value, error) {
+			return c.locale.parse(base, src)
+		},
+	})
+}
+
+// ValueFromDestination implements [TypeConverter]
+func (c localeConverter) ValueFromDestination(val reflect.Value) reflect.Value {
+	if dest, ok := val.Interface().(*convertDest); ok {
+		return dest.value()
+	}
+
+	return val.Elem()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// isLocalized reports if values of the type are parsed with the locale
+func isLocalized(typ reflect.Type) bool {
+	if typ == timeType {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// parse converts the column value into a value of the type
+func (l Locale) parse(typ reflect.Type, src any) (reflect.Value, error) {
+	v := reflect.New(typ).Elem()
+
+	var text string
+	switch src := src.(type) {
+	case nil:
+		return v, nil
+	case string:
+		text = src
+	case []byte:
+		text = string(src)
+	default:
+		err := opt.ConvertAssign(v.Addr().Interface(), src)
+		return v, err
+	}
+
+	text = strings.TrimSpace(text)
+	if typ == timeType {
+		t, err := l.parseTime(text)
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.ValueOf(t))
+		return v, nil
+	}
+
+	text = l.normalizeNumber(text)
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(u)
+
+	default:
+		i, err := strconv.ParseInt(text, 10, typ.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(i)
+	}
+
+	return v, nil
+}
+
+// normalizeNumber removes the group separators and uses '.' as the decimal separator
+func (l Locale) normalizeNumber(text string) string {
+	decimal := l.DecimalSeparator
+	if decimal == 0 {
+		decimal = '.'
+	}
+
+	var b strings.Builder
+	for _, r := range text {
+		switch {
+		case r == decimal:
+			b.WriteRune('.')
+		case strings.ContainsRune(l.GroupSeparators, r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+func (l Locale) parseTime(text string) (time.Time, error) {
+	for _, layout := range l.DateLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse %q as a time with layouts %q", text, l.DateLayouts)
+}
@@ -0,0 +1,63 @@
+package scan
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Sale struct {
+	ID     int
+	Amount float64
+	Units  *uint16
+	SoldAt time.Time
+	Note   string
+}
+
+func TestLocaleConverter(t *testing.T) {
+	m := StructMapper[Sale](WithTypeConverter(LocaleConverter(LocaleDE)))
+	cols := []string{"id", "amount", "units", "sold_at", "note"}
+
+	rows := newSliceRows(cols,
+		[]any{"1", "1.234,5", "12", "31.12.2023", "3,5"},
+		[]any{int64(2), 7.25, nil, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), "x"},
+	)
+
+	sales, err := AllFromRows(context.Background(), m, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Sale{
+		{ID: 1, Amount: 1234.5, Units: toPtr[uint16](12), SoldAt: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC), Note: "3,5"},
+		{ID: 2, Amount: 7.25, SoldAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Note: "x"},
+	}
+	if diff := cmp.Diff(expected, sales); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The locale from the context is used instead
+	ctx := WithLocale(context.Background(), LocaleUS)
+	rows = newSliceRows(cols, []any{"3", "1,234.5", "70000", "12/31/2023", ""})
+	if _, err := AllFromRows(ctx, m, rows); err == nil {
+		t.Fatal("expected an error for a value that overflows uint16")
+	}
+
+	rows = newSliceRows(cols, []any{"3", "1,234.5", "7", "12/31/2023", ""})
+	sales, err = AllFromRows(ctx, m, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []Sale{{ID: 3, Amount: 1234.5, Units: toPtr[uint16](7), SoldAt: time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)}}
+	if diff := cmp.Diff(expected, sales); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows(cols, []any{"4", "1", "1", "2023/31/12", ""})
+	if _, err := AllFromRows(ctx, m, rows); err == nil {
+		t.Fatal("expected an error for a date that does not match the layouts")
+	}
+}
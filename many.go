@@ -0,0 +1,87 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoMoreResultSets is returned by [Many] when the query returns
+// fewer result sets than expected
+var ErrNoMoreResultSets = errors.New("no more result sets")
+
+// MultiRows is implemented by [Rows] that can hold multiple result sets, such as *sql.Rows
+type MultiRows interface {
+	Rows
+	NextResultSet() bool
+}
+
+// ResultSet maps one of the result sets of a query. Create one with [Set]
+type ResultSet interface {
+	scanResultSet(ctx context.Context, rows Rows) error
+}
+
+// Set returns a [ResultSet] that maps all the rows of a result set with m,
+// and stores them in dest
+func Set[T any](m Mapper[T], dest *[]T) ResultSet {
+	return resultSet[T]{mapper: m, dest: dest}
+}
+
+type resultSet[T any] struct {
+	mapper Mapper[T]
+	dest   *[]T
+}
+
+func (r resultSet[T]) scanResultSet(ctx context.Context, rows Rows) error {
+	all, err := AllFromRows(ctx, r.mapper, rows)
+	if err != nil {
+		return err
+	}
+
+	*r.dest = all
+	return nil
+}
+
+// Many runs a query that returns multiple result sets, such as a stored procedure
+// or a batch of statements, and maps each result set with its own [ResultSet] in order.
+// The [Rows] returned by the Queryer must implement [MultiRows] if there is more than one set.
+//
+//	var users []User
+//	var posts []Post
+//	err := scan.Many(ctx, db, []scan.ResultSet{
+//	    scan.Set(scan.StructMapper[User](), &users),
+//	    scan.Set(scan.StructMapper[Post](), &posts),
+//	}, "EXEC user_with_posts @id = ?", 1)
+func Many(ctx context.Context, exec Queryer, sets []ResultSet, query string, args ...any) error {
+	rows, err := queryContext(ctx, exec, nil, query, args)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return ManyFromRows(ctx, rows, sets...)
+}
+
+// ManyFromRows maps each result set of the given [Rows] with its own [ResultSet] in order.
+// Any result sets after the given ones are ignored
+func ManyFromRows(ctx context.Context, rows Rows, sets ...ResultSet) error {
+	multi, isMulti := rows.(MultiRows)
+	if len(sets) > 1 && !isMulti {
+		return fmt.Errorf("%T does not support multiple result sets", rows)
+	}
+
+	for i, set := range sets {
+		if i > 0 && !multi.NextResultSet() {
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			return fmt.Errorf("result set %d: %w", i+1, ErrNoMoreResultSets)
+		}
+
+		if err := set.scanResultSet(ctx, rows); err != nil {
+			return fmt.Errorf("result set %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
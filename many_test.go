@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// multiSliceRows holds multiple in-memory result sets
+type multiSliceRows struct {
+	*sliceRows
+	sets []*sliceRows
+}
+
+func newMultiSliceRows(sets ...*sliceRows) *multiSliceRows {
+	return &multiSliceRows{sliceRows: sets[0], sets: sets[1:]}
+}
+
+func (m *multiSliceRows) NextResultSet() bool {
+	if len(m.sets) == 0 {
+		return false
+	}
+
+	m.sliceRows, m.sets = m.sets[0], m.sets[1:]
+	return true
+}
+
+func TestMany(t *testing.T) {
+	ctx := context.Background()
+	newRows := func() *multiSliceRows {
+		return newMultiSliceRows(
+			newSliceRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"}),
+			newSliceRows([]string{"count"}, []any{2}),
+		)
+	}
+
+	var users []User
+	var counts []int
+	err := ManyFromRows(ctx, newRows(),
+		Set(StructMapper[User](), &users),
+		Set(SingleColumnMapper[int], &counts),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]User{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if diff := cmp.Diff([]int{2}, counts); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	err = ManyFromRows(ctx, newRows(),
+		Set(StructMapper[User](), &users),
+		Set(SingleColumnMapper[int], &counts),
+		Set(SingleColumnMapper[int], &counts),
+	)
+	if !errors.Is(err, ErrNoMoreResultSets) {
+		t.Fatalf("expected ErrNoMoreResultSets, got %v", err)
+	}
+
+	err = ManyFromRows(ctx, newSliceRows([]string{"id"}),
+		Set(SingleColumnMapper[int], &counts),
+		Set(SingleColumnMapper[int], &counts),
+	)
+	if err == nil {
+		t.Fatal("expected an error for rows without multiple result sets")
+	}
+}
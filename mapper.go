@@ -26,10 +26,50 @@ type mapinfo struct {
 	position  []int
 	init      [][]int
 	isPointer bool
+	// optional is true if the field is inside a struct reached through a pointer
+	optional bool
+	// container is true for struct fields whose own fields are also mapped.
+	// They are only used if a type converter scans the struct as a single value
+	container bool
+	typ       reflect.Type
+	// decode is set for fields that decode the column value e.g. with the json tag option
+	decode decodeFunc
+	// remain is set for the map field that receives the columns not matched to other fields
+	remain bool
+	// def is the text of the default value of the field, set with the default tag option
+	def *string
 }
 
 type mapping []mapinfo
 
+// withContainers returns the mapping without the container fields
+// that the type converter does not scan as a single value.
+// The remain field is also removed since it is not mapped by name
+func (m mapping) withContainers(tc TypeConverter) mapping {
+	sc, _ := tc.(structConverter)
+
+	filtered := make(mapping, 0, len(m))
+	for _, info := range m {
+		if info.remain || info.container && (sc == nil || !sc.convertsType(info.typ)) {
+			continue
+		}
+		filtered = append(filtered, info)
+	}
+
+	return filtered
+}
+
+// remainField returns the field tagged with the remain option, if any
+func (m mapping) remainField() (mapinfo, bool) {
+	for _, info := range m {
+		if info.remain {
+			return info, true
+		}
+	}
+
+	return mapinfo{}, false
+}
+
 func (m mapping) cols() []string {
 	cols := make([]string, len(m))
 	for i, info := range m {
@@ -69,35 +109,6 @@ func ErrorMapper[T any](err error, meta ...string) (func(*Row) (any, error), fun
 		}
 }
 
-// Returns a [MappingError] with some optional metadata
-func createError(err error, meta ...string) error {
-	if me, ok := err.(*MappingError); ok && len(meta) == 0 {
-		return me
-	}
-
-	return &MappingError{cause: err, meta: meta}
-}
-
-// MappingError wraps another error and holds some additional metadata
-type MappingError struct {
-	meta  []string // easy compare
-	cause error
-}
-
-// Unwrap returns the wrapped error
-func (m *MappingError) Unwrap() error {
-	return m.cause
-}
-
-// Error implements the error interface
-func (m *MappingError) Error() string {
-	if m.cause == nil {
-		return ""
-	}
-
-	return m.cause.Error()
-}
-
 // For queries that return only one column
 // throws an error if there is more than one column
 func SingleColumnMapper[T any](ctx context.Context, c cols) (before func(*Row) (any, error), after func(any) (T, error)) {
@@ -116,11 +127,22 @@ func SingleColumnMapper[T any](ctx context.Context, c cols) (before func(*Row) (
 }
 
 // Map a column by name.
+// All other columns in the result are ignored
 func ColumnMapper[T any](name string) func(ctx context.Context, c cols) (before func(*Row) (any, error), after func(any) (T, error)) {
 	return func(ctx context.Context, c cols) (before func(*Row) (any, error), after func(any) (T, error)) {
+		// The values of other columns are scanned here and discarded
+		discard := reflect.ValueOf(new(any))
+
 		return func(v *Row) (any, error) {
 				var t T
 				v.ScheduleScan(name, &t)
+
+				for _, other := range c {
+					if other != name {
+						v.ScheduleScanx(other, discard)
+					}
+				}
+
 				return &t, nil
 			}, func(v any) (T, error) {
 				return *(v.(*T)), nil
@@ -143,6 +165,79 @@ func SliceMapper[T any](ctx context.Context, c cols) (before func(*Row) (any, er
 		}
 }
 
+// Maps each row into an array type A such as [2]int, e.g. for SELECT lo, hi.
+// The number of columns must be the same as the length of the array
+func ArrayMapper[A any](ctx context.Context, c cols) (before func(*Row) (any, error), after func(any) (A, error)) {
+	typ := typeOf[A]()
+	if typ == nil || typ.Kind() != reflect.Array {
+		return ErrorMapper[A](fmt.Errorf("Type %q is not an array", fmt.Sprint(typ)))
+	}
+
+	if len(c) != typ.Len() {
+		err := fmt.Errorf("Expected %d columns but got %d columns", typ.Len(), len(c))
+		return ErrorMapper[A](err, "wrong column count", strconv.Itoa(typ.Len()), strconv.Itoa(len(c)))
+	}
+
+	return func(v *Row) (any, error) {
+			row := reflect.New(typ).Elem()
+
+			for index, name := range c {
+				v.ScheduleScanx(name, row.Index(index).Addr())
+			}
+
+			return row, nil
+		}, func(v any) (A, error) {
+			return v.(reflect.Value).Interface().(A), nil
+		}
+}
+
+// StructArrayMapper maps each row into a slice with one T for each prefix,
+// for queries that select a small group of structs side by side.
+// Each struct is mapped with [StructMapper] using the prefix as the struct tag prefix
+//
+//	// []scan.User{{ID: 1, ...}, {ID: 2, ...}}
+//	pair, _ := scan.One(ctx, db, scan.StructArrayMapper[User]("a.", "b."),
+//	    `SELECT a.id AS "a.id", a.name AS "a.name", b.id AS "b.id", b.name AS "b.name" FROM ...`)
+func StructArrayMapper[T any](prefixes ...string) Mapper[[]T] {
+	mappers := make([]Mapper[T], len(prefixes))
+	for i, prefix := range prefixes {
+		mappers[i] = StructMapper[T](WithStructTagPrefix(prefix))
+	}
+
+	return func(ctx context.Context, c cols) (func(*Row) (any, error), func(any) ([]T, error)) {
+		befores := make([]func(*Row) (any, error), len(mappers))
+		afters := make([]func(any) (T, error), len(mappers))
+		for i, m := range mappers {
+			befores[i], afters[i] = m(ctx, c)
+		}
+
+		return func(v *Row) (any, error) {
+				links := make([]any, len(befores))
+				for i, before := range befores {
+					link, err := before(v)
+					if err != nil {
+						return nil, err
+					}
+					links[i] = link
+				}
+
+				return links, nil
+			}, func(v any) ([]T, error) {
+				links := v.([]any)
+				row := make([]T, len(afters))
+				for i, after := range afters {
+					one, err := after(links[i])
+					if err != nil {
+						return nil, err
+					}
+					row[i] = one
+				}
+
+				return row, nil
+			}
+	}
+}
+
 // Maps all rows into map[string]T
 // Most likely used with interface{} to get a map[string]interface{}
 func MapMapper[T any](ctx context.Context, c cols) (before func(*Row) (any, error), after func(any) (map[string]T, error)) {
@@ -2,15 +2,48 @@ package scan
 
 import (
 	"context"
+	"database/sql"
+	"encoding"
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // CtxKeyAllowUnknownColumns makes it possible to allow unknown columns using the context
 var CtxKeyAllowUnknownColumns contextKey = "allow unknown columns"
 
+// CtxKeyEnforceAllFields makes it possible to enforce all fields using the context
+// See [WithEnforceAllFields]
+var CtxKeyEnforceAllFields contextKey = "enforce all fields"
+
+// mappable is implemented by pointers to structs that can return the scan
+// destinations for their own fields. It is usually generated with cmd/scangen.
+//
+// MapValues is called with the mapping key of a column (i.e. the column name
+// without any struct tag prefix), and should return a pointer to the field to
+// scan into, or nil if there is no such field.
+//
+// If the type implements this, [StructMapper] uses it instead of reflection,
+// unless an option that MapValues cannot apply is set, see mappingOptions.mapValuesSafe,
+// or [WithoutMapValues] is used.
+//
+// The settings of the [StructMapperSource], such as the struct tag key, the name mapper
+// and tag options like default and trim, are not used with MapValues.
+// The generated method has the column names computed when it was generated
+type mappable interface {
+	MapValues(ctx context.Context, key string) any
+}
+
 // Uses reflection to create a mapping function for a struct type
-// using the default options
+// using the default options.
+// Maps, such as map[string]any, are mapped like [MapMapper]
+// so that generic code can use it with either structs or maps.
+// Map keys are converted from the column names, so map[int]float64 works
+// for a crosstab query with the columns "2022" and "2023".
+// Primitive and scannable types, such as int64 or time.Time, are scanned
+// from a single column like [SingleColumnMapper].
+// If T or *T implements [AfterScanner], AfterScan is called for each row
 func StructMapper[T any](opts ...MappingOption) Mapper[T] {
 	return CustomStructMapper[T](defaultStructMapper, opts...)
 }
@@ -23,6 +56,28 @@ func CustomStructMapper[T any](src StructMapperSource, optMod ...MappingOption)
 		o(&opts)
 	}
 
+	mapper := structMapperWithOptions[T](src, opts)
+
+	return func(ctx context.Context, c cols) (func(*Row) (any, error), func(any) (T, error)) {
+		perQuery, _ := ctx.Value(ctxKeyMappingOptions).([]MappingOption)
+		ctxMods, _ := ctx.Value(CtxKeyMapperMods).([]MapperMod)
+		if len(perQuery) == 0 && len(ctxMods) == 0 {
+			return mapper(ctx, c)
+		}
+
+		// Options for a single query are applied after the options of the mapper
+		merged := opts
+		merged.mapperMods = append([]MapperMod(nil), opts.mapperMods...)
+		for _, o := range perQuery {
+			o(&merged)
+		}
+		merged.mapperMods = append(merged.mapperMods, ctxMods...)
+
+		return structMapperWithOptions[T](src, merged)(ctx, c)
+	}
+}
+
+func structMapperWithOptions[T any](src StructMapperSource, opts mappingOptions) Mapper[T] {
 	mod := func(ctx context.Context, c cols) (func(*Row) (any, error), func(any) (T, error)) {
 		return structMapperFrom[T](ctx, c, src, opts)
 	}
@@ -31,25 +86,169 @@ func CustomStructMapper[T any](src StructMapperSource, optMod ...MappingOption)
 		mod = Mod(mod, opts.mapperMods...)
 	}
 
-	return mod
+	return withAfterScan(mod)
 }
 
 func structMapperFrom[T any](ctx context.Context, c cols, s StructMapperSource, opts mappingOptions) (func(*Row) (any, error), func(any) (T, error)) {
 	typ := typeOf[T]()
 
+	// Registered mappers are used instead of reflection
+	if m, ok := registeredMapper[T](opts.mappers); ok {
+		return m(ctx, c)
+	}
+
+	if opts.polymorphic != nil {
+		return polymorphicMapper[T](ctx, c, s, opts)
+	}
+
+	// Maps are mapped the same way as MapMapper
+	if typ != nil && typ.Kind() == reflect.Map && isMapKey(typ.Key()) {
+		return mapMapperOf[T](c, typ)
+	}
+
+	// Primitives and scannable types are scanned directly from a single column
+	if isScannable(s, typ) {
+		return SingleColumnMapper[T](ctx, c)
+	}
+
 	isPointer, err := checks(typ)
 	if err != nil {
 		return ErrorMapper[T](err)
 	}
 
-	mapping, err := s.getMapping(typ)
+	if enforce, _ := ctx.Value(CtxKeyEnforceAllFields).(bool); enforce {
+		opts.enforceAllFields = true
+	}
+
+	if isMappable(typ, isPointer) && opts.mapValuesSafe() {
+		return mappableMapper[T](ctx, c, typ, isPointer, opts)
+	}
+
+	depth := -1
+	if opts.maxDepth != nil {
+		depth = *opts.maxDepth
+	}
+
+	mapping, recursions, err := s.getMappingDepth(typ, depth)
 	if err != nil {
 		return ErrorMapper[T](err)
 	}
 
+	// Columns deeper than the max depth are unknown columns, so they are allowed the same way
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+
+	switch {
+	case !opts.allowUnknown && !allowUnknown:
+		if err := checkRecursions(c, mapping, recursions, typ, isPointer, opts.structTagPrefix, opts.columnNormalizer, nil); err != nil {
+			return ErrorMapper[T](err)
+		}
+	case opts.warnings != nil:
+		checkRecursions(c, mapping, recursions, typ, isPointer, opts.structTagPrefix, opts.columnNormalizer, opts.warnings)
+	}
+
 	return mapperFromMapping[T](mapping, typ, isPointer, opts)(ctx, c)
 }
 
+// mapMapperOf is like [MapMapper] for a map type only known at runtime.
+// The keys are converted from the column names once, see [mapKey]
+func mapMapperOf[T any](c cols, typ reflect.Type) (func(*Row) (any, error), func(any) (T, error)) {
+	keys := make([]reflect.Value, len(c))
+	for i, name := range c {
+		key, err := mapKey(name, typ.Key())
+		if err != nil {
+			err = fmt.Errorf("cannot use column %q as a key of %s: %w", name, typ, err)
+			return ErrorMapper[T](columnError(ErrConversion, name, "", err))
+		}
+		keys[i] = key
+	}
+
+	return func(v *Row) (any, error) {
+			row := make([]reflect.Value, len(c))
+			for i, name := range c {
+				row[i] = reflect.New(typ.Elem())
+				v.ScheduleScanx(name, row[i])
+			}
+
+			return row, nil
+		}, func(v any) (T, error) {
+			vals := v.([]reflect.Value)
+			row := reflect.MakeMapWithSize(typ, len(c))
+			for i, key := range keys {
+				row.SetMapIndex(key, vals[i].Elem())
+			}
+
+			return row.Interface().(T), nil
+		}
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// isMapKey reports if the column names can be converted to keys of the type
+func isMapKey(typ reflect.Type) bool {
+	if reflect.PtrTo(typ).Implements(textUnmarshalerType) {
+		return true
+	}
+
+	switch typ.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// mapKey converts the column name to a map key of the type, e.g. "2023" for map[int]V
+// in crosstab queries. Types that implement [encoding.TextUnmarshaler] are unmarshaled
+func mapKey(name string, typ reflect.Type) (reflect.Value, error) {
+	key := reflect.New(typ)
+	if u, ok := key.Interface().(encoding.TextUnmarshaler); ok {
+		err := u.UnmarshalText([]byte(name))
+		return key.Elem(), err
+	}
+
+	k := key.Elem()
+	switch typ.Kind() {
+	case reflect.String:
+		k.SetString(name)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(name)
+		if err != nil {
+			return k, err
+		}
+		k.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(name, 10, typ.Bits())
+		if err != nil {
+			return k, err
+		}
+		k.SetInt(i)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(name, 10, typ.Bits())
+		if err != nil {
+			return k, err
+		}
+		k.SetUint(u)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(name, typ.Bits())
+		if err != nil {
+			return k, err
+		}
+		k.SetFloat(f)
+
+	default:
+		return k, fmt.Errorf("unsupported key type %s", typ)
+	}
+
+	return k, nil
+}
+
 // Check if there are any errors, and returns if it is a pointer or not
 func checks(typ reflect.Type) (bool, error) {
 	if typ == nil {
@@ -73,16 +272,232 @@ func checks(typ reflect.Type) (bool, error) {
 	return isPointer, nil
 }
 
+// isScannable reports if values of the type are scanned from a single column
+// instead of being mapped field by field, such as int64, *string, []byte, time.Time
+// or a type that implements one of the scannable types of the source, e.g. [sql.Scanner].
+// Other types, such as []int or interfaces, are not scanned
+func isScannable(s StructMapperSource, typ reflect.Type) bool {
+	if typ == nil {
+		return false
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Slice:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return true
+		}
+	case reflect.Struct:
+		if typ == timeType {
+			return true
+		}
+	}
+
+	scannables := []reflect.Type{scannerType}
+	if impl, ok := s.(*mapperSourceImpl); ok {
+		scannables = impl.scannableTypes
+	}
+
+	for _, scannable := range scannables {
+		if reflect.PtrTo(typ).Implements(scannable) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isMappable(typ reflect.Type, isPointer bool) bool {
+	if !isPointer {
+		typ = reflect.PtrTo(typ)
+	}
+
+	return typ.Implements(reflect.TypeOf((*mappable)(nil)).Elem())
+}
+
+func mappableMapper[T any](ctx context.Context, c cols, typ reflect.Type, isPointer bool, opts mappingOptions) (func(*Row) (any, error), func(any) (T, error)) {
+	newRow := func() any {
+		if isPointer {
+			return reflect.New(typ.Elem()).Interface()
+		}
+
+		return new(T)
+	}
+
+	// MapValues can read the options of the mapper from the context
+	cfg := mappingConfig(ctx, opts)
+	ctx = context.WithValue(ctx, ctxKeyMappingConfig, cfg)
+	ctxKey := new(int)
+
+	// Use an empty row to find the columns that have a destination
+	probe := newRow().(mappable)
+
+	names := make([]string, 0, len(c))
+	keys := make([]string, 0, len(c))
+	var unknown []string
+	for _, name := range c {
+		key := strings.TrimPrefix(name, opts.structTagPrefix)
+		if !strings.HasPrefix(name, opts.structTagPrefix) || probe.MapValues(ctx, key) == nil {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		names = append(names, name)
+		keys = append(keys, key)
+	}
+
+	if opts.allowUnknown {
+		for _, name := range unknown {
+			opts.warnings.warn(WarningUnknownColumn, name, "", "Column %q has no field and is discarded", name)
+		}
+	}
+
+	return func(v *Row) (any, error) {
+			// The context of the run is used if the mapping is reused by other runs
+			rowCtx := ctx
+			if v.ctx != nil {
+				rowCtx = runState(v, ctxKey, func() context.Context {
+					return context.WithValue(v.ctx, ctxKeyMappingConfig, cfg)
+				})
+			}
+
+			row := newRow()
+			m := row.(mappable)
+			for i, key := range keys {
+				v.ScheduleScan(names[i], m.MapValues(rowCtx, key))
+			}
+
+			if opts.allowUnknown {
+				v.skipColumns(unknown)
+			}
+
+			return row, nil
+		}, func(v any) (T, error) {
+			if isPointer {
+				return v.(T), nil
+			}
+
+			return *(v.(*T)), nil
+		}
+}
+
+// ctxKeyMappingConfig holds the [MappingConfig] of the struct mapper that calls MapValues
+var ctxKeyMappingConfig contextKey = "mapping config"
+
+// MappingConfig is the configuration of a struct mapper for a single query,
+// with the options of the mapper and of the query merged.
+//
+// The context passed to a MapValues method is the context of the query, so it has
+// any values set by the caller, and the config can be read from it with [MappingConfigFrom].
+// This lets hand-written MapValues methods follow the same options as reflection
+type MappingConfig struct {
+	// StructTagPrefix is the prefix of the column names, see [WithStructTagPrefix].
+	// The keys passed to MapValues do not have it
+	StructTagPrefix string
+	// AllowUnknown is set if columns without a destination are discarded
+	AllowUnknown bool
+	// Mods are the [MapperMod]s applied to the mapper
+	Mods []MapperMod
+}
+
+// MappingConfigFrom returns the [MappingConfig] of the struct mapper
+// that is running MapValues with the context, if any
+func MappingConfigFrom(ctx context.Context) (MappingConfig, bool) {
+	cfg, ok := ctx.Value(ctxKeyMappingConfig).(MappingConfig)
+	return cfg, ok
+}
+
+func mappingConfig(ctx context.Context, opts mappingOptions) MappingConfig {
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+
+	return MappingConfig{
+		StructTagPrefix: opts.structTagPrefix,
+		AllowUnknown:    opts.allowUnknown || allowUnknown,
+		Mods:            opts.mapperMods,
+	}
+}
+
 type mappingOptions struct {
-	typeConverter   TypeConverter
-	rowValidator    RowValidator
-	mapperMods      []MapperMod
-	structTagPrefix string
+	typeConverter    TypeConverter
+	rowValidator     RowValidator
+	mapperMods       []MapperMod
+	structTagPrefix  string
+	allowUnknown     bool
+	enforceAllFields bool
+	nullHandling     NullHandling
+	timeLayouts      []string
+	jsonColumns      []string
+	skipMapValues    bool
+	columnMatcher    ColumnMatcher
+	mappers          []*Mappers
+	rowFactory       any
+	numericGuard     bool
+	numericReport    *NumericReport
+	encodings        []columnEncoding
+	sharedColumns    SharedColumns
+	boolValues       *BoolValues
+	maxDepth         *int
+	integerOverflow  *integerOverflow
+	polymorphic      *polymorphicMapping
+	columnNormalizer ColumnNormalizer
+	warnings         warner
+}
+
+// mapValuesSafe reports if the options can be applied with the MapValues method
+// of the type instead of reflection. MapValues gets the struct tag prefix,
+// whether unknown columns are allowed and the mapper mods from [MappingConfig],
+// unknown columns are warned about by the mapper, and registered mappers
+// and polymorphic mappings are handled before. Any other option needs reflection,
+// including the ones added later, unless they are cleared here
+func (o mappingOptions) mapValuesSafe() bool {
+	if o.skipMapValues {
+		return false
+	}
+
+	o.mapperMods = nil
+	o.structTagPrefix = ""
+	o.allowUnknown = false
+	o.warnings = nil
+	o.mappers = nil
+	o.polymorphic = nil
+
+	return reflect.ValueOf(o).IsZero()
 }
 
 // MappingeOption is a function type that changes how the mapper is generated
 type MappingOption func(*mappingOptions)
 
+// RowFactory creates the value that each row is scanned into.
+// Use it with [WithRowFactory]
+type RowFactory[T any] func() T
+
+// WithRowFactory makes the struct mapper create each row with the factory
+// instead of allocating a new one, e.g. to take objects from a pool or to start
+// from default values. If T is a pointer, the factory may return nil to allocate as usual.
+// Columns are scanned into the fields of the value returned by the factory.
+//
+// The factory must be for the same type as the mapper.
+//
+//	pool := sync.Pool{New: func() any { return new(User) }}
+//	m := scan.StructMapper[*User](scan.WithRowFactory(func() *User {
+//	    u := pool.Get().(*User)
+//	    *u = User{}
+//	    return u
+//	}))
+func WithRowFactory[T any](factory RowFactory[T]) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.rowFactory = factory
+	}
+}
+
 // WithRowValidator sets the [RowValidator] for the struct mapper
 // after scanning all values in a row, they are passed to the RowValidator
 // if it returns false, the zero value for that row is returned
@@ -107,7 +522,121 @@ func WithStructTagPrefix(prefix string) MappingOption {
 	}
 }
 
-// WithMapperMods accepts mods used to modify the mapper
+// WithAllowUnknownColumns makes the struct mapper ignore columns that
+// have no matching field, instead of returning an error.
+// This is the same as setting [CtxKeyAllowUnknownColumns] in the context
+func WithAllowUnknownColumns(allow bool) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.allowUnknown = allow
+	}
+}
+
+// WithEnforceAllFields makes the struct mapper return an error if a field
+// does not receive a column from the result set.
+// Fields of structs reached through a pointer are not enforced, since the pointer can be left nil.
+// This can also be set with [CtxKeyEnforceAllFields] in the context
+func WithEnforceAllFields(enforce bool) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.enforceAllFields = enforce
+	}
+}
+
+// NullHandling decides what the struct mapper does when a column is NULL
+// and its field cannot hold NULL. See [WithNullHandling]
+type NullHandling int
+
+const (
+	// NullDefault leaves NULL values to the driver, which
+	// usually returns an error or sets the zero value depending on the type
+	NullDefault NullHandling = iota
+	// NullError returns an error naming the column
+	NullError
+	// NullZero sets the field to its zero value
+	NullZero
+	// NullRequirePointer returns an error when building the mapper if any mapped field
+	// cannot hold NULL, so NULL values are always scanned into nil pointers
+	NullRequirePointer
+)
+
+// WithNullHandling sets how NULL values are handled for fields that cannot hold NULL.
+// A field can hold NULL if it is a pointer, interface, slice or map, or implements [sql.Scanner].
+// Fields decoded with a tag option such as json are always set to their zero value,
+// and the NullError and NullZero modes do not apply to fields scanned with a [TypeConverter]
+func WithNullHandling(mode NullHandling) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.nullHandling = mode
+	}
+}
+
+// WithTimeLayouts makes the struct mapper parse string and []byte values of [time.Time] fields
+// (and pointers to them) with the layouts, in order. See [time.Parse].
+// This is useful with drivers that return timestamps as text, such as MySQL without parseTime.
+// Values without a time zone are parsed as UTC, and NULL values set the field to its zero value
+func WithTimeLayouts(layouts ...string) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.timeLayouts = layouts
+	}
+}
+
+// WithJSONColumns makes the struct mapper unmarshal the values of the columns
+// into their fields with [json.Unmarshal], the same as the json tag option.
+// This is useful for types whose struct tags cannot be changed
+func WithJSONColumns(columns ...string) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.jsonColumns = columns
+	}
+}
+
+// ColumnMatcher reports if a column name matches the key of a field,
+// for columns that are not an exact match. The struct tag prefix has been removed from the column
+type ColumnMatcher func(column, key string) bool
+
+// MatchCaseInsensitive matches columns and keys that are equal ignoring case, e.g. UserID and userid
+func MatchCaseInsensitive(column, key string) bool {
+	return strings.EqualFold(column, key)
+}
+
+// MatchIgnoreUnderscores matches columns and keys that are equal ignoring case and underscores,
+// e.g. UserID and user_id
+func MatchIgnoreUnderscores(column, key string) bool {
+	return strings.EqualFold(strings.ReplaceAll(column, "_", ""), strings.ReplaceAll(key, "_", ""))
+}
+
+// WithColumnMatcher sets how columns are matched to fields when there is no exact match,
+// such as [MatchCaseInsensitive] or [MatchIgnoreUnderscores].
+// Each field is matched to one column at most, and exact matches are preferred.
+// Types with a MapValues method are mapped with reflection when a matcher is set
+func WithColumnMatcher(m ColumnMatcher) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.columnMatcher = m
+	}
+}
+
+// WithMaxDepth sets how deep the fields of recursive struct types are mapped.
+// A field whose type is already on its path is mapped at most n more times,
+// e.g. with n = 1 a Node with a Parent *Node is mapped down to parent.parent.id.
+// The default is 3, i.e. down to parent.parent.parent.parent.id.
+// If a column of the query belongs to a field that is deeper, an error naming
+// the recursive path is returned, unless unknown columns are allowed
+func WithMaxDepth(n int) MappingOption {
+	return func(opt *mappingOptions) {
+		if n < 0 {
+			n = 0
+		}
+		opt.maxDepth = &n
+	}
+}
+
+// WithoutMapValues makes the struct mapper use reflection even if the type
+// has a MapValues method. Use it with [WithMappingOptions] or [AllWithOptions]
+// for a query whose columns the MapValues method does not handle
+func WithoutMapValues() MappingOption {
+	return func(opt *mappingOptions) {
+		opt.skipMapValues = true
+	}
+}
+
+// WithMapperMods accepts mods used to modify the mapper. See [Mod]
 func WithMapperMods(mods ...MapperMod) MappingOption {
 	return func(opt *mappingOptions) {
 		opt.mapperMods = append(opt.mapperMods, mods...)
@@ -115,19 +644,135 @@ func WithMapperMods(mods ...MapperMod) MappingOption {
 }
 
 func mapperFromMapping[T any](m mapping, typ reflect.Type, isPointer bool, opts mappingOptions) func(context.Context, cols) (func(*Row) (any, error), func(any) (T, error)) {
+	if len(opts.jsonColumns) > 0 {
+		m = m.withJSONColumns(opts.jsonColumns)
+	}
+
 	return func(ctx context.Context, c cols) (func(*Row) (any, error), func(any) (T, error)) {
+		remain, hasRemain := m.remainField()
+		m := m.withContainers(opts.typeConverter)
+
 		// Filter the mapping so we only ask for the available columns
-		filtered, err := filterColumns(ctx, c, m, opts.structTagPrefix)
+		filtered, err := filterColumns(ctx, c, m, opts.structTagPrefix, opts.columnMatcher, opts.columnNormalizer)
 		if err != nil {
 			return ErrorMapper[T](err)
 		}
 
+		if opts.sharedColumns == SharedColumnsFirst && opts.warnings != nil {
+			warnShadowed(filtered, m, typ, isPointer, opts)
+		}
+
+		if opts.sharedColumns != SharedColumnsFirst {
+			var shared mapping
+			if filtered, shared = withSharedColumns(filtered, m, opts.structTagPrefix, opts.sharedColumns); shared != nil {
+				names := fieldPaths(shared, typ, isPointer)
+				err := fmt.Errorf("Column %q is mapped to several fields: %v", shared[0].name, names)
+				return ErrorMapper[T](err, append([]string{"shared column", shared[0].name}, names...)...)
+			}
+		}
+
+		// The fields without a column that have a default value are decoded the same way
+		defaults := absentDefaults(m, filtered)
+		decoded := append(filtered[:len(filtered):len(filtered)], defaults...)
+
+		if len(opts.timeLayouts) > 0 {
+			withTimeLayouts(decoded, opts.timeLayouts)
+		}
+
+		if opts.numericGuard {
+			withNumericGuard(decoded, opts.numericReport, opts.warnings)
+		}
+
+		if opts.integerOverflow != nil {
+			withIntegerOverflow(decoded, *opts.integerOverflow, opts.warnings)
+		}
+
+		if len(opts.encodings) > 0 {
+			withEncodings(decoded, opts.encodings)
+		}
+
+		if opts.boolValues != nil {
+			withBoolValues(decoded, *opts.boolValues)
+		}
+
+		withDefaults(decoded)
+		filtered, defaults = decoded[:len(filtered)], decoded[len(filtered):]
+		if err := checkDefaults(decoded, typ, isPointer); err != nil {
+			return ErrorMapper[T](err)
+		}
+
+		if opts.enforceAllFields {
+			if missing := missingFields(m, filtered); len(missing) > 0 {
+				err := fmt.Errorf("No column for fields: %v", missing)
+				return ErrorMapper[T](err, append([]string{"missing fields"}, missing...)...)
+			}
+		}
+
+		var unknown, remaining []string
+		switch {
+		case hasRemain:
+			remaining = unknownColumns(c, filtered)
+		case opts.allowUnknown:
+			unknown = unknownColumns(c, filtered)
+			for _, name := range unknown {
+				opts.warnings.warn(WarningUnknownColumn, name, "", "Column %q has no field and is discarded", name)
+			}
+		}
+
+		var nulls []bool
+		if opts.nullHandling != NullDefault {
+			nulls = nonNullable(filtered, typ, isPointer)
+		}
+
+		if opts.nullHandling == NullRequirePointer {
+			var names []string
+			for i, info := range filtered {
+				if nulls[i] {
+					names = append(names, info.name)
+				}
+			}
+
+			if len(names) > 0 {
+				err := fmt.Errorf("Fields cannot hold NULL: %v", names)
+				return ErrorMapper[T](err, append([]string{"non-nullable fields"}, names...)...)
+			}
+
+			nulls = nil
+		}
+
+		converter := opts.typeConverter
+		if cc, ok := converter.(contextConverter); ok {
+			converter = cc.withContext(ctx)
+		}
+
+		var factory RowFactory[T]
+		if opts.rowFactory != nil {
+			var ok bool
+			if factory, ok = opts.rowFactory.(RowFactory[T]); !ok {
+				err := fmt.Errorf("row factory of type %T cannot create %s", opts.rowFactory, typ)
+				return ErrorMapper[T](err, "row factory", typ.String())
+			}
+		}
+
 		mapper := regular[T]{
+			factory:   factory,
 			typ:       typ,
 			isPointer: isPointer,
 			filtered:  filtered,
-			converter: opts.typeConverter,
+			fields:    fieldPaths(filtered, typ, isPointer),
+			indexes:   columnIndexes(c, filtered),
+			unknown:   unknown,
+			remaining: remaining,
+			converter: converter,
 			validator: opts.rowValidator,
+			nulls:     nulls,
+			nullZero:  opts.nullHandling == NullZero,
+			shared:    opts.sharedColumns == SharedColumnsFanOut,
+			defaults:  defaults,
+		}
+		if hasRemain {
+			mapper.remain = &remain
+			mapper.remainField = fieldPaths(mapping{remain}, typ, isPointer)[0]
 		}
 		switch {
 		case opts.typeConverter == nil && opts.rowValidator == nil:
@@ -142,23 +787,54 @@ func mapperFromMapping[T any](m mapping, typ reflect.Type, isPointer bool, opts
 type regular[T any] struct {
 	isPointer bool
 	typ       reflect.Type
+	factory   RowFactory[T]
 	filtered  mapping
+	fields    []string
+	unknown   []string
 	converter TypeConverter
+
+	// indexes are the positions of the columns of the fields,
+	// so that they do not have to be searched for on every row
+	indexes []int
+
+	// remain is the field that receives the remaining columns, if any
+	remain      *mapinfo
+	remainField string
+	remaining   []string
+
 	validator RowValidator
+
+	// nulls marks the fields that cannot hold NULL and are scanned
+	// through a pointer so that NULL values can be handled
+	nulls    []bool
+	nullZero bool
+
+	// shared is set if several fields may be scanned from the same column
+	shared bool
+
+	// defaults are the fields with a default value that have no column
+	defaults mapping
+}
+
+// regularRow is the link between the before and after functions of regular()
+type regularRow struct {
+	row    reflect.Value
+	nulls  []reflect.Value
+	remain []reflect.Value
 }
 
 func (s regular[T]) regular() (func(*Row) (any, error), func(any) (T, error)) {
 	return func(v *Row) (any, error) {
-			var row reflect.Value
-			if s.isPointer {
-				row = reflect.New(s.typ.Elem()).Elem()
-			} else {
-				row = reflect.New(s.typ).Elem()
+			row := s.newRow()
+
+			var nulls []reflect.Value
+			if s.nulls != nil {
+				nulls = make([]reflect.Value, len(s.filtered))
 			}
 
-			for _, info := range s.filtered {
+			for i, info := range s.filtered {
 				for _, v := range info.init {
-					pv := row.FieldByIndex(v)
+					pv := fieldOf(row, v)
 					if !pv.IsZero() {
 						continue
 					}
@@ -166,20 +842,121 @@ func (s regular[T]) regular() (func(*Row) (any, error), func(any) (T, error)) {
 					pv.Set(reflect.New(pv.Type().Elem()))
 				}
 
-				fv := row.FieldByIndex(info.position)
-				v.ScheduleScanx(info.name, fv.Addr())
+				fv := fieldOf(row, info.position)
+				if s.nulls != nil && s.nulls[i] {
+					nulls[i] = reflect.New(reflect.PtrTo(fv.Type()))
+					s.schedule(v, i, nulls[i])
+					continue
+				}
+
+				s.schedule(v, i, info.scanDest(fv.Addr()))
 			}
 
-			return row, nil
+			for _, info := range s.defaults {
+				if err := setDefault(row, info); err != nil {
+					return nil, err
+				}
+			}
+
+			v.skipColumns(s.unknown)
+
+			remain := s.scheduleRemaining(v)
+			if nulls == nil && remain == nil {
+				// The pointer to the row is the link, so that no link has to be allocated
+				return row.Addr().Interface(), nil
+			}
+
+			return regularRow{row: row, nulls: nulls, remain: remain}, nil
 		}, func(v any) (T, error) {
-			row := v.(reflect.Value)
+			r, ok := v.(regularRow)
+			if !ok {
+				return s.fromPointer(v), nil
+			}
+			row := r.row
 
-			if s.isPointer {
-				row = row.Addr()
+			for i, ptr := range r.nulls {
+				if !ptr.IsValid() {
+					continue
+				}
+
+				val, err := s.nullValue(i, ptr.Elem())
+				if err != nil {
+					var t T
+					return t, err
+				}
+
+				fieldOf(row, s.filtered[i].position).Set(val)
 			}
 
-			return row.Interface().(T), nil
+			s.setRemaining(row, r.remain)
+
+			return s.fromPointer(row.Addr().Interface()), nil
+		}
+}
+
+// schedule schedules the scan of the ith field of the mapping
+func (s regular[T]) schedule(v *Row, i int, val reflect.Value) {
+	if s.shared {
+		v.shareIndex(s.indexes[i], s.filtered[i].name, s.fields[i], val)
+		return
+	}
+
+	v.scheduleIndex(s.indexes[i], s.filtered[i].name, s.fields[i], val)
+}
+
+// newRow returns the addressable row that the columns are scanned into,
+// created with the row factory if there is one
+func (s regular[T]) newRow() reflect.Value {
+	if s.factory == nil {
+		if s.isPointer {
+			return reflect.New(s.typ.Elem()).Elem()
 		}
+		return reflect.New(s.typ).Elem()
+	}
+
+	if !s.isPointer {
+		row := new(T)
+		*row = s.factory()
+		return reflect.ValueOf(row).Elem()
+	}
+
+	row := reflect.ValueOf(s.factory())
+	if row.IsNil() {
+		return reflect.New(s.typ.Elem()).Elem()
+	}
+
+	return row.Elem()
+}
+
+// fromPointer returns T from a pointer to the row.
+// Unlike [reflect.Value.Interface] on the row itself, it does not allocate a copy
+func (s regular[T]) fromPointer(ptr any) T {
+	if s.isPointer {
+		return ptr.(T)
+	}
+
+	if row, ok := ptr.(*T); ok {
+		return *row
+	}
+
+	// T is an interface of the row, with polymorphic mapping
+	return reflect.ValueOf(ptr).Elem().Interface().(T)
+}
+
+// nullValue returns the value of a field that was scanned through a pointer,
+// which is nil if the column was NULL
+func (s regular[T]) nullValue(i int, ptr reflect.Value) (reflect.Value, error) {
+	if !ptr.IsNil() {
+		return ptr.Elem(), nil
+	}
+
+	if s.nullZero {
+		return reflect.Zero(ptr.Type().Elem()), nil
+	}
+
+	name := s.filtered[i].name
+	err := fmt.Errorf("NULL value in column %q for a field of type %s", name, ptr.Type().Elem())
+	return reflect.Value{}, columnError(ErrConversion, name, s.fields[i], err, "null value", name)
 }
 
 func (s regular[T]) allOptions() (func(*Row) (any, error), func(any) (T, error)) {
@@ -194,34 +971,40 @@ func (s regular[T]) allOptions() (func(*Row) (any, error), func(any) (T, error))
 					ft = s.typ.FieldByIndex(info.position).Type
 				}
 
-				if s.converter != nil {
+				switch {
+				case info.decode != nil:
+					row[i] = reflect.New(ft)
+					s.schedule(v, i, info.scanDest(row[i]))
+					continue
+				case s.converter != nil:
 					row[i] = s.converter.TypeToDestination(ft)
-				} else {
+				case s.nulls != nil && s.nulls[i]:
+					row[i] = reflect.New(reflect.PtrTo(ft))
+				default:
 					row[i] = reflect.New(ft)
 				}
 
-				v.ScheduleScanx(info.name, row[i])
+				s.schedule(v, i, row[i])
 			}
 
-			return row, nil
+			v.skipColumns(s.unknown)
+
+			// The remaining columns are scanned after the mapped fields
+			return append(row, s.scheduleRemaining(v)...), nil
 		}, func(v any) (T, error) {
 			vals := v.([]reflect.Value)
+			vals, remain := vals[:len(s.filtered)], vals[len(s.filtered):]
 
 			if s.validator != nil && !s.validator(s.filtered.cols(), vals) {
 				var t T
 				return t, nil
 			}
 
-			var row reflect.Value
-			if s.isPointer {
-				row = reflect.New(s.typ.Elem()).Elem()
-			} else {
-				row = reflect.New(s.typ).Elem()
-			}
+			row := s.newRow()
 
 			for i, info := range s.filtered {
 				for _, v := range info.init {
-					pv := row.FieldByIndex(v)
+					pv := fieldOf(row, v)
 					if !pv.IsZero() {
 						continue
 					}
@@ -230,20 +1013,35 @@ func (s regular[T]) allOptions() (func(*Row) (any, error), func(any) (T, error))
 				}
 
 				var val reflect.Value
-				if s.converter != nil {
+				switch {
+				case s.converter != nil && info.decode == nil:
 					val = s.converter.ValueFromDestination(vals[i])
-				} else {
+				case s.nulls != nil && s.nulls[i]:
+					var err error
+					if val, err = s.nullValue(i, vals[i].Elem()); err != nil {
+						var t T
+						return t, err
+					}
+				default:
 					val = vals[i].Elem()
 				}
 
-				fv := row.FieldByIndex(info.position)
-				if info.isPointer {
-					fv.Elem().Set(val)
-				} else {
-					fv.Set(val)
+				fv := fieldOf(row, info.position)
+				if err := setValue(fv, val); err != nil {
+					var t T
+					return t, columnError(ErrConversion, info.name, s.fields[i], err, "assign", info.name)
 				}
 			}
 
+			for _, info := range s.defaults {
+				if err := setDefault(row, info); err != nil {
+					var t T
+					return t, err
+				}
+			}
+
+			s.setRemaining(row, remain)
+
 			if s.isPointer {
 				row = row.Addr()
 			}
@@ -251,3 +1049,172 @@ func (s regular[T]) allOptions() (func(*Row) (any, error), func(any) (T, error))
 			return row.Interface().(T), nil
 		}
 }
+
+// columnIndexes returns the position of the column of each field of the mapping,
+// or -1 if the column is not found
+func columnIndexes(c cols, m mapping) []int {
+	indexes := make([]int, len(m))
+	for i, info := range m {
+		indexes[i] = -1
+		for j, name := range c {
+			if name == info.name {
+				indexes[i] = j
+				break
+			}
+		}
+	}
+
+	return indexes
+}
+
+// scheduleRemaining schedules the columns that are not mapped to other fields
+// to be scanned for the remain field, if any
+func (s regular[T]) scheduleRemaining(v *Row) []reflect.Value {
+	if s.remain == nil || len(s.remaining) == 0 {
+		return nil
+	}
+
+	vals := make([]reflect.Value, len(s.remaining))
+	for i, name := range s.remaining {
+		vals[i] = reflect.New(s.remain.typ.Elem())
+		v.scheduleField(name, s.remainField, vals[i])
+	}
+
+	return vals
+}
+
+// setRemaining sets the values of the remaining columns to the remain field.
+// The field is left nil if there are no remaining columns
+func (s regular[T]) setRemaining(row reflect.Value, vals []reflect.Value) {
+	if s.remain == nil || len(vals) == 0 {
+		return
+	}
+
+	for _, v := range s.remain.init {
+		pv := fieldOf(row, v)
+		if pv.IsZero() {
+			pv.Set(reflect.New(pv.Type().Elem()))
+		}
+	}
+
+	keyType := s.remain.typ.Key()
+	m := reflect.MakeMapWithSize(s.remain.typ, len(vals))
+	for i, val := range vals {
+		m.SetMapIndex(reflect.ValueOf(s.remaining[i]).Convert(keyType), val.Elem())
+	}
+
+	fieldOf(row, s.remain.position).Set(m)
+}
+
+// fieldPaths returns the path of the struct field of each entry in the mapping e.g. Address.Street
+func fieldPaths(m mapping, typ reflect.Type, isPointer bool) []string {
+	if isPointer {
+		typ = typ.Elem()
+	}
+
+	paths := make([]string, len(m))
+	for i, info := range m {
+		names := make([]string, len(info.position))
+		t := typ
+		for j, index := range info.position {
+			if t.Kind() == reflect.Pointer {
+				t = t.Elem()
+			}
+			f := t.Field(index)
+			names[j] = f.Name
+			t = f.Type
+		}
+
+		paths[i] = strings.Join(names, ".")
+	}
+
+	return paths
+}
+
+// nonNullable marks the fields of the mapping that cannot hold NULL
+func nonNullable(m mapping, typ reflect.Type, isPointer bool) []bool {
+	if isPointer {
+		typ = typ.Elem()
+	}
+
+	nulls := make([]bool, len(m))
+	for i, info := range m {
+		// Decoded fields are zeroed by the decoder, and containers are scanned by the converter
+		if info.decode != nil || info.container {
+			continue
+		}
+
+		nulls[i] = !canHoldNull(typ.FieldByIndex(info.position).Type)
+	}
+
+	return nulls
+}
+
+// canHoldNull reports if a value of the type can be scanned from NULL
+func canHoldNull(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	}
+
+	return reflect.PtrTo(typ).Implements(scannerType)
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// unknownColumns returns the columns that are not in the mapping
+func unknownColumns(c cols, m mapping) []string {
+	known := make(map[string]bool, len(m))
+	for _, info := range m {
+		known[info.name] = true
+	}
+
+	var unknown []string
+	for _, name := range c {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	return unknown
+}
+
+// missingFields returns the keys of the required fields
+// in the mapping that are not in the filtered mapping
+func missingFields(m, filtered mapping) []string {
+	found := make(map[string]bool, len(filtered))
+	var converted [][]int
+	for _, info := range filtered {
+		found[fmt.Sprint(info.position)] = true
+		if info.container {
+			converted = append(converted, info.position)
+		}
+	}
+
+	var missing []string
+	for _, info := range m {
+		if info.optional || info.def != nil || found[fmt.Sprint(info.position)] {
+			continue
+		}
+
+		// Fields of a struct that was scanned as a single value
+		if hasAncestor(info.position, converted) {
+			continue
+		}
+
+		missing = append(missing, info.name)
+	}
+
+	return missing
+}
+
+// hasAncestor reports if any of the ancestors is a prefix of the position
+func hasAncestor(position []int, ancestors [][]int) bool {
+	for _, a := range ancestors {
+		if len(a) < len(position) && fmt.Sprint(a) == fmt.Sprint(position[:len(a)]) {
+			return true
+		}
+	}
+
+	return false
+}
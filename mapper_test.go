@@ -2,6 +2,8 @@ package scan
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
@@ -189,6 +191,86 @@ func TestMapMapper(t *testing.T) {
 		Mapper:      MapMapper[any],
 		ExpectedVal: mapToVals[any](goodSlice),
 	})
+
+	RunMapperTest(t, "StructMapper with map", MapperTest[map[string]any]{
+		row: &Row{
+			columns: columns(len(goodSlice)),
+		},
+		scanned:     goodSlice,
+		Mapper:      StructMapper[map[string]any](),
+		ExpectedVal: mapToVals[any](goodSlice),
+	})
+
+	type key string
+	RunMapperTest(t, "StructMapper with typed map", MapperTest[map[key]int]{
+		row: &Row{
+			columns: columnNames("a", "b"),
+		},
+		scanned:     []any{1, 2},
+		Mapper:      StructMapper[map[key]int](),
+		ExpectedVal: map[key]int{"a": 1, "b": 2},
+	})
+
+	RunMapperTest(t, "StructMapper with int keys", MapperTest[map[int]float64]{
+		row: &Row{
+			columns: columnNames("2022", "2023"),
+		},
+		scanned:     []any{1.5, 2.5},
+		Mapper:      StructMapper[map[int]float64](),
+		ExpectedVal: map[int]float64{2022: 1.5, 2023: 2.5},
+	})
+
+	RunMapperTest(t, "StructMapper with text keys", MapperTest[map[textKey]string]{
+		row: &Row{
+			columns: columnNames("color", "size"),
+		},
+		scanned:     []any{"red", "xl"},
+		Mapper:      StructMapper[map[textKey]string](),
+		ExpectedVal: map[textKey]string{"COLOR": "red", "SIZE": "xl"},
+	})
+
+	m := StructMapper[map[uint8]int]()
+	_, after := m(context.Background(), columnNames("1", "300"))
+	if _, err := after(nil); !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected a conversion error for an invalid key, got %v", err)
+	}
+}
+
+// textKey is a map key that is unmarshaled from the column name
+type textKey string
+
+func (k *textKey) UnmarshalText(text []byte) error {
+	*k = textKey(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestArrayMapper(t *testing.T) {
+	RunMapperTest(t, "ArrayMapper", MapperTest[[2]int]{
+		row: &Row{
+			columns: columnNames("lo", "hi"),
+		},
+		scanned:     []any{1, 10},
+		Mapper:      ArrayMapper[[2]int],
+		ExpectedVal: [2]int{1, 10},
+	})
+
+	RunMapperTest(t, "ArrayMapper wrong column count", MapperTest[[2]int]{
+		row: &Row{
+			columns: columnNames("lo", "mid", "hi"),
+		},
+		Mapper:              ArrayMapper[[2]int],
+		ExpectedBeforeError: createError(nil, "wrong column count", "2", "3"),
+		ExpectedAfterError:  createError(nil, "wrong column count", "2", "3"),
+	})
+
+	RunMapperTest(t, "StructArrayMapper", MapperTest[[]User]{
+		row: &Row{
+			columns: columnNames("a.id", "a.name", "b.id", "b.name"),
+		},
+		scanned:     []any{1, "foo", 2, "bar"},
+		Mapper:      StructArrayMapper[User]("a.", "b."),
+		ExpectedVal: []User{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}},
+	})
 }
 
 func TestStructMapper(t *testing.T) {
@@ -222,6 +304,15 @@ func TestStructMapper(t *testing.T) {
 		},
 	})
 
+	RunMapperTest(t, "with pointer columns not selected", MapperTest[PtrUser1]{
+		row: &Row{
+			columns: columnNames("name"),
+		},
+		scanned:     []any{"The Name"},
+		Mapper:      StructMapper[PtrUser1](),
+		ExpectedVal: PtrUser1{Name: "The Name"},
+	})
+
 	RunMapperTest(t, "with pointer columns 2", MapperTest[PtrUser2]{
 		row: &Row{
 			columns: columnNames("id", "name", "created_at", "updated_at"),
@@ -270,6 +361,18 @@ func TestStructMapper(t *testing.T) {
 		ExpectedVal: Tagged{ID: 1, Name: "The Name", Email: "user@example.com"},
 	})
 
+	RunMapperTest(t, "embedded with prefix tag", MapperTest[UserWithAddress]{
+		row: &Row{
+			columns: columnNames("id", "name", "addr.street", "addr.city"),
+		},
+		scanned: []any{1, "The Name", "1 Main St", "Springfield"},
+		Mapper:  StructMapper[UserWithAddress](),
+		ExpectedVal: UserWithAddress{
+			User:    User{ID: 1, Name: "The Name"},
+			Address: &Address{Street: "1 Main St", City: "Springfield"},
+		},
+	})
+
 	RunCustomStructMapperTest(t, "custom column separator", CustomStructMapperTest[Blog]{
 		MapperTest: MapperTest[Blog]{
 			row: &Row{
@@ -315,6 +418,21 @@ func TestStructMapper(t *testing.T) {
 		Options: []MappingSourceOption{WithStructTagKey("custom")},
 	})
 
+	RunCustomStructMapperTest(t, "fallback tag keys", CustomStructMapperTest[Tagged]{
+		MapperTest: MapperTest[Tagged]{
+			row: &Row{
+				columns: columnNames("custom_id", "custom_name", "EMAIL"),
+			},
+			scanned:     []any{1, "The Name", "a@b.c"},
+			ExpectedVal: Tagged{ID: 1, Name: "The Name", Email: "a@b.c"},
+		},
+		Options: []MappingSourceOption{WithStructTagKeys("custom", "db")},
+	})
+
+	if _, err := NewStructMapperSource(WithStructTagKeys()); err == nil {
+		t.Fatal("expected an error without tag keys")
+	}
+
 	RunMapperTest(t, "with prefix", MapperTest[User]{
 		row: &Row{
 			columns: columnNames("prefix--id", "prefix--name"),
@@ -410,6 +528,352 @@ func TestStructMapper(t *testing.T) {
 		Mapper:      CustomStructMapper[*User](defaultStructMapper, WithMapperMods(userMod)),
 		ExpectedVal: &User{ID: 400, Name: "The Name modified"},
 	})
+
+	RunMapperTest(t, "with context mods", MapperTest[*User]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		Context:     map[contextKey]any{CtxKeyMapperMods: []MapperMod{userMod}},
+		scanned:     []any{2, "The Name"},
+		Mapper:      StructMapper[*User](),
+		ExpectedVal: &User{ID: 400, Name: "The Name modified"},
+	})
+
+	fillName := AfterRow(func(ctx context.Context, u *User) error {
+		if u.Name == "" {
+			u.Name = "Anonymous"
+		}
+		return nil
+	})
+
+	RunMapperTest(t, "with after row hook", MapperTest[*User]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		scanned:     []any{2, ""},
+		Mapper:      StructMapper[*User](WithMapperMods(fillName)),
+		ExpectedVal: &User{ID: 2, Name: "Anonymous"},
+	})
+
+	RunMapperTest(t, "with null zero", MapperTest[User]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		scanned:     []any{toPtr(1), (*string)(nil)},
+		Mapper:      StructMapper[User](WithNullHandling(NullZero)),
+		ExpectedVal: User{ID: 1},
+	})
+
+	RunMapperTest(t, "with null error", MapperTest[User]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		scanned:            []any{toPtr(1), (*string)(nil)},
+		Mapper:             StructMapper[User](WithNullHandling(NullError)),
+		ExpectedAfterError: createError(nil, "null value", "name"),
+	})
+
+	RunMapperTest(t, "with null error and row validator", MapperTest[User]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		scanned: []any{toPtr(1), toPtr("The Name")},
+		Mapper: StructMapper[User](WithNullHandling(NullError), WithRowValidator(func([]string, []reflect.Value) bool {
+			return true
+		})),
+		ExpectedVal: User{ID: 1, Name: "The Name"},
+	})
+
+	RunMapperTest(t, "with null handling and nullable fields", MapperTest[PtrUser2]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		scanned:     []any{toPtr(1), (*string)(nil)},
+		Mapper:      StructMapper[PtrUser2](WithNullHandling(NullError)),
+		ExpectedVal: PtrUser2{ID: 1},
+	})
+
+	RunMapperTest(t, "with null require pointer", MapperTest[PtrUser2]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		Mapper:              StructMapper[PtrUser2](WithNullHandling(NullRequirePointer)),
+		ExpectedBeforeError: createError(nil, "non-nullable fields", "id"),
+		ExpectedAfterError:  createError(nil, "non-nullable fields", "id"),
+	})
+}
+
+func TestRowHooks(t *testing.T) {
+	ctx := context.Background()
+	cols := []string{"id", "name"}
+
+	var seen []string
+	before := BeforeRow(func(ctx context.Context, r *Row) error {
+		seen = append(seen, "before")
+		return nil
+	})
+	after := AfterRow(func(ctx context.Context, u User) error {
+		seen = append(seen, u.Name)
+		return nil
+	})
+
+	_, err := AllFromRows(ctx, StructMapper[User](WithMapperMods(before, after)), newSliceRows(cols,
+		[]any{1, "foo"},
+		[]any{2, "bar"},
+	))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"before", "foo", "before", "bar"}, seen); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	errRejected := errors.New("rejected")
+	reject := BeforeRow(func(ctx context.Context, r *Row) error {
+		return errRejected
+	})
+	_, err = AllFromRows(ctx, StructMapper[User](WithMapperMods(reject)), newSliceRows(cols, []any{1, "foo"}))
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("expected the hook error, got %v", err)
+	}
+
+	_, err = AllFromRows(ctx, StructMapper[*User](WithMapperMods(after)), newSliceRows(cols, []any{1, "foo"}))
+	if err == nil || err.Error() != "AfterRow expected scan.User, got *scan.User" {
+		t.Fatalf("expected a type error, got %v", err)
+	}
+}
+
+func TestMappableStructMapper(t *testing.T) {
+	RunMapperTest(t, "mappable", MapperTest[MappableUser]{
+		row: &Row{
+			columns: columnNames("identifier", "name"),
+		},
+		scanned:     []any{1, "The Name"},
+		Mapper:      StructMapper[MappableUser](),
+		ExpectedVal: MappableUser{ID: 1, Name: "The Name"},
+	})
+
+	RunMapperTest(t, "mappable ptr with prefix", MapperTest[*MappableUser]{
+		row: &Row{
+			columns: columnNames("prefix--identifier", "prefix--name"),
+		},
+		scanned:     []any{1, "The Name"},
+		Mapper:      StructMapper[*MappableUser](WithStructTagPrefix("prefix--")),
+		ExpectedVal: &MappableUser{ID: 1, Name: "The Name"},
+	})
+
+	RunMapperTest(t, "mappable with type converter uses reflection", MapperTest[MappableUser]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		scanned:     []any{wrapper{toPtr(1)}, wrapper{toPtr("The Name")}},
+		Mapper:      StructMapper[MappableUser](WithTypeConverter(typeConverter{})),
+		ExpectedVal: MappableUser{ID: 1, Name: "The Name"},
+	})
+
+	RunMapperTest(t, "mappable without MapValues uses reflection", MapperTest[MappableUser]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		scanned:     []any{1, "The Name"},
+		Mapper:      StructMapper[MappableUser](WithoutMapValues()),
+		ExpectedVal: MappableUser{ID: 1, Name: "The Name"},
+	})
+}
+
+func TestMapValuesSafe(t *testing.T) {
+	apply := func(opts ...MappingOption) mappingOptions {
+		var o mappingOptions
+		for _, opt := range opts {
+			opt(&o)
+		}
+		return o
+	}
+
+	safe := [][]MappingOption{
+		nil,
+		{WithStructTagPrefix("user.")},
+		{WithAllowUnknownColumns(true)},
+		{WithMapperMods(BeforeRow(func(ctx context.Context, r *Row) error { return nil }))},
+		{WithWarnings(func(Warning) {})},
+		{WithMappers(NewMappers())},
+	}
+	for i, opts := range safe {
+		if !apply(opts...).mapValuesSafe() {
+			t.Errorf("expected options %d to be applied with MapValues", i)
+		}
+	}
+
+	unsafe := map[string]MappingOption{
+		"without MapValues": WithoutMapValues(),
+		"type converter":    WithTypeConverter(typeConverter{}),
+		"null handling":     WithNullHandling(NullZero),
+		"time layouts":      WithTimeLayouts("2006"),
+		"max depth":         WithMaxDepth(1),
+		"shared columns":    WithSharedColumns(SharedColumnsFanOut),
+		"numeric guard":     WithNumericGuard(nil),
+	}
+	for name, opt := range unsafe {
+		if apply(opt).mapValuesSafe() {
+			t.Errorf("expected %s to need reflection", name)
+		}
+	}
+}
+
+// configUser reads the mapping config and a custom context value in MapValues
+type configUser struct {
+	ID     int
+	Name   string
+	Prefix string
+}
+
+var ctxKeyNameColumn contextKey = "name column"
+
+func (u *configUser) MapValues(ctx context.Context, key string) any {
+	if cfg, ok := MappingConfigFrom(ctx); ok {
+		u.Prefix = cfg.StructTagPrefix
+	}
+
+	nameColumn, _ := ctx.Value(ctxKeyNameColumn).(string)
+
+	switch key {
+	case "id":
+		return &u.ID
+	case nameColumn:
+		return &u.Name
+	}
+
+	return nil
+}
+
+func TestMappingConfig(t *testing.T) {
+	RunMapperTest(t, "config and custom keys", MapperTest[configUser]{
+		row: &Row{
+			columns: columnNames("user.id", "user.full_name"),
+		},
+		Context:     map[contextKey]any{ctxKeyNameColumn: "full_name"},
+		scanned:     []any{1, "The Name"},
+		Mapper:      StructMapper[configUser](WithStructTagPrefix("user.")),
+		ExpectedVal: configUser{ID: 1, Name: "The Name", Prefix: "user."},
+	})
+
+	if _, ok := MappingConfigFrom(context.Background()); ok {
+		t.Fatal("expected no config outside of a mapper")
+	}
+}
+
+func TestUnexportedFields(t *testing.T) {
+	src, err := NewStructMapperSource(WithUnexportedFields((*Account)(nil)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := newSliceRows([]string{"id", "balance", "creator"}, []any{1, 100, "admin"})
+	accounts, err := AllFromRows(context.Background(), CustomStructMapper[Account](src), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(accounts) != 1 {
+		t.Fatalf("expected 1 account, got %d", len(accounts))
+	}
+
+	a := accounts[0]
+	if a.ID != 1 || a.Balance() != 100 || a.CreatedBy() != "admin" {
+		t.Fatalf("wrong account: %d %d %s", a.ID, a.Balance(), a.CreatedBy())
+	}
+
+	clause, args, err := CustomWhere(src, a)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if clause != "WHERE id = ? AND balance = ? AND creator = ?" || len(args) != 3 {
+		t.Fatalf("wrong clause: %s %v", clause, args)
+	}
+
+	// Unexported fields are ignored by default
+	rows = newSliceRows([]string{"id", "balance"}, []any{1, 100})
+	_, err = AllFromRows(context.Background(), StructMapper[Account](), rows)
+	if err == nil {
+		t.Fatal("expected an error for the unexported column")
+	}
+
+	_, err = NewStructMapperSource(WithUnexportedFields(1))
+	if err == nil {
+		t.Fatal("expected an error for a non-struct type")
+	}
+}
+
+func TestStructMapperPrimitives(t *testing.T) {
+	RunMapperTest(t, "int64", MapperTest[int64]{
+		row: &Row{
+			columns: columnNames("id"),
+		},
+		scanned:     []any{int64(5)},
+		Mapper:      StructMapper[int64](),
+		ExpectedVal: 5,
+	})
+
+	RunMapperTest(t, "pointer to string", MapperTest[*string]{
+		row: &Row{
+			columns: columnNames("name"),
+		},
+		scanned:     []any{toPtr("gopher")},
+		Mapper:      StructMapper[*string](),
+		ExpectedVal: toPtr("gopher"),
+	})
+
+	RunMapperTest(t, "time", MapperTest[time.Time]{
+		row: &Row{
+			columns: columnNames("created_at"),
+		},
+		scanned:     []any{now},
+		Mapper:      StructMapper[time.Time](),
+		ExpectedVal: now,
+	})
+
+	RunMapperTest(t, "scanner", MapperTest[sql.NullString]{
+		row: &Row{
+			columns: columnNames("name"),
+		},
+		scanned:     []any{sql.NullString{String: "gopher", Valid: true}},
+		Mapper:      StructMapper[sql.NullString](),
+		ExpectedVal: sql.NullString{String: "gopher", Valid: true},
+	})
+
+	RunMapperTest(t, "too many columns", MapperTest[int64]{
+		row: &Row{
+			columns: columnNames("id", "name"),
+		},
+		Mapper:              StructMapper[int64](),
+		ExpectedBeforeError: createError(nil, "wrong column count", "1", "2"),
+		ExpectedAfterError:  createError(nil, "wrong column count", "1", "2"),
+	})
+}
+
+func TestStructMapperNotScannable(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := AllFromRows(ctx, StructMapper[[]int](), newSliceRows([]string{"ids"}, []any{1}))
+	if err == nil || !strings.Contains(err.Error(), "is not a struct") {
+		t.Fatalf("expected a not a struct error for []int, got %v", err)
+	}
+
+	_, err = AllFromRows(ctx, StructMapper[any](), newSliceRows([]string{"id"}, []any{1}))
+	if err == nil || !strings.Contains(err.Error(), "is not a struct") {
+		t.Fatalf("expected a not a struct error for an interface, got %v", err)
+	}
+
+	bytes, err := AllFromRows(ctx, StructMapper[[]byte](), newSliceRows([]string{"data"}, []any{[]byte("a")}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([][]byte{[]byte("a")}, bytes); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
 }
 
 func TestScannable(t *testing.T) {
@@ -474,3 +938,259 @@ func TestScannableErrors(t *testing.T) {
 		})
 	}
 }
+
+func TestRemainColumns(t *testing.T) {
+	type userWithExtra struct {
+		ID    int
+		Name  string
+		Extra map[string]any `db:",remain"`
+	}
+
+	rows := newSliceRows([]string{"id", "name", "age", "country"},
+		[]any{1, "foo", int64(30), "NG"},
+	)
+
+	users, err := AllFromRows(context.Background(), StructMapper[userWithExtra](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []userWithExtra{{ID: 1, Name: "foo", Extra: map[string]any{"age": int64(30), "country": "NG"}}}
+	if diff := cmp.Diff(expected, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The field is left nil if every column is mapped
+	rows = newSliceRows([]string{"id", "name"}, []any{2, "bar"})
+	users, err = AllFromRows(context.Background(), StructMapper[userWithExtra](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]userWithExtra{{ID: 2, Name: "bar"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Typed maps and the other mapping options are supported
+	type userWithAttrs struct {
+		*User
+		Attrs map[string]string `db:",remain"`
+	}
+
+	rows = newSliceRows([]string{"id", "color"}, []any{3, "red"})
+	validated, err := AllFromRows(context.Background(), StructMapper[userWithAttrs](WithRowValidator(func([]string, []reflect.Value) bool {
+		return true
+	})), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]userWithAttrs{{User: &User{ID: 3}, Attrs: map[string]string{"color": "red"}}}, validated); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestTimeLayouts(t *testing.T) {
+	type event struct {
+		ID        int
+		StartsAt  time.Time
+		EndsAt    *time.Time
+		CreatedAt time.Time
+	}
+
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := newSliceRows([]string{"id", "starts_at", "ends_at", "created_at"},
+		[]any{1, "2023-06-01 09:30:00", []byte("2023-06-01"), created},
+		[]any{2, "2023-06-02T10:00:00Z", nil, nil},
+	)
+
+	m := StructMapper[event](WithTimeLayouts("2006-01-02 15:04:05", time.RFC3339, "2006-01-02"))
+	events, err := AllFromRows(context.Background(), m, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	endsAt := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	expected := []event{
+		{ID: 1, StartsAt: time.Date(2023, 6, 1, 9, 30, 0, 0, time.UTC), EndsAt: &endsAt, CreatedAt: created},
+		{ID: 2, StartsAt: time.Date(2023, 6, 2, 10, 0, 0, 0, time.UTC)},
+	}
+	if diff := cmp.Diff(expected, events); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows([]string{"id", "starts_at"}, []any{1, "01/06/2023"})
+	if _, err := AllFromRows(context.Background(), m, rows); err == nil {
+		t.Fatal("expected an error for a value that matches no layout")
+	}
+}
+
+func TestColumnMatcher(t *testing.T) {
+	type account struct {
+		UserID    int
+		FirstName string
+		Email     string
+	}
+
+	ctx := context.Background()
+	cols := []string{"USERID", "First_Name", "email"}
+
+	_, err := AllFromRows(ctx, StructMapper[account](), newSliceRows(cols, []any{1, "foo", "a@b.c"}))
+	if !errors.Is(err, ErrNoDestination) {
+		t.Fatalf("expected ErrNoDestination without a matcher, got %v", err)
+	}
+
+	accounts, err := AllFromRows(ctx, StructMapper[account](WithColumnMatcher(MatchIgnoreUnderscores)),
+		newSliceRows(cols, []any{1, "foo", "a@b.c"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]account{{UserID: 1, FirstName: "foo", Email: "a@b.c"}}, accounts); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Case-insensitive matching does not ignore underscores
+	_, err = AllFromRows(ctx, StructMapper[account](WithColumnMatcher(MatchCaseInsensitive)),
+		newSliceRows([]string{"USERID", "FIRST_NAME"}, []any{1, "foo"}))
+	if !errors.Is(err, ErrNoDestination) {
+		t.Fatalf("expected ErrNoDestination for USERID, got %v", err)
+	}
+
+	// Each field is matched once, and exact matches are preferred
+	accounts, err = AllFromRows(ctx, StructMapper[account](WithColumnMatcher(MatchCaseInsensitive), WithAllowUnknownColumns(true)),
+		newSliceRows([]string{"EMAIL", "email", "Email"}, []any{"upper", "exact", "title"}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]account{{Email: "exact"}}, accounts); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestRowFactory(t *testing.T) {
+	ctx := context.Background()
+	newRows := func() Rows {
+		return newSliceRows([]string{"id"}, []any{1}, []any{2})
+	}
+
+	// Fields without a column keep the values from the factory
+	users, err := AllFromRows(ctx, StructMapper[User](WithRowFactory(func() User {
+		return User{Name: "default"}
+	})), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]User{{ID: 1, Name: "default"}, {ID: 2, Name: "default"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	pool := []*User{{Name: "pooled"}}
+	pointers, err := AllFromRows(ctx, StructMapper[*User](WithRowFactory(func() *User {
+		if len(pool) == 0 {
+			return nil
+		}
+		u := pool[0]
+		pool = pool[1:]
+		return u
+	})), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]*User{{ID: 1, Name: "pooled"}, {ID: 2}}, pointers); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	_, err = AllFromRows(ctx, StructMapper[User](WithRowFactory(func() *User { return nil })), newRows())
+	if err == nil {
+		t.Fatal("expected an error for a factory of another type")
+	}
+}
+
+type treeNode struct {
+	ID     int
+	Parent *treeNode
+}
+
+func TestMaxDepth(t *testing.T) {
+	ctx := context.Background()
+	cols := []string{"id", "parent.id", "parent.parent.id"}
+	newRows := func() Rows {
+		return newSliceRows(cols, []any{3, 2, 1})
+	}
+
+	nodes, err := AllFromRows(ctx, StructMapper[treeNode](), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []treeNode{{ID: 3, Parent: &treeNode{ID: 2, Parent: &treeNode{ID: 1}}}}
+	if diff := cmp.Diff(expected, nodes); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	_, err = AllFromRows(ctx, StructMapper[treeNode](WithMaxDepth(0)), newRows())
+	if !errors.Is(err, ErrNoDestination) {
+		t.Fatalf("expected ErrNoDestination, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "treeNode.Parent.Parent") {
+		t.Fatalf("expected the recursive path in the error, got %v", err)
+	}
+
+	// Unknown columns are still allowed
+	nodes, err = AllFromRows(ctx, StructMapper[treeNode](WithMaxDepth(0), WithAllowUnknownColumns(true)), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]treeNode{{ID: 3, Parent: &treeNode{ID: 2}}}, nodes); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The same with the context key
+	allowCtx := context.WithValue(ctx, CtxKeyAllowUnknownColumns, true)
+	nodes, err = AllFromRows(allowCtx, StructMapper[treeNode](WithMaxDepth(0)), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]treeNode{{ID: 3, Parent: &treeNode{ID: 2}}}, nodes); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Deeper columns are mapped with a higher depth
+	cols = []string{"id", "parent.parent.parent.parent.parent.id"}
+	if _, err := AllFromRows(ctx, StructMapper[treeNode](), newSliceRows(cols, []any{1, 2})); !errors.Is(err, ErrNoDestination) {
+		t.Fatalf("expected ErrNoDestination, got %v", err)
+	}
+
+	nodes, err = AllFromRows(ctx, StructMapper[treeNode](WithMaxDepth(5)), newSliceRows(cols, []any{1, 2}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := nodes[0].Parent.Parent.Parent.Parent.Parent.ID; got != 2 {
+		t.Fatalf("expected 2, got %d", got)
+	}
+}
+
+func TestExactFieldNames(t *testing.T) {
+	type legacy struct {
+		ID        int
+		CreatedAt string `db:",exact"`
+		UserName  string `db:"login,exact"`
+	}
+
+	rows := newSliceRows([]string{"id", "CreatedAt", "login"}, []any{1, "today", "foo"})
+	res, err := AllFromRows(context.Background(), StructMapper[legacy](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]legacy{{ID: 1, CreatedAt: "today", UserName: "foo"}}, res); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
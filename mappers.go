@@ -0,0 +1,84 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+)
+
+// Mappers is a registry of the mappers that [StructMapper] uses for specific types
+// instead of reflection, for types that cannot have a MapValues method
+// such as structs from other packages.
+//
+// Use it with [WithMappers]. Mappers should not be modified once in use.
+//
+//	mappers := scan.NewMappers()
+//	scan.RegisterMapper(mappers, scan.CustomStructMapper[stripe.Charge](chargeSource))
+//
+//	charges, _ := stdscan.All(ctx, db, scan.StructMapper[stripe.Charge](scan.WithMappers(mappers)), query)
+type Mappers struct {
+	mappers map[reflect.Type]any
+}
+
+// NewMappers returns an empty registry of mappers
+func NewMappers() *Mappers {
+	return &Mappers{mappers: make(map[reflect.Type]any)}
+}
+
+// RegisterMapper registers m as the mapper for T.
+// It is also used for *T, unless a mapper is registered for *T
+func RegisterMapper[T any](r *Mappers, m Mapper[T]) {
+	r.mappers[typeOf[T]()] = m
+}
+
+// WithMappers makes the struct mapper use the mappers registered for its type.
+// If it is given several times, the registries are consulted in order
+func WithMappers(r *Mappers) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.mappers = append(opt.mappers, r)
+	}
+}
+
+// registeredMapper returns the mapper registered for T in the first registry that has one
+func registeredMapper[T any](registries []*Mappers) (Mapper[T], bool) {
+	typ := typeOf[T]()
+	if typ == nil {
+		return nil, false
+	}
+
+	for _, r := range registries {
+		if m, ok := r.mappers[typ].(Mapper[T]); ok {
+			return m, true
+		}
+
+		if typ.Kind() != reflect.Pointer {
+			continue
+		}
+
+		if m, ok := r.mappers[typ.Elem()]; ok {
+			return pointerMapper[T](reflect.ValueOf(m)), true
+		}
+	}
+
+	return nil, false
+}
+
+// pointerMapper returns a Mapper[T] for a pointer type T from a mapper of its element type,
+// which returns a pointer to each value
+func pointerMapper[T any](m reflect.Value) Mapper[T] {
+	return func(ctx context.Context, c cols) (func(*Row) (any, error), func(any) (T, error)) {
+		out := m.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(c)})
+		before, after := out[0].Interface().(func(*Row) (any, error)), out[1]
+
+		return before, func(link any) (T, error) {
+			res := after.Call([]reflect.Value{reflect.ValueOf(&link).Elem()})
+			if err, _ := res[1].Interface().(error); err != nil {
+				var t T
+				return t, err
+			}
+
+			ptr := reflect.New(res[0].Type())
+			ptr.Elem().Set(res[0])
+			return ptr.Interface().(T), nil
+		}
+	}
+}
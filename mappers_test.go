@@ -0,0 +1,77 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// Coordinates stands in for a type from another package
+type Coordinates struct {
+	Lat, Lng float64
+}
+
+// coordinatesMapper doubles the values, to tell it apart from reflection
+func coordinatesMapper(ctx context.Context, c cols) (func(*Row) (any, error), func(any) (Coordinates, error)) {
+	return func(v *Row) (any, error) {
+			var lat, lng float64
+			v.ScheduleScan("lat", &lat)
+			v.ScheduleScan("lng", &lng)
+			return [2]*float64{&lat, &lng}, nil
+		}, func(link any) (Coordinates, error) {
+			p := link.([2]*float64)
+			return Coordinates{Lat: *p[0] * 2, Lng: *p[1] * 2}, nil
+		}
+}
+
+func TestMappers(t *testing.T) {
+	ctx := context.Background()
+	mappers := NewMappers()
+	RegisterMapper[Coordinates](mappers, coordinatesMapper)
+
+	rows := func() Rows {
+		return newSliceRows([]string{"lat", "lng"}, []any{1.0, 2.0}, []any{3.0, 4.0})
+	}
+
+	coords, err := AllFromRows(ctx, StructMapper[Coordinates](WithMappers(mappers)), rows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]Coordinates{{Lat: 2, Lng: 4}, {Lat: 6, Lng: 8}}, coords); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The mapper for T is also used for *T
+	ptrs, err := AllFromRows(ctx, StructMapper[*Coordinates](WithMappers(mappers)), rows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]*Coordinates{{Lat: 2, Lng: 4}, {Lat: 6, Lng: 8}}, ptrs); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Earlier registries take priority
+	first := NewMappers()
+	RegisterMapper[Coordinates](first, StructMapper[Coordinates]())
+	coords, err = AllFromRows(ctx, StructMapper[Coordinates](WithMappers(first), WithMappers(mappers)), rows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]Coordinates{{Lat: 1, Lng: 2}, {Lat: 3, Lng: 4}}, coords); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Without the registry, reflection is used
+	coords, err = AllFromRows(ctx, StructMapper[Coordinates](), rows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]Coordinates{{Lat: 1, Lng: 2}, {Lat: 3, Lng: 4}}, coords); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
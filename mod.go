@@ -2,24 +2,36 @@ package scan
 
 import (
 	"context"
+	"fmt"
 )
 
+// CtxKeyMapperMods makes it possible to add [MapperMod]s to a struct mapper using the context.
+// The value should be a []MapperMod. They are applied after the mods set with [WithMapperMods]
+var CtxKeyMapperMods contextKey = "mapper mods"
+
 type (
 	// MapperMod is a function that can be used to convert an existing mapper
-	// into a new mapper using [Mod]
+	// into a new mapper using [Mod].
+	// Like a [Mapper], it is called once with the columns and returns
+	// a function called before each row is scanned,
+	// and a function called with each value from the original mapper.
+	//
+	// Mods can be used for validation, filling defaults or audit logging.
+	// Use [BeforeRow] and [AfterRow] to create one from a single callback.
 	MapperMod = func(context.Context, cols) (BeforeFunc, AfterMod)
 	// AfterMod receives both the link of the [MapperMod] and the retrieved value from
 	// the original mapper
 	AfterMod = func(link any, retrieved any) error
 )
 
-// Mod converts an existing mapper into a new mapper with [MapperMod]s
+// Mod converts an existing mapper into a new mapper with [MapperMod]s.
+// The mods are run in order, after the before and after functions of the original mapper.
+// If any of them returns an error, the row is not mapped
 func Mod[T any](m Mapper[T], mods ...MapperMod) Mapper[T] {
 	return func(ctx context.Context, c cols) (func(*Row) (any, error), func(any) (T, error)) {
 		before, after := m(ctx, c)
 		befores := make([]BeforeFunc, len(mods))
 		afters := make([]AfterMod, len(mods))
-		links := make([]any, len(mods))
 		for i, m := range mods {
 			befores[i], afters[i] = m(ctx, c)
 		}
@@ -30,21 +42,24 @@ func Mod[T any](m Mapper[T], mods ...MapperMod) Mapper[T] {
 					return nil, err
 				}
 
+				// The links are kept per row so the mapper can be used concurrently
+				links := make([]any, len(befores))
 				for i, b := range befores {
 					if links[i], err = b(v); err != nil {
 						return nil, err
 					}
 				}
 
-				return a, nil
+				return modLink{link: a, mods: links}, nil
 			}, func(v any) (T, error) {
-				t, err := after(v)
+				l := v.(modLink)
+				t, err := after(l.link)
 				if err != nil {
 					return t, err
 				}
 
 				for i, a := range afters {
-					if err := a(links[i], t); err != nil {
+					if err := a(l.mods[i], t); err != nil {
 						return t, err
 					}
 				}
@@ -53,3 +68,39 @@ func Mod[T any](m Mapper[T], mods ...MapperMod) Mapper[T] {
 			}
 	}
 }
+
+// modLink holds the links of the original mapper and its mods for a row
+type modLink struct {
+	link any
+	mods []any
+}
+
+// BeforeRow returns a [MapperMod] that calls fn before each row is scanned.
+// fn can schedule scans for the columns that the mapper does not use
+func BeforeRow(fn func(ctx context.Context, r *Row) error) MapperMod {
+	return func(ctx context.Context, c cols) (BeforeFunc, AfterMod) {
+		return func(r *Row) (any, error) {
+				return nil, fn(r.runContext(ctx), r)
+			}, func(link, retrieved any) error {
+				return nil
+			}
+	}
+}
+
+// AfterRow returns a [MapperMod] that calls fn with each value retrieved by the mapper.
+// T must be the type returned by the mapper. To modify the value, the mapper should return a pointer
+func AfterRow[T any](fn func(ctx context.Context, v T) error) MapperMod {
+	return func(ctx context.Context, c cols) (BeforeFunc, AfterMod) {
+		return func(r *Row) (any, error) {
+				return r, nil
+			}, func(link, retrieved any) error {
+				v, ok := retrieved.(T)
+				if !ok {
+					return fmt.Errorf("AfterRow expected %T, got %T", v, retrieved)
+				}
+
+				r, _ := link.(*Row)
+				return fn(r.runContext(ctx), v)
+			}
+	}
+}
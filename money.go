@@ -0,0 +1,273 @@
+package scan
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Currency is implemented by currency types used with [Money].
+// Use [CurrencyCode] for ISO 4217 currencies, or implement it for custom currencies
+type Currency interface {
+	// Code is the code of the currency e.g. USD
+	Code() string
+	// MinorUnits is the number of digits after the decimal point e.g. 2 for USD and 0 for JPY
+	MinorUnits() int
+}
+
+// CurrencyCode is an ISO 4217 currency code such as USD
+type CurrencyCode string
+
+// Code returns the currency code
+func (c CurrencyCode) Code() string {
+	return string(c)
+}
+
+// MinorUnits returns the number of digits after the decimal point.
+// It is 2 for currencies not in the ISO 4217 exceptions
+func (c CurrencyCode) MinorUnits() int {
+	if units, ok := isoMinorUnits[string(c)]; ok {
+		return units
+	}
+
+	return 2
+}
+
+// isoMinorUnits are the ISO 4217 currencies that do not have 2 minor units
+var isoMinorUnits = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Money is an amount in the minor units of its currency, e.g. cents.
+// If the currency is nil, 2 minor units are assumed
+type Money struct {
+	Amount   int64
+	Currency Currency
+}
+
+// Decimal returns the amount in the major units of the currency, e.g. 12.34
+func (m Money) Decimal() string {
+	units := minorUnits(m.Currency)
+	if units == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := fmt.Sprintf("%0*d", units+1, amount)
+	return sign + digits[:len(digits)-units] + "." + digits[len(digits)-units:]
+}
+
+// String returns the amount in major units followed by the currency code, e.g. 12.34 USD
+func (m Money) String() string {
+	if m.Currency == nil {
+		return m.Decimal()
+	}
+
+	return m.Decimal() + " " + m.Currency.Code()
+}
+
+func minorUnits(c Currency) int {
+	if c == nil {
+		return 2
+	}
+
+	return c.MinorUnits()
+}
+
+// MoneyConverter returns a converter for money columns into [Money] in the given currency,
+// to use with [RegisterConverter].
+//
+// Integer columns hold the amount in minor units e.g. cents, while decimal and text
+// columns hold the amount in major units e.g. 12.34 or $1,234.56 from the Postgres money type.
+// NULL values return the zero value
+func MoneyConverter(currency Currency) func(src any) (Money, error) {
+	return func(src any) (Money, error) {
+		if src == nil {
+			return Money{}, nil
+		}
+
+		amount, err := parseMoney(src, minorUnits(currency), false)
+		if err != nil {
+			return Money{}, err
+		}
+
+		return Money{Amount: amount, Currency: currency}, nil
+	}
+}
+
+// moneyDecoder returns the decoder for fields with the money tag option.
+// The currency of the field is looked up with the code, if given
+func (s *mapperSourceImpl) moneyDecoder(opts tagOptions) decodeFunc {
+	var currency Currency
+	if code := opts["currency"]; code != "" {
+		currency = s.currency(code)
+	}
+
+	units := minorUnits(currency)
+	minor := opts.has("minor")
+
+	return func(src any, dest any) error {
+		amount, err := parseMoney(src, units, minor)
+		if err != nil {
+			return err
+		}
+
+		v := reflect.ValueOf(dest).Elem()
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		switch {
+		case v.Type() == reflect.TypeOf(Money{}):
+			v.Set(reflect.ValueOf(Money{Amount: amount, Currency: currency}))
+		case v.CanInt():
+			if v.OverflowInt(amount) {
+				return fmt.Errorf("money amount %d overflows %s", amount, v.Type())
+			}
+			v.SetInt(amount)
+		default:
+			return fmt.Errorf("cannot decode money into %s", v.Type())
+		}
+
+		return nil
+	}
+}
+
+// currency returns the registered currency for the code, or the ISO 4217 currency
+func (s *mapperSourceImpl) currency(code string) Currency {
+	if c, ok := s.currencies[code]; ok {
+		return c
+	}
+
+	return CurrencyCode(code)
+}
+
+// parseMoney returns the amount in minor units of a column value.
+// Integers are in minor units, while floats and text are in major units
+// unless minor is set
+func parseMoney(src any, units int, minor bool) (int64, error) {
+	switch src := src.(type) {
+	case int64:
+		return src, nil
+	case float64:
+		if minor {
+			return int64(math.Round(src)), nil
+		}
+		return int64(math.Round(src * math.Pow10(units))), nil
+	case []byte:
+		return parseMoneyText(string(src), units, minor)
+	case string:
+		return parseMoneyText(src, units, minor)
+	}
+
+	if v := reflect.ValueOf(src); v.CanInt() {
+		return v.Int(), nil
+	}
+
+	return 0, fmt.Errorf("cannot convert %T to money", src)
+}
+
+// parseMoneyText parses a decimal amount with an optional sign or parentheses for a negative amount,
+// one currency symbol or code before or after it, and group separators between the digits,
+// e.g. 12.34, -$1,234.56, ($5.00) or 12.34 USD. Any other character is an error
+func parseMoneyText(text string, units int, minor bool) (int64, error) {
+	s := strings.TrimSpace(text)
+
+	var negative, signed bool
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative, signed = true, true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+
+	takeSign := func() bool {
+		if s == "" || (s[0] != '-' && s[0] != '+') {
+			return true
+		}
+		if signed {
+			return false
+		}
+
+		negative, signed = s[0] == '-', true
+		s = strings.TrimSpace(s[1:])
+		return true
+	}
+
+	if !takeSign() {
+		return 0, fmt.Errorf("invalid money value %q", text)
+	}
+
+	// The currency can be before the sign or the digits, or after the digits
+	if i := strings.IndexFunc(s, isNotCurrencyRune); i > 0 {
+		s = strings.TrimSpace(s[i:])
+		if !takeSign() {
+			return 0, fmt.Errorf("invalid money value %q", text)
+		}
+	} else if i := strings.LastIndexFunc(s, isNotCurrencyRune); i >= 0 && i < len(s)-1 {
+		s = strings.TrimSpace(s[:i+1])
+	}
+
+	whole, frac, _ := strings.Cut(s, ".")
+	if !isMoneyDigits(whole, true) || !isMoneyDigits(frac, false) || whole == "" && frac == "" {
+		return 0, fmt.Errorf("invalid money value %q", text)
+	}
+	whole = strings.ReplaceAll(whole, ",", "")
+
+	if minor {
+		units = 0
+	}
+
+	digits := strings.TrimRight(frac, "0")
+	if len(digits) > units {
+		return 0, fmt.Errorf("money value %q has more than %d decimal places", text, units)
+	}
+
+	digits = whole + digits + strings.Repeat("0", units-len(digits))
+	if digits == "" {
+		digits = "0"
+	}
+
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid money value %q: %w", text, err)
+	}
+
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+// isNotCurrencyRune reports if r cannot be part of a currency symbol or code such as $, € or USD
+func isNotCurrencyRune(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.Is(unicode.Sc, r)
+}
+
+// isMoneyDigits reports if s only has digits, and commas between them if grouped is set
+func isMoneyDigits(s string, grouped bool) bool {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case '0' <= s[i] && s[i] <= '9':
+		case grouped && s[i] == ',' && i > 0 && i < len(s)-1 && s[i-1] != ',':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,142 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type bitcoin struct{}
+
+func (bitcoin) Code() string    { return "BTC" }
+func (bitcoin) MinorUnits() int { return 8 }
+
+type Purchase struct {
+	ID       int
+	Total    Money  `db:"total,money,currency=USD"`
+	Refund   *Money `db:"refund,money,currency=JPY"`
+	Cents    int64  `db:"cents,money"`
+	Fee      Money  `db:"fee,money,minor,currency=EUR"`
+	Reserved Money  `db:"reserved,money,currency=BTC"`
+}
+
+func TestMoneyColumns(t *testing.T) {
+	src, err := NewStructMapperSource(WithCurrencies(bitcoin{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := newSliceRows([]string{"id", "total", "refund", "cents", "fee", "reserved"},
+		[]any{1, 1234, "¥1,500", []byte("12.5"), "250", 0.5},
+		[]any{2, "-$1,234.56", nil, 99, nil, "0.00000001"},
+	)
+
+	orders, err := AllFromRows(context.Background(), CustomStructMapper[Purchase](src), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Purchase{
+		{
+			ID:       1,
+			Total:    Money{Amount: 1234, Currency: CurrencyCode("USD")},
+			Refund:   &Money{Amount: 1500, Currency: CurrencyCode("JPY")},
+			Cents:    1250,
+			Fee:      Money{Amount: 250, Currency: CurrencyCode("EUR")},
+			Reserved: Money{Amount: 50000000, Currency: bitcoin{}},
+		},
+		{
+			ID:       2,
+			Total:    Money{Amount: -123456, Currency: CurrencyCode("USD")},
+			Cents:    99,
+			Reserved: Money{Amount: 1, Currency: bitcoin{}},
+		},
+	}
+	if diff := cmp.Diff(expected, orders); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows([]string{"id", "refund"}, []any{1, "1.5"})
+	if _, err := AllFromRows(context.Background(), CustomStructMapper[Purchase](src), rows); err == nil {
+		t.Fatal("expected an error for too many decimal places")
+	}
+}
+
+func TestMoneyConverter(t *testing.T) {
+	conv := NewConverters()
+	RegisterConverter(conv, MoneyConverter(CurrencyCode("KWD")))
+
+	type wallet struct {
+		ID      int
+		Balance Money
+		Limit   *Money
+	}
+
+	rows := newSliceRows([]string{"id", "balance", "limit"},
+		[]any{1, "1.234", nil},
+		[]any{2, int64(5), "(2.5)"},
+	)
+
+	wallets, err := AllFromRows(context.Background(), StructMapper[wallet](WithTypeConverter(conv)), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	kwd := CurrencyCode("KWD")
+	expected := []wallet{
+		{ID: 1, Balance: Money{Amount: 1234, Currency: kwd}},
+		{ID: 2, Balance: Money{Amount: 5, Currency: kwd}, Limit: &Money{Amount: -2500, Currency: kwd}},
+	}
+	if diff := cmp.Diff(expected, wallets); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	cases := map[string]Money{
+		"12.34 USD":      {Amount: 1234, Currency: CurrencyCode("USD")},
+		"-0.05 EUR":      {Amount: -5, Currency: CurrencyCode("EUR")},
+		"1500 JPY":       {Amount: 1500, Currency: CurrencyCode("JPY")},
+		"1.000 BHD":      {Amount: 1000, Currency: CurrencyCode("BHD")},
+		"7.00":           {Amount: 700},
+		"0.00000001 BTC": {Amount: 1, Currency: bitcoin{}},
+	}
+
+	for expected, m := range cases {
+		if got := m.String(); got != expected {
+			t.Fatalf("expected %q, got %q", expected, got)
+		}
+	}
+
+	parsed := map[string]int64{
+		"12.34":      1234,
+		"-$1,234.56": -123456,
+		"$-1,234.56": -123456,
+		"($5.00)":    -500,
+		"+€7":        700,
+		"12.34 USD":  1234,
+		"-0.05 EUR":  -5,
+		".5":         50,
+	}
+
+	for text, expected := range parsed {
+		got, err := parseMoneyText(text, 2, false)
+		if err != nil {
+			t.Fatalf("unexpected error for %q: %v", text, err)
+		}
+		if got != expected {
+			t.Fatalf("expected %d for %q, got %d", expected, text, got)
+		}
+	}
+
+	invalid := []string{
+		"", "$", "1.2.3", "abc", "N/A5", "1e5", "12-34", "--5", "(-5)",
+		"$5$", "USD 5 EUR", "1,,234", ",123", "123,", "1 234", "5%",
+	}
+	for _, text := range invalid {
+		if _, err := parseMoneyText(text, 2, false); err == nil {
+			t.Fatalf("expected an error for %q", text)
+		}
+	}
+}
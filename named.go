@@ -0,0 +1,169 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Named rewrites the :name parameters of the query into the placeholders of the dialect,
+// such as ? or $1, and returns the args in their order. The value of each name is taken from arg,
+// which is a map with string keys, or a struct whose fields are named
+// with the same column names as [StructMapper].
+//
+// Names are made of letters, digits, underscores and dots e.g. :author.id.
+// Parameters inside quoted strings, quoted identifiers and comments are left as is,
+// as are casts such as ::text. A name that is used several times gets an arg each time.
+//
+//	query, args, err := scan.Named(scan.Postgres, "SELECT * FROM users WHERE id = :id AND status = :status",
+//	    map[string]any{"id": 1, "status": "active"})
+//	// SELECT * FROM users WHERE id = $1 AND status = $2, []any{1, "active"}
+func Named(d Dialect, query string, arg any) (string, []any, error) {
+	return CustomNamed(defaultStructMapper, d, query, arg)
+}
+
+// CustomNamed is like [Named] but uses a custom struct mapping source
+// which should have been created with [NewStructMapperSource]
+func CustomNamed(src StructMapperSource, d Dialect, query string, arg any) (string, []any, error) {
+	values, err := namedValues(src, arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var args []any
+	rewritten, err := rewriteQuery(query, func(rest string) (string, int, error) {
+		if rest[0] != ':' {
+			return "", 0, nil
+		}
+
+		// Casts such as ::text are not parameters
+		if len(rest) > 1 && rest[1] == ':' {
+			return "::", 2, nil
+		}
+
+		n := 1
+		for n < len(rest) && isNameByte(rest[n]) {
+			n++
+		}
+		if n == 1 {
+			return "", 0, nil
+		}
+
+		name := rest[1:n]
+		val, ok := values(name)
+		if !ok {
+			return "", 0, fmt.Errorf("no value for the named parameter %q", name)
+		}
+
+		args = append(args, val)
+		return d.placeholder(len(args)), n, nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return rewritten, args, nil
+}
+
+func isNameByte(b byte) bool {
+	return b == '_' || b == '.' ||
+		'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' || '0' <= b && b <= '9'
+}
+
+// namedValues returns a function that looks up the values of names in arg
+func namedValues(src StructMapperSource, arg any) (func(string) (any, bool), error) {
+	if arg == nil {
+		return func(string) (any, bool) { return nil, false }, nil
+	}
+
+	val := reflect.ValueOf(arg)
+	if val.Kind() == reflect.Map {
+		if val.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("named parameters must be in a map with string keys, not %s", val.Type())
+		}
+
+		return func(name string) (any, bool) {
+			v := val.MapIndex(reflect.ValueOf(name).Convert(val.Type().Key()))
+			if !v.IsValid() {
+				return nil, false
+			}
+			return v.Interface(), true
+		}, nil
+	}
+
+	cols, vals, err := CustomColumnValues(src, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any, len(cols))
+	for i, col := range cols {
+		values[col] = vals[i]
+	}
+
+	return func(name string) (any, bool) {
+		v, ok := values[name]
+		return v, ok
+	}, nil
+}
+
+// rewriteQuery copies the query, calling replace at each position that is not
+// inside a quoted string, a quoted identifier or a comment.
+// replace returns the text to write instead of the first n bytes of rest,
+// or n == 0 to copy the byte as is
+func rewriteQuery(query string, replace func(rest string) (text string, n int, err error)) (string, error) {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	for i := 0; i < len(query); {
+		rest := query[i:]
+
+		// Copy quoted strings, identifiers and comments as they are
+		if end := skipQuoted(rest); end > 0 {
+			b.WriteString(rest[:end])
+			i += end
+			continue
+		}
+
+		text, n, err := replace(rest)
+		if err != nil {
+			return "", err
+		}
+
+		if n == 0 {
+			b.WriteByte(query[i])
+			i++
+			continue
+		}
+
+		b.WriteString(text)
+		i += n
+	}
+
+	return b.String(), nil
+}
+
+// skipQuoted returns the length of the quoted string, quoted identifier or comment
+// at the start of s, or 0 if there is none. Unterminated ones run to the end of s.
+// Quotes escaped by doubling them are the same as two quoted strings in a row
+func skipQuoted(s string) int {
+	var start int
+	var end string
+	switch {
+	case s[0] == '\'', s[0] == '"', s[0] == '`':
+		start, end = 1, s[:1]
+	case strings.HasPrefix(s, "--"):
+		start, end = 2, "\n"
+	case strings.HasPrefix(s, "/*"):
+		start, end = 2, "*/"
+	default:
+		return 0
+	}
+
+	i := strings.Index(s[start:], end)
+	if i < 0 {
+		return len(s)
+	}
+
+	return start + i + len(end)
+}
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNamed(t *testing.T) {
+	type filter struct {
+		ID     int
+		Status string
+		Author *User
+	}
+
+	cases := map[string]struct {
+		query    string
+		arg      any
+		expected string
+		args     []any
+	}{
+		"map": {
+			query:    "SELECT * FROM users WHERE id = :id AND status = :status OR parent = :id",
+			arg:      map[string]any{"id": 1, "status": "active"},
+			expected: "SELECT * FROM users WHERE id = ? AND status = ? OR parent = ?",
+			args:     []any{1, "active", 1},
+		},
+		"struct": {
+			query:    "SELECT * FROM posts WHERE status = :status AND author_id = :author.id",
+			arg:      filter{Status: "draft", Author: &User{ID: 7}},
+			expected: "SELECT * FROM posts WHERE status = ? AND author_id = ?",
+			args:     []any{"draft", 7},
+		},
+		"quoted and casts": {
+			query:    "SELECT ':id', \":id\", created_at::date FROM t -- :id\nWHERE id = :id /* :status */",
+			arg:      map[string]int{"id": 2},
+			expected: "SELECT ':id', \":id\", created_at::date FROM t -- :id\nWHERE id = ? /* :status */",
+			args:     []any{2},
+		},
+		"no params": {
+			query:    "SELECT 1",
+			expected: "SELECT 1",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			query, args, err := Named(MySQL, tc.query, tc.arg)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if query != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, query)
+			}
+
+			if diff := cmp.Diff(tc.args, args); diff != "" {
+				t.Fatalf("diff: %s", diff)
+			}
+		})
+	}
+
+	if _, _, err := Named(MySQL, "SELECT :missing", map[string]any{}); err == nil {
+		t.Fatal("expected an error for a missing parameter")
+	}
+
+	if _, _, err := Named(MySQL, "SELECT :author.id", filter{}); err == nil {
+		t.Fatal("expected an error for a field behind a nil pointer")
+	}
+
+	for d, expected := range map[Dialect]string{
+		Postgres: "SELECT * FROM users WHERE id = $1 AND status = $2 OR parent = $3",
+		MSSQL:    "SELECT * FROM users WHERE id = @p1 AND status = @p2 OR parent = @p3",
+	} {
+		query, _, err := Named(d, "SELECT * FROM users WHERE id = :id AND status = :status OR parent = :id",
+			map[string]any{"id": 1, "status": "active"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if query != expected {
+			t.Fatalf("%s: expected %q, got %q", d, expected, query)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package scan
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DefaultInitialisms are the initialisms kept as a single word by [SnakeCase],
+// e.g. UserID is mapped to user_id and HTTPURL to http_url
+var DefaultInitialisms = []string{
+	"ACL", "API", "ASCII", "CPU", "CSS", "DNS", "EOF", "GUID", "HTML", "HTTP", "HTTPS",
+	"ID", "IP", "JSON", "LHS", "QPS", "RAM", "RHS", "RPC", "SLA", "SMTP", "SQL", "SSH",
+	"TCP", "TLS", "TTL", "UDP", "UI", "UID", "UUID", "URI", "URL", "UTF8", "VM", "XML",
+	"XMPP", "XSRF", "XSS",
+}
+
+var defaultSnakeCase = NewSnakeCase()
+
+// SnakeCase maps struct field names to snake case.
+// It is the default function used to map field names to column names.
+// See [NewSnakeCase] for how the words are split
+func SnakeCase(str string) string {
+	return defaultSnakeCase(str)
+}
+
+// NewSnakeCase returns a function that maps struct field names to snake case,
+// keeping the initialisms and [DefaultInitialisms] as single words,
+// e.g. with OAuth, OAuth2Token is mapped to oauth2_token instead of o_auth2_token.
+//
+// Words start at an upper case letter after a lower case letter or a digit,
+// and at the last upper case letter of a run that is followed by a lower case letter,
+// e.g. HTTPStatus is mapped to http_status. Letters are compared with their Unicode case,
+// so ÜberÄrger is mapped to über_ärger. Digits stay with the word before them,
+// and an initialism followed by a lower case s is kept as a plural, e.g. UserIDs to user_ids
+func NewSnakeCase(initialisms ...string) func(string) string {
+	all := make([][]rune, 0, len(DefaultInitialisms)+len(initialisms))
+	for _, list := range [][]string{DefaultInitialisms, initialisms} {
+		for _, i := range list {
+			if i != "" {
+				all = append(all, []rune(i))
+			}
+		}
+	}
+
+	// Longer initialisms are tried first, e.g. HTTPS before HTTP
+	sort.SliceStable(all, func(i, j int) bool { return len(all[i]) > len(all[j]) })
+
+	return func(name string) string {
+		return strings.ToLower(strings.Join(splitWords(name, all), "_"))
+	}
+}
+
+// splitWords splits the field name into words. See [NewSnakeCase]
+func splitWords(name string, initialisms [][]rune) []string {
+	runes := []rune(name)
+	var words []string
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !isWordRune(r) {
+			i++
+			continue
+		}
+
+		start := i
+		if n := matchInitialism(runes, i, initialisms); n > 0 {
+			i += n
+		} else if unicode.IsUpper(r) && i+1 < len(runes) && unicode.IsUpper(runes[i+1]) {
+			// A run of upper case letters, without the one that starts the next word
+			i++
+			for i < len(runes) && unicode.IsUpper(runes[i]) &&
+				!(i+1 < len(runes) && isLowerLetter(runes[i+1])) &&
+				matchInitialism(runes, i, initialisms) == 0 {
+				i++
+			}
+		} else {
+			i++
+			for i < len(runes) && isLowerRune(runes[i]) {
+				i++
+			}
+		}
+
+		// Digits stay with the word before them
+		for i < len(runes) && unicode.IsDigit(runes[i]) {
+			i++
+		}
+
+		words = append(words, string(runes[start:i]))
+	}
+
+	return words
+}
+
+// matchInitialism returns the length of the initialism at runes[i:], if any.
+// The initialism must not be followed by a lower case letter, except for a plural s
+func matchInitialism(runes []rune, i int, initialisms [][]rune) int {
+	for _, init := range initialisms {
+		n := len(init)
+		if i+n > len(runes) || string(runes[i:i+n]) != string(init) {
+			continue
+		}
+
+		switch {
+		case i+n == len(runes) || !isLowerLetter(runes[i+n]):
+			return n
+		case runes[i+n] == 's' && (i+n+1 == len(runes) || !isLowerLetter(runes[i+n+1])):
+			return n + 1
+		}
+	}
+
+	return 0
+}
+
+// isWordRune reports if the rune is part of a word, i.e. not a separator such as _
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// isLowerLetter reports if the rune is a lower case letter or a letter without case
+func isLowerLetter(r rune) bool {
+	return unicode.IsLetter(r) && !unicode.IsUpper(r)
+}
+
+// isLowerRune reports if the rune continues a word, i.e. a lower case letter,
+// a letter without case, a digit or a mark
+func isLowerRune(r rune) bool {
+	return isWordRune(r) && !unicode.IsUpper(r)
+}
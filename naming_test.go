@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSnakeCase(t *testing.T) {
+	cases := map[string]string{
+		"ID":           "id",
+		"Name":         "name",
+		"CreatedAt":    "created_at",
+		"UserID":       "user_id",
+		"userID":       "user_id",
+		"HTTPStatus":   "http_status",
+		"HTTPURL":      "http_url",
+		"GetHTTPSURL":  "get_https_url",
+		"UserIDs":      "user_ids",
+		"URLs":         "urls",
+		"ID2":          "id2",
+		"ABC123Def":    "abc123_def",
+		"Base64Data":   "base64_data",
+		"UTF8String":   "utf8_string",
+		"OAuth2Token":  "o_auth2_token",
+		"ÜberID":       "über_id",
+		"ÄpfelÖl":      "äpfel_öl",
+		"NaïveBayes":   "naïve_bayes",
+		"Foo_Bar":      "foo_bar",
+		"IsHTMLParser": "is_html_parser",
+	}
+
+	for name, expected := range cases {
+		if got := SnakeCase(name); got != expected {
+			t.Errorf("expected %q for %q, got %q", expected, name, got)
+		}
+	}
+
+	custom := NewSnakeCase("OAuth", "GraphQL")
+	for name, expected := range map[string]string{
+		"OAuth2Token":     "oauth2_token",
+		"UserOAuthTokens": "user_oauth_tokens",
+		"GraphQLQuery":    "graphql_query",
+		"UserID":          "user_id",
+	} {
+		if got := custom(name); got != expected {
+			t.Errorf("expected %q for %q, got %q", expected, name, got)
+		}
+	}
+}
+
+func TestWithInitialisms(t *testing.T) {
+	type product struct {
+		ProductSKUs string
+		UserID      int
+	}
+
+	src, err := NewStructMapperSource(WithInitialisms("SKU"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cols, _, err := CustomColumnValues(src, product{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"product_skus", "user_id"}, cols); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
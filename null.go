@@ -0,0 +1,92 @@
+package scan
+
+import (
+	"database/sql/driver"
+
+	"github.com/aarondl/opt"
+)
+
+// Null is a value of T that may be NULL, to tell NULL apart from the zero value.
+// It can be used as the type of struct fields and with [Value] in collectors
+//
+//	return func(v *scan.Values) (scan.Null[int], error) {
+//	    return scan.Value[scan.Null[int]](v, "age"), nil
+//	}
+type Null[T any] struct {
+	V     T
+	Valid bool
+}
+
+// NullOf returns a valid [Null] with the value
+func NullOf[T any](v T) Null[T] {
+	return Null[T]{V: v, Valid: true}
+}
+
+// Get returns the value and whether it is not NULL
+func (n Null[T]) Get() (T, bool) {
+	return n.V, n.Valid
+}
+
+// Or returns the value, or def if it is NULL
+func (n Null[T]) Or(def T) T {
+	if !n.Valid {
+		return def
+	}
+
+	return n.V
+}
+
+// Ptr returns a pointer to the value, or nil if it is NULL
+func (n Null[T]) Ptr() *T {
+	if !n.Valid {
+		return nil
+	}
+
+	v := n.V
+	return &v
+}
+
+// Scan implements [database/sql.Scanner]
+func (n *Null[T]) Scan(value any) error {
+	var zero T
+	n.V, n.Valid = zero, false
+	if value == nil {
+		return nil
+	}
+
+	if v, ok := value.(T); ok {
+		n.V, n.Valid = v, true
+		return nil
+	}
+
+	if err := opt.ConvertAssign(&n.V, value); err != nil {
+		n.V = zero
+		return err
+	}
+
+	n.Valid = true
+	return nil
+}
+
+// Value implements [database/sql/driver.Valuer].
+// The value is converted to a [driver.Value] the same way as the args of a query, e.g. an int to an int64
+func (n Null[T]) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return driver.DefaultParameterConverter.ConvertValue(n.V)
+}
+
+// IsNull reports if the value of the column is NULL in the current row.
+// Columns that do not exist are reported as NULL, and the error is returned
+// by the function that collects the rows
+func (v *Values) IsNull(name string) bool {
+	i, ok := v.index[name]
+	if !ok {
+		v.setErr(unknownColumnError(name))
+		return true
+	}
+
+	return v.values[i] == nil
+}
@@ -0,0 +1,96 @@
+package scan
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestNullValues(t *testing.T) {
+	ctx := context.Background()
+
+	type age struct {
+		null bool
+		age  Null[int]
+	}
+
+	ages, err := CollectOf(ctx, funcQ(usersWithAge), func(ctx context.Context, c cols) func(*Values) (age, error) {
+		return func(v *Values) (age, error) {
+			return age{null: v.IsNull("age"), age: Value[Null[int]](v, "age")}, nil
+		}
+	}, "SELECT id, name, age FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []age{
+		{age: NullOf(30)},
+		{null: true},
+		{age: NullOf(41)},
+	}
+	if diff := cmp.Diff(expected, ages, cmp.AllowUnexported(age{})); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	_, err = CollectOf(ctx, funcQ(usersWithAge), func(ctx context.Context, c cols) func(*Values) (bool, error) {
+		return func(v *Values) (bool, error) { return v.IsNull("missing"), nil }
+	}, "SELECT id, name, age FROM users")
+	if !errors.Is(err, ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn, got %v", err)
+	}
+}
+
+func TestNullField(t *testing.T) {
+	type person struct {
+		ID  int
+		Age Null[int64]
+	}
+
+	rows := newSliceRows([]string{"id", "age"}, []any{1, int64(30)}, []any{2, nil})
+	people, err := AllFromRows(context.Background(), StructMapper[person](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []person{{ID: 1, Age: NullOf[int64](30)}, {ID: 2}}
+	if diff := cmp.Diff(expected, people); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if people[1].Age.Or(-1) != -1 || people[1].Age.Ptr() != nil {
+		t.Fatal("expected a NULL age")
+	}
+
+	if v, _ := people[0].Age.Value(); v != int64(30) {
+		t.Fatalf("expected 30, got %v", v)
+	}
+}
+
+func TestNullValue(t *testing.T) {
+	cases := map[string]struct {
+		value    driver.Valuer
+		expected driver.Value
+	}{
+		"int":    {value: NullOf(1), expected: int64(1)},
+		"int32":  {value: NullOf[int32](2), expected: int64(2)},
+		"uint8":  {value: NullOf[uint8](3), expected: int64(3)},
+		"string": {value: NullOf("a"), expected: "a"},
+		"null":   {value: Null[int]{}, expected: nil},
+		"valuer": {value: NullOf(sql.NullString{String: "b", Valid: true}), expected: "b"},
+	}
+
+	for name, c := range cases {
+		got, err := c.value.Value()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		if got != c.expected {
+			t.Fatalf("%s: expected %#v, got %#v", name, c.expected, got)
+		}
+	}
+}
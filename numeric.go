@@ -0,0 +1,384 @@
+package scan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"sync"
+
+	"github.com/aarondl/opt"
+)
+
+var (
+	// ErrOverflow is returned by [WithNumericGuard] when a value does not fit in its field
+	ErrOverflow = errors.New("numeric overflow")
+	// ErrPrecisionLoss is returned by [WithNumericGuard] when a value cannot be stored
+	// exactly in its field, e.g. 1.5 in an int or 0.1234567890123 in a float32
+	ErrPrecisionLoss = errors.New("numeric precision loss")
+)
+
+// NumericLoss is the number of values of a column that overflowed or lost precision
+type NumericLoss struct {
+	Overflow      int64
+	PrecisionLoss int64
+}
+
+// NumericReport records the values that overflowed or lost precision when they were
+// converted into the numeric fields of a struct, for each column. Use it with [WithNumericGuard].
+// It is safe for concurrent use, and can be shared by several queries
+type NumericReport struct {
+	mu      sync.Mutex
+	columns map[string]NumericLoss
+}
+
+// Columns returns the losses of each column that had any
+func (r *NumericReport) Columns() map[string]NumericLoss {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	columns := make(map[string]NumericLoss, len(r.columns))
+	for name, loss := range r.columns {
+		columns[name] = loss
+	}
+
+	return columns
+}
+
+func (r *NumericReport) record(column string, loss error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.columns == nil {
+		r.columns = make(map[string]NumericLoss)
+	}
+
+	counts := r.columns[column]
+	if loss == ErrOverflow {
+		counts.Overflow++
+	} else {
+		counts.PrecisionLoss++
+	}
+	r.columns[column] = counts
+}
+
+// WithNumericGuard makes the struct mapper check the values of integer and float fields
+// (and pointers to them) for overflow and precision loss, e.g. a decimal column
+// in an int32 or float32 field. Text values are checked with their exact decimal value.
+//
+// If report is nil, an error wrapping [ErrOverflow] or [ErrPrecisionLoss] is returned for the row.
+// Otherwise, the loss is recorded in the report and the field is set to the nearest value
+// that fits, truncated towards zero for integers.
+//
+//	report := &scan.NumericReport{}
+//	m := scan.StructMapper[Payment](scan.WithNumericGuard(report))
+//	// ... after the query
+//	for column, loss := range report.Columns() { ... }
+func WithNumericGuard(report *NumericReport) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.numericGuard = true
+		opt.numericReport = report
+	}
+}
+
+// withNumericGuard sets a decoder that checks the values of the numeric fields
+// of the mapping that do not have a decoder
+func withNumericGuard(m mapping, report *NumericReport, w warner) {
+	for i, info := range m {
+		if info.decode != nil || info.typ == nil {
+			continue
+		}
+
+		typ := info.typ
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		if !isNumeric(typ) {
+			continue
+		}
+
+		column := info.name
+		m[i].decode = func(src any, dest any) error {
+			v := reflect.ValueOf(dest).Elem()
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+
+			loss, err := setNumeric(v, src)
+			if err != nil || loss == nil {
+				return err
+			}
+
+			if report == nil {
+				return fmt.Errorf("%w: %v into %s", loss, src, v.Type())
+			}
+
+			report.record(column, loss)
+			w.warn(WarningLossyConversion, column, "", "%v: %v into %s", loss, src, v.Type())
+			return nil
+		}
+	}
+}
+
+// isNumeric reports if the type is an integer or a float that is not a scanner itself
+func isNumeric(typ reflect.Type) bool {
+	if reflect.PtrTo(typ).Implements(scannerType) {
+		return false
+	}
+
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+
+	return false
+}
+
+// setNumeric sets the value of the column into v. The loss is
+// [ErrOverflow] or [ErrPrecisionLoss] if the value does not fit exactly
+func setNumeric(v reflect.Value, src any) (loss error, err error) {
+	exact, ok := exactValue(src)
+	if !ok {
+		// Other values are converted as usual
+		return nil, opt.ConvertAssign(v.Addr().Interface(), src)
+	}
+
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return setFloat(v, exact, src), nil
+	default:
+		return setInteger(v, exact), nil
+	}
+}
+
+// exactValue returns the exact value of numbers and decimal text
+func exactValue(src any) (*big.Rat, bool) {
+	switch src := src.(type) {
+	case int64:
+		return new(big.Rat).SetInt64(src), true
+	case uint64:
+		return new(big.Rat).SetUint64(src), true
+	case float64:
+		if math.IsInf(src, 0) || math.IsNaN(src) {
+			return nil, false
+		}
+		return new(big.Rat).SetFloat64(src), true
+	case float32:
+		return exactValue(float64(src))
+	case []byte:
+		return new(big.Rat).SetString(string(src))
+	case string:
+		return new(big.Rat).SetString(src)
+	}
+
+	v := reflect.ValueOf(src)
+	switch {
+	case v.CanInt():
+		return new(big.Rat).SetInt64(v.Int()), true
+	case v.CanUint():
+		return new(big.Rat).SetUint64(v.Uint()), true
+	}
+
+	return nil, false
+}
+
+func setInteger(v reflect.Value, exact *big.Rat) error {
+	var loss error
+	whole := new(big.Int).Quo(exact.Num(), exact.Denom())
+	if !exact.IsInt() {
+		loss = ErrPrecisionLoss
+	}
+
+	if v.CanInt() {
+		bits := v.Type().Bits()
+		lo := new(big.Int).Lsh(big.NewInt(-1), uint(bits-1))
+		hi := new(big.Int).Sub(new(big.Int).Neg(lo), big.NewInt(1))
+		whole, loss = clamp(whole, lo, hi, loss)
+		v.SetInt(whole.Int64())
+		return loss
+	}
+
+	hi := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), uint(v.Type().Bits())), big.NewInt(1))
+	whole, loss = clamp(whole, new(big.Int), hi, loss)
+	v.SetUint(whole.Uint64())
+	return loss
+}
+
+// clamp returns the nearest value in [lo, hi], and [ErrOverflow] if it is out of range
+func clamp(n, lo, hi *big.Int, kind error) (*big.Int, error) {
+	switch {
+	case n.Cmp(lo) < 0:
+		return lo, ErrOverflow
+	case n.Cmp(hi) > 0:
+		return hi, ErrOverflow
+	}
+
+	return n, kind
+}
+
+func setFloat(v reflect.Value, exact *big.Rat, src any) error {
+	bits := v.Type().Bits()
+
+	var f float64
+	if bits == 32 {
+		f32, _ := exact.Float32()
+		f = float64(f32)
+	} else {
+		f, _ = exact.Float64()
+	}
+
+	if math.IsInf(f, 0) {
+		max := math.MaxFloat64
+		if bits == 32 {
+			max = math.MaxFloat32
+		}
+		v.SetFloat(math.Copysign(max, f))
+		return ErrOverflow
+	}
+
+	v.SetFloat(f)
+
+	// Values are compared with their shortest decimal representation,
+	// so that decimals such as 0.1 are not reported
+	source := exact
+	switch src := src.(type) {
+	case float64:
+		source, _ = new(big.Rat).SetString(strconv.FormatFloat(src, 'g', -1, 64))
+	case float32:
+		source, _ = new(big.Rat).SetString(strconv.FormatFloat(float64(src), 'g', -1, 32))
+	}
+
+	shortest, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, bits))
+	if shortest.Cmp(source) != 0 {
+		return ErrPrecisionLoss
+	}
+
+	return nil
+}
+
+// IntegerOverflow decides what the struct mapper does when an integer value
+// does not fit in the size of its field, e.g. a BIGINT in an int32 or in an int on 32-bit platforms.
+// See [WithIntegerOverflow]
+type IntegerOverflow int
+
+const (
+	// IntegerOverflowError returns an error wrapping [ErrOverflow]
+	IntegerOverflowError IntegerOverflow = iota
+	// IntegerOverflowSaturate sets the field to the nearest value that fits
+	IntegerOverflowSaturate
+	// IntegerOverflowWrap keeps the low bits of the value, as a Go conversion does
+	IntegerOverflowWrap
+)
+
+// IntegerOverflowHook is called with the column, the value and the type of the field
+// when an integer value does not fit in its field, e.g. to log a warning
+type IntegerOverflowHook func(column string, value any, typ reflect.Type)
+
+// IntegerOverflowFor returns the usual strategy for the dialect.
+// MySQL saturates, the same as MySQL itself does with out of range values
+// when it is not in strict mode, and the other dialects return an error
+func IntegerOverflowFor(d Dialect) IntegerOverflow {
+	if d == MySQL {
+		return IntegerOverflowSaturate
+	}
+
+	return IntegerOverflowError
+}
+
+// WithIntegerOverflow sets how integer values that do not fit in their integer fields
+// (and pointers to them) are handled, instead of the error returned by the driver.
+// The hook, if not nil, is called for each value that does not fit.
+// Fields checked by [WithNumericGuard] are not affected.
+//
+//	m := scan.StructMapper[Event](scan.WithIntegerOverflow(scan.IntegerOverflowFor(scan.MySQL),
+//	    func(column string, value any, typ reflect.Type) {
+//	        log.Printf("%s: %v does not fit in %s", column, value, typ)
+//	    },
+//	))
+func WithIntegerOverflow(mode IntegerOverflow, hook IntegerOverflowHook) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.integerOverflow = &integerOverflow{mode: mode, hook: hook}
+	}
+}
+
+type integerOverflow struct {
+	mode IntegerOverflow
+	hook IntegerOverflowHook
+}
+
+// withIntegerOverflow sets a decoder that handles the integer values that do not fit
+// in the integer fields of the mapping that do not have a decoder
+func withIntegerOverflow(m mapping, o integerOverflow, w warner) {
+	for i, info := range m {
+		if info.decode != nil || info.typ == nil {
+			continue
+		}
+
+		typ := info.typ
+		if typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+
+		if !isNumeric(typ) || typ.Kind() == reflect.Float32 || typ.Kind() == reflect.Float64 {
+			continue
+		}
+
+		column := info.name
+		m[i].decode = func(src any, dest any) error {
+			v := reflect.ValueOf(dest).Elem()
+			if v.Kind() == reflect.Pointer {
+				if v.IsNil() {
+					v.Set(reflect.New(v.Type().Elem()))
+				}
+				v = v.Elem()
+			}
+
+			exact, ok := exactValue(src)
+			if !ok || !exact.IsInt() {
+				// Other values are converted as usual
+				return opt.ConvertAssign(v.Addr().Interface(), src)
+			}
+
+			if setInteger(v, exact) == nil {
+				return nil
+			}
+
+			if o.hook != nil {
+				o.hook(column, src, v.Type())
+			}
+
+			switch o.mode {
+			case IntegerOverflowSaturate:
+				w.warn(WarningLossyConversion, column, "", "%v: %v saturated into %s", ErrOverflow, src, v.Type())
+				return nil
+			case IntegerOverflowWrap:
+				wrapInteger(v, exact.Num())
+				w.warn(WarningLossyConversion, column, "", "%v: %v wrapped into %s", ErrOverflow, src, v.Type())
+				return nil
+			default:
+				return fmt.Errorf("%w: %v into %s", ErrOverflow, src, v.Type())
+			}
+		}
+	}
+}
+
+// wrapInteger sets the low bits of n into the integer v, as a Go conversion does
+func wrapInteger(v reflect.Value, n *big.Int) {
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(1))
+	low := new(big.Int).And(n, mask).Uint64()
+
+	if v.CanInt() {
+		v.SetInt(int64(low))
+		return
+	}
+
+	v.SetUint(low)
+}
@@ -0,0 +1,140 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Payment struct {
+	ID     int8
+	Amount int32
+	Rate   float32
+	Fee    *uint16
+	Total  float64
+}
+
+func TestNumericGuard(t *testing.T) {
+	ctx := context.Background()
+	columns := []string{"id", "amount", "rate", "fee", "total"}
+
+	rows := newSliceRows(columns,
+		[]any{int64(1), "100", 0.25, int64(5), "0.1"},
+		[]any{int64(300), "12.75", 0.1234567890123, int64(-1), "12345678901234567890.12"},
+		[]any{int64(3), []byte("3e10"), "1e39", nil, int64(9007199254740993)},
+	)
+
+	report := &NumericReport{}
+	payments, err := AllFromRows(ctx, StructMapper[Payment](WithNumericGuard(report)), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Payment{
+		{ID: 1, Amount: 100, Rate: 0.25, Fee: toPtr[uint16](5), Total: 0.1},
+		{ID: 127, Amount: 12, Rate: 0.12345679, Fee: toPtr[uint16](0), Total: 12345678901234567890.12},
+		{ID: 3, Amount: 2147483647, Rate: 3.4028234663852886e+38, Total: 9007199254740992},
+	}
+	if diff := cmp.Diff(expected, payments); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	losses := map[string]NumericLoss{
+		"id":     {Overflow: 1},
+		"amount": {Overflow: 1, PrecisionLoss: 1},
+		"rate":   {Overflow: 1, PrecisionLoss: 1},
+		"fee":    {Overflow: 1},
+		"total":  {PrecisionLoss: 2},
+	}
+	if diff := cmp.Diff(losses, report.Columns()); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	strict := StructMapper[Payment](WithNumericGuard(nil))
+	rows = newSliceRows(columns, []any{int64(1), "1.5", 0.5, int64(1), 1.0})
+	if _, err := AllFromRows(ctx, strict, rows); !errors.Is(err, ErrPrecisionLoss) || !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrPrecisionLoss, got %v", err)
+	}
+
+	rows = newSliceRows(columns, []any{int64(128), "1", 0.5, int64(1), 1.0})
+	if _, err := AllFromRows(ctx, strict, rows); !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestIntegerOverflow(t *testing.T) {
+	type counters struct {
+		Small  int8
+		Count  *uint16
+		Total  int64
+		Amount float32
+	}
+
+	newRows := func() Rows {
+		return newSliceRows([]string{"small", "count", "total", "amount"},
+			[]any{int64(1), int64(2), "3", 1.5},
+			[]any{int64(200), int64(-1), int64(math.MaxInt64), 2.5},
+			[]any{[]byte("-129"), uint64(70000), nil, 0.0},
+		)
+	}
+
+	var warnings []string
+	hook := func(column string, value any, typ reflect.Type) {
+		warnings = append(warnings, fmt.Sprintf("%s=%v:%s", column, value, typ))
+	}
+
+	cases := map[string]struct {
+		mode     IntegerOverflow
+		expected []counters
+	}{
+		"saturate": {
+			mode: IntegerOverflowSaturate,
+			expected: []counters{
+				{Small: 1, Count: toPtr(uint16(2)), Total: 3, Amount: 1.5},
+				{Small: 127, Count: toPtr(uint16(0)), Total: math.MaxInt64, Amount: 2.5},
+				{Small: -128, Count: toPtr(uint16(math.MaxUint16))},
+			},
+		},
+		"wrap": {
+			mode: IntegerOverflowWrap,
+			expected: []counters{
+				{Small: 1, Count: toPtr(uint16(2)), Total: 3, Amount: 1.5},
+				{Small: -56, Count: toPtr(uint16(math.MaxUint16)), Total: math.MaxInt64, Amount: 2.5},
+				{Small: 127, Count: toPtr(uint16(4464))},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			warnings = nil
+			res, err := AllFromRows(context.Background(), StructMapper[counters](WithIntegerOverflow(tc.mode, hook)), newRows())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(tc.expected, res); diff != "" {
+				t.Fatalf("diff: %s", diff)
+			}
+
+			expected := []string{"small=200:int8", "count=-1:uint16", "small=[45 49 50 57]:int8", "count=70000:uint16"}
+			if diff := cmp.Diff(expected, warnings); diff != "" {
+				t.Fatalf("diff: %s", diff)
+			}
+		})
+	}
+
+	_, err := AllFromRows(context.Background(), StructMapper[counters](WithIntegerOverflow(IntegerOverflowError, nil)), newRows())
+	if !errors.Is(err, ErrOverflow) {
+		t.Fatalf("expected ErrOverflow, got %v", err)
+	}
+
+	if IntegerOverflowFor(MySQL) != IntegerOverflowSaturate || IntegerOverflowFor(Postgres) != IntegerOverflowError {
+		t.Fatal("unexpected default for the dialect")
+	}
+}
@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrUnordered is returned by the mods from [Ascending] and [Descending]
+// when a row is out of order
+var ErrUnordered = errors.New("rows are out of order")
+
+type ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Ascending returns a [MapperMod] that returns [ErrUnordered] if the rows of a query
+// are not in ascending order of their keys. Rows with equal keys are allowed.
+// Use it to catch a missing ORDER BY that pagination or merging depends on.
+// T must be the type returned by the mapper
+//
+//	m := scan.StructMapper[User](scan.WithMapperMods(
+//	    scan.Ascending(func(u User) int { return u.ID }),
+//	))
+func Ascending[T any, K ordered](key func(T) K) MapperMod {
+	return orderMod(key, func(prev, next K) bool { return prev <= next })
+}
+
+// Descending is like [Ascending] for rows in descending order of their keys
+func Descending[T any, K ordered](key func(T) K) MapperMod {
+	return orderMod(key, func(prev, next K) bool { return prev >= next })
+}
+
+// orderState is the last key of a run of the query
+type orderState[K ordered] struct {
+	prev    K
+	started bool
+}
+
+func orderMod[T any, K ordered](key func(T) K, inOrder func(prev, next K) bool) MapperMod {
+	return func(ctx context.Context, c cols) (BeforeFunc, AfterMod) {
+		// The last key is kept on the *Row of each run of the query,
+		// since the mapping can be reused by concurrent runs
+		stateKey := new(int)
+
+		return func(r *Row) (any, error) {
+				return r, nil
+			}, func(link, retrieved any) error {
+				v, ok := retrieved.(T)
+				if !ok {
+					return fmt.Errorf("order check expected %T, got %T", v, retrieved)
+				}
+
+				next := key(v)
+				r, ok := link.(*Row)
+				if !ok {
+					return nil
+				}
+
+				state := runState(r, stateKey, func() *orderState[K] { return &orderState[K]{} })
+				if state.started && !inOrder(state.prev, next) {
+					return fmt.Errorf("%w: %v after %v", ErrUnordered, next, state.prev)
+				}
+
+				state.prev, state.started = next, true
+				return nil
+			}
+	}
+}
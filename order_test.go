@@ -0,0 +1,71 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOrderedRows(t *testing.T) {
+	ctx := context.Background()
+	byID := func(u User) int { return u.ID }
+
+	ascending := StructMapper[User](WithMapperMods(Ascending(byID)))
+	descending := StructMapper[User](WithMapperMods(Descending(byID)))
+
+	if _, err := All(ctx, funcQ(usersByID), ascending, "", 1, 2, 2, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := All(ctx, funcQ(usersByID), descending, "", 3, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := All(ctx, funcQ(usersByID), ascending, "", 1, 3, 2); !errors.Is(err, ErrUnordered) {
+		t.Fatalf("expected ErrUnordered, got %v", err)
+	}
+
+	if _, err := All(ctx, funcQ(usersByID), descending, "", 1, 2); !errors.Is(err, ErrUnordered) {
+		t.Fatalf("expected ErrUnordered, got %v", err)
+	}
+
+	// Each run of a prepared query is checked on its own
+	q, err := Prepare(ctx, funcQ(usersByID), ascending, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := q.All(ctx, 5, 6); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Concurrent runs that share the mapping are checked on their own
+	before, after := ascending(ctx, []string{"id", "name"})
+	run := func(ids ...any) *Row {
+		rows, _ := usersByID(ctx, "", ids...)
+		v, err := wrapRows(rows, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return v
+	}
+	next := func(v *Row) error {
+		v.r.Next()
+		_, err := mapOneRow(v, before, after)
+		return err
+	}
+
+	a, b := run(1, 3, 2), run(5, 6)
+	for _, v := range []*Row{a, b, a, b} {
+		if err := next(v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := next(a); !errors.Is(err, ErrUnordered) {
+		t.Fatalf("expected ErrUnordered for the interleaved run, got %v", err)
+	}
+}
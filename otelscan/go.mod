@@ -0,0 +1,18 @@
+module github.com/stephenafamo/scan/otelscan
+
+go 1.18
+
+require (
+	github.com/google/go-cmp v0.5.9
+	github.com/stephenafamo/scan v0.7.0
+	go.opentelemetry.io/otel v1.11.2
+	go.opentelemetry.io/otel/trace v1.11.2
+)
+
+require (
+	github.com/aarondl/json v0.0.0-20221020222930-8b0db17ef1bf // indirect
+	github.com/aarondl/opt v0.0.0-20221129170750-3d40c96d9bb8 // indirect
+	github.com/go-logr/logr v1.2.3 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	golang.org/x/text v0.3.8 // indirect
+)
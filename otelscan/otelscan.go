@@ -0,0 +1,145 @@
+// Package otelscan instruments a [scan.Queryer] with OpenTelemetry spans
+package otelscan
+
+import (
+	"context"
+	"time"
+
+	"github.com/stephenafamo/scan"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/stephenafamo/scan/otelscan"
+
+// Attributes set on the spans in addition to the query text
+const (
+	// TypeKey is the type the rows are mapped to, if it is known
+	TypeKey = attribute.Key("scan.type")
+	// RowsKey is the number of rows that were mapped without an error
+	RowsKey = attribute.Key("scan.rows")
+	// MappingDurationKey is the time spent building the mapper for the columns of the query, in milliseconds.
+	// It is 0 when a prepared query reuses its cached mapping
+	MappingDurationKey = attribute.Key("scan.mapping_duration_ms")
+)
+
+// Option changes how queries are instrumented
+type Option func(*config)
+
+type config struct {
+	provider trace.TracerProvider
+	spanName string
+	attrs    []attribute.KeyValue
+}
+
+// WithTracerProvider sets the provider of the tracer used to create spans.
+// The default is the global provider from [otel.GetTracerProvider]
+func WithTracerProvider(provider trace.TracerProvider) Option {
+	return func(c *config) {
+		c.provider = provider
+	}
+}
+
+// WithSpanName sets the name of the spans. The default is "scan.query"
+func WithSpanName(name string) Option {
+	return func(c *config) {
+		c.spanName = name
+	}
+}
+
+// WithAttributes adds attributes to every span, e.g. the database system
+//
+//	otelscan.WithAttributes(semconv.DBSystemPostgreSQL)
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(c *config) {
+		c.attrs = append(c.attrs, attrs...)
+	}
+}
+
+// Wrap returns a [scan.Queryer] that creates a span for each query run with q, see [Hook].
+// The hooks of q are kept and called after the span is started,
+// and if q can prepare statements, the returned Queryer can too.
+//
+//	users, err := scan.All(ctx, otelscan.Wrap(stdscan.Wrap(db)), scan.StructMapper[User](), query)
+func Wrap(q scan.Queryer, opts ...Option) scan.Queryer {
+	return scan.WithHooks(q, Hook(opts...))
+}
+
+// Hook returns a [scan.Hook] that creates a span for each query.
+// The span has the query text and the type of the rows, and once the rows are closed,
+// the number of rows and the time spent building the mapper.
+// Errors from the query or the rows are recorded on the span
+//
+//	exec := scan.WithHooks(stdscan.Wrap(db), otelscan.Hook(), auditHook)
+func Hook(opts ...Option) scan.Hook {
+	c := config{spanName: "scan.query"}
+	for _, o := range opts {
+		o(&c)
+	}
+
+	if c.provider == nil {
+		c.provider = otel.GetTracerProvider()
+	}
+
+	return hook{
+		tracer: c.provider.Tracer(instrumentationName),
+		config: c,
+	}
+}
+
+// ctxKeySpan holds the span of the query and what is known about it
+type ctxKeySpan struct{}
+
+type querySpan struct {
+	span    trace.Span
+	mapping time.Duration
+}
+
+type hook struct {
+	tracer trace.Tracer
+	config
+}
+
+func (h hook) Handle(ctx context.Context, e scan.Event) context.Context {
+	switch e := e.(type) {
+	case scan.QueryStart:
+		attrs := make([]attribute.KeyValue, 0, len(h.attrs)+2)
+		attrs = append(attrs, semconv.DBStatementKey.String(e.Query))
+		if e.Type != nil {
+			attrs = append(attrs, TypeKey.String(e.Type.String()))
+		}
+		attrs = append(attrs, h.attrs...)
+
+		ctx, span := h.tracer.Start(ctx, h.spanName,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attrs...),
+		)
+		return context.WithValue(ctx, ctxKeySpan{}, &querySpan{span: span})
+
+	case scan.MappingBuilt:
+		if s, ok := ctx.Value(ctxKeySpan{}).(*querySpan); ok {
+			s.mapping += e.Duration
+		}
+
+	case scan.ScanEnd:
+		s, ok := ctx.Value(ctxKeySpan{}).(*querySpan)
+		if !ok {
+			return ctx
+		}
+
+		s.span.SetAttributes(
+			RowsKey.Int(e.Rows),
+			MappingDurationKey.Float64(float64(s.mapping)/float64(time.Millisecond)),
+		)
+		if e.Err != nil {
+			s.span.RecordError(e.Err)
+			s.span.SetStatus(codes.Error, e.Err.Error())
+		}
+		s.span.End()
+	}
+
+	return ctx
+}
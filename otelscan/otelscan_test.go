@@ -0,0 +1,199 @@
+package otelscan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stephenafamo/scan"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordedSpan records the name, attributes and status of a span
+type recordedSpan struct {
+	trace.Span
+	name   string
+	attrs  map[attribute.Key]attribute.Value
+	status codes.Code
+	ended  bool
+}
+
+func (s *recordedSpan) SetAttributes(kv ...attribute.KeyValue) {
+	for _, a := range kv {
+		s.attrs[a.Key] = a.Value
+	}
+}
+
+func (s *recordedSpan) SetStatus(code codes.Code, _ string)     { s.status = code }
+func (s *recordedSpan) RecordError(error, ...trace.EventOption) {}
+func (s *recordedSpan) End(...trace.SpanEndOption)              { s.ended = true }
+
+// recordingProvider is a tracer provider and tracer that records the spans it starts
+type recordingProvider struct {
+	spans []*recordedSpan
+}
+
+func (p *recordingProvider) Tracer(string, ...trace.TracerOption) trace.Tracer {
+	return p
+}
+
+func (p *recordingProvider) Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	span := &recordedSpan{
+		Span:  trace.SpanFromContext(ctx),
+		name:  name,
+		attrs: make(map[attribute.Key]attribute.Value),
+	}
+	cfg := trace.NewSpanStartConfig(opts...)
+	span.SetAttributes(cfg.Attributes()...)
+	p.spans = append(p.spans, span)
+
+	return trace.ContextWithSpan(ctx, span), span
+}
+
+type queryFunc func(ctx context.Context, query string, args ...any) (scan.Rows, error)
+
+func (f queryFunc) QueryContext(ctx context.Context, query string, args ...any) (scan.Rows, error) {
+	return f(ctx, query, args...)
+}
+
+// intRows returns the values in a single column
+type intRows struct {
+	values []int
+	index  int
+	err    error
+}
+
+func (r *intRows) Scan(dest ...any) error {
+	*dest[0].(*int) = r.values[r.index-1]
+	return nil
+}
+
+func (r *intRows) Columns() ([]string, error) { return []string{"n"}, nil }
+func (r *intRows) Close() error               { return nil }
+func (r *intRows) Err() error                 { return r.err }
+
+func (r *intRows) Next() bool {
+	if r.index >= len(r.values) {
+		return false
+	}
+
+	r.index++
+	return true
+}
+
+func TestWrap(t *testing.T) {
+	provider := &recordingProvider{}
+	ctx := context.Background()
+
+	var rowsErr error
+	exec := Wrap(queryFunc(func(ctx context.Context, query string, args ...any) (scan.Rows, error) {
+		if trace.SpanFromContext(ctx) != provider.spans[len(provider.spans)-1] {
+			t.Fatal("expected the query to run with the span in the context")
+		}
+		if query == "fail" {
+			return nil, errors.New("failed")
+		}
+		return &intRows{values: []int{1, 2, 3}, err: rowsErr}, nil
+	}), WithTracerProvider(provider), WithAttributes(attribute.String("db.system", "fake")))
+
+	got, err := scan.All(ctx, exec, scan.SingleColumnMapper[int], "SELECT n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 3}, got); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	span := provider.spans[0]
+	if !span.ended || span.name != "scan.query" || span.status != codes.Unset {
+		t.Fatalf("unexpected span %+v", span)
+	}
+
+	if span.attrs["db.statement"].AsString() != "SELECT n" ||
+		span.attrs["db.system"].AsString() != "fake" ||
+		span.attrs[RowsKey].AsInt64() != 3 {
+		t.Fatalf("unexpected attributes %v", span.attrs)
+	}
+
+	if _, ok := span.attrs[MappingDurationKey]; !ok || span.attrs[TypeKey].AsString() != "int" {
+		t.Fatalf("expected the mapping duration and type, got %v", span.attrs)
+	}
+
+	if _, err := scan.All(ctx, exec, scan.SingleColumnMapper[int], "fail"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	rowsErr = errors.New("rows failed")
+	if _, err := scan.All(ctx, exec, scan.SingleColumnMapper[int], "SELECT n"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, span := range provider.spans[1:] {
+		if !span.ended || span.status != codes.Error {
+			t.Fatalf("expected the error to be recorded, got %+v", span)
+		}
+	}
+}
+
+// preparer is a queryer that can prepare statements
+type preparer struct {
+	queryFunc
+}
+
+func (p preparer) PrepareContext(ctx context.Context, query string) (scan.Stmt, error) {
+	return stmt{query: query, q: p.queryFunc}, nil
+}
+
+type stmt struct {
+	query string
+	q     queryFunc
+}
+
+func (s stmt) QueryContext(ctx context.Context, args ...any) (scan.Rows, error) {
+	return s.q(ctx, s.query, args...)
+}
+
+func (s stmt) Close() error { return nil }
+
+func TestWrapKeepsQueryer(t *testing.T) {
+	provider := &recordingProvider{}
+	ctx := context.Background()
+
+	var events int
+	inner := scan.WithHooks(preparer{queryFunc(func(ctx context.Context, query string, args ...any) (scan.Rows, error) {
+		return &intRows{values: []int{1, 2}}, nil
+	})}, scan.HookFunc(func(ctx context.Context, e scan.Event) context.Context {
+		if trace.SpanFromContext(ctx) != provider.spans[len(provider.spans)-1] {
+			t.Fatal("expected the inner hooks to get the span in the context")
+		}
+		events++
+		return ctx
+	}))
+
+	exec := Wrap(inner, WithTracerProvider(provider))
+	if _, ok := exec.(scan.Preparer); !ok {
+		t.Fatal("expected the wrapped queryer to prepare statements")
+	}
+
+	p, err := scan.Prepare(ctx, exec, scan.SingleColumnMapper[int], "SELECT n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Close()
+
+	if _, err := p.All(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(provider.spans) != 1 || !provider.spans[0].ended || provider.spans[0].attrs[RowsKey].AsInt64() != 2 {
+		t.Fatalf("unexpected spans %+v", provider.spans)
+	}
+
+	if events == 0 {
+		t.Fatal("expected the hooks of the wrapped queryer to be called")
+	}
+}
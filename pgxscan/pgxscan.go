@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stephenafamo/scan"
 )
 
@@ -14,12 +15,110 @@ func One[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string,
 	return scan.One(ctx, convert(exec), m, sql, args...)
 }
 
+// OneOrZero is like [One], but returns false instead of [scan.ErrNoRows] if the query returns no rows
+func OneOrZero[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (T, bool, error) {
+	return scan.OneOrZero(ctx, convert(exec), m, sql, args...)
+}
+
+// First scans the first row from the query and maps it to T, ignoring the other rows
+func First[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (T, error) {
+	return scan.First(ctx, convert(exec), m, sql, args...)
+}
+
+// FanOut runs the query concurrently on each of the Queryers, e.g. the shards of a database,
+// and returns the rows of all of them. See [scan.FanOut]
+func FanOut[T any](ctx context.Context, execs []Queryer, m scan.Mapper[T], sql string, args ...any) ([]T, error) {
+	converted := make([]scan.Queryer, len(execs))
+	for i, exec := range execs {
+		converted[i] = convert(exec)
+	}
+
+	return scan.FanOut(ctx, converted, m, sql, args...)
+}
+
+// Count runs a query that returns a single number, such as SELECT COUNT(*). See [scan.Count]
+func Count(ctx context.Context, exec Queryer, sql string, args ...any) (int64, error) {
+	return scan.Count(ctx, convert(exec), sql, args...)
+}
+
+// Exists runs a query that returns a single boolean, such as SELECT EXISTS (...). See [scan.Exists]
+func Exists(ctx context.Context, exec Queryer, sql string, args ...any) (bool, error) {
+	return scan.Exists(ctx, convert(exec), sql, args...)
+}
+
+// ExactlyOne is like [One], but returns [scan.ErrTooManyRows] if the query returns more than one row
+func ExactlyOne[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (T, error) {
+	return scan.ExactlyOne(ctx, convert(exec), m, sql, args...)
+}
+
 // All scans all rows from the query and returns a slice []T of all rows using a [StdQueryer] this is for use with *sql.DB, *sql.Tx or *sql.Conn or any similar implementations
 // that return *sql.Rows
 func All[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) ([]T, error) {
 	return scan.All(ctx, convert(exec), m, sql, args...)
 }
 
+// AllIndexed scans all rows from the query and returns them in a map keyed by keyFn
+func AllIndexed[K comparable, T any](ctx context.Context, exec Queryer, m scan.Mapper[T], keyFn func(T) K, sql string, args ...any) (map[K]T, error) {
+	return scan.AllIndexed(ctx, convert(exec), m, keyFn, sql, args...)
+}
+
+// AllWhile scans the rows from the query while keep returns true. See [scan.AllWhile]
+func AllWhile[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], keep func(T) bool, sql string, args ...any) ([]T, error) {
+	return scan.AllWhile(ctx, convert(exec), m, keep, sql, args...)
+}
+
+// Stream sends the rows from the query on a channel as they are read. See [scan.Stream]
+func Stream[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (<-chan T, <-chan error) {
+	return scan.Stream(ctx, convert(exec), m, sql, args...)
+}
+
+// Chunks runs the query and calls fn with the rows in batches of size. See [scan.Chunks]
+func Chunks[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], size int, fn func([]T) error, sql string, args ...any) error {
+	return scan.Chunks(ctx, convert(exec), m, size, fn, sql, args...)
+}
+
+// Grouped folds joined rows into parents with their children using [scan.Group]
+func Grouped[P any, C any, K comparable](ctx context.Context, exec Queryer, g scan.Group[P, C, K], sql string, args ...any) ([]P, error) {
+	return scan.Grouped(ctx, convert(exec), g, sql, args...)
+}
+
+// Faceted splits the data rows and facet count rows of the query using [scan.FacetColumns]
+func Faceted[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], fc scan.FacetColumns, sql string, args ...any) ([]T, scan.FacetCounts, error) {
+	return scan.Faceted(ctx, convert(exec), m, fc, sql, args...)
+}
+
+// OneWithOptions is like [One] but applies the [scan.MappingOption]s
+// to the struct mapper for only this query
+func OneWithOptions[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], opts []scan.MappingOption, sql string, args ...any) (T, error) {
+	return scan.OneWithOptions(ctx, convert(exec), m, opts, sql, args...)
+}
+
+// AllWithOptions is like [All] but applies the [scan.MappingOption]s
+// to the struct mapper for only this query
+func AllWithOptions[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], opts []scan.MappingOption, sql string, args ...any) ([]T, error) {
+	return scan.AllWithOptions(ctx, convert(exec), m, opts, sql, args...)
+}
+
+// Collect runs the query and collects several values from each row. See [scan.Collect]
+func Collect(ctx context.Context, exec Queryer, collector func(context.Context, []string) any, sql string, args ...any) ([]any, error) {
+	return scan.Collect(ctx, convert(exec), collector, sql, args...)
+}
+
+// CollectOf runs the query and returns the value extracted from each row by the [scan.Collector]
+func CollectOf[T any](ctx context.Context, exec Queryer, c scan.Collector[T], sql string, args ...any) ([]T, error) {
+	return scan.CollectOf(ctx, convert(exec), c, sql, args...)
+}
+
+// Collect2 runs the query and returns the two values extracted from each row. See [scan.Collect2]
+func Collect2[A, B any](ctx context.Context, exec Queryer, c scan.Collector2[A, B], sql string, args ...any) ([]A, []B, error) {
+	return scan.Collect2(ctx, convert(exec), c, sql, args...)
+}
+
+// Collect3 runs the query and returns the three values extracted from each row. See [scan.Collect3]
+func Collect3[A, B, C any](ctx context.Context, exec Queryer, c scan.Collector3[A, B, C], sql string, args ...any) ([]A, []B, []C, error) {
+	return scan.Collect3(ctx, convert(exec), c, sql, args...)
+}
+
 // Cursor returns a cursor that works similar to *sql.Rows
 func Cursor[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (scan.ICursor[T], error) {
 	return scan.Cursor(ctx, convert(exec), m, sql, args...)
@@ -30,6 +129,12 @@ type Queryer interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 }
 
+// Wrap converts a [Queryer] such as *pgxpool.Pool into a [scan.Queryer]
+// to use it with the functions in the base scan package
+func Wrap(exec Queryer) scan.Queryer {
+	return convert(exec)
+}
+
 // convert wraps an Queryer and makes it a Queryer
 func convert(wrapped Queryer) scan.Queryer {
 	return queryer{wrapped: wrapped}
@@ -64,3 +169,10 @@ func (q queryer) QueryContext(ctx context.Context, query string, args ...any) (s
 	r, err := q.wrapped.Query(ctx, query, args...)
 	return rows{r}, err
 }
+
+// IsRetryable reports if the error is transient, with the rules of [scan.IsRetryable]
+// and the errors that pgx knows are safe to retry because nothing was sent to the server.
+// Use it in [scan.RetryPolicy]
+func IsRetryable(err error) bool {
+	return scan.IsRetryable(err) || pgconn.SafeToRetry(err)
+}
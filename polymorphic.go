@@ -0,0 +1,153 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// WithPolymorphicMapping makes the struct mapper for an interface type create
+// one of several concrete types for each row, selected by the value of the discriminator column,
+// e.g. for tables with single table inheritance. Each type must implement the interface,
+// and is mapped with the other options of the mapper.
+// Columns that are not mapped to a field of the selected type are ignored,
+// since the table has the columns of every type. A value that is not registered returns an error
+//
+//	vehicles, err := scan.All(ctx, db, scan.StructMapper[Vehicle](
+//	    scan.WithPolymorphicMapping("kind", map[string]reflect.Type{
+//	        "car":  reflect.TypeOf(&Car{}),
+//	        "boat": reflect.TypeOf(&Boat{}),
+//	    }),
+//	), "SELECT * FROM vehicles")
+func WithPolymorphicMapping(column string, types map[string]reflect.Type) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.polymorphic = &polymorphicMapping{column: column, types: types}
+	}
+}
+
+type polymorphicMapping struct {
+	column string
+	types  map[string]reflect.Type
+}
+
+// concreteMapper is the mapper of a concrete type of a polymorphic mapping
+type concreteMapper struct {
+	before func(*Row) (any, error)
+	after  func(any) (any, error)
+}
+
+func polymorphicMapper[T any](ctx context.Context, c cols, s StructMapperSource, opts mappingOptions) (func(*Row) (any, error), func(any) (T, error)) {
+	typ := typeOf[T]()
+	p := opts.polymorphic
+
+	if typ.Kind() != reflect.Interface {
+		err := fmt.Errorf("Polymorphic mapping needs an interface type, not %s", typ)
+		return ErrorMapper[T](err, "polymorphic", typ.String())
+	}
+
+	discriminator := -1
+	for i, name := range c {
+		if name == p.column {
+			discriminator = i
+			break
+		}
+	}
+
+	if discriminator < 0 {
+		err := fmt.Errorf("No discriminator column %q for polymorphic mapping", p.column)
+		return ErrorMapper[T](columnError(ErrUnknownColumn, p.column, "", err, p.column))
+	}
+
+	// The concrete types are mapped with the same options, except
+	// for the ones that only apply to the interface type
+	inner := opts
+	inner.polymorphic = nil
+	inner.rowFactory = nil
+	inner.skipMapValues = true
+	inner.allowUnknown = true
+	// The columns of the other types are expected
+	inner.warnings = opts.warnings.except(WarningUnknownColumn)
+
+	depth := -1
+	if opts.maxDepth != nil {
+		depth = *opts.maxDepth
+	}
+
+	mappers := make(map[string]concreteMapper, len(p.types))
+	for value, ct := range p.types {
+		if ct == nil || !ct.Implements(typ) {
+			err := fmt.Errorf("Type %v for %q does not implement %s", ct, value, typ)
+			return ErrorMapper[T](err, "polymorphic", value)
+		}
+
+		isPointer, err := checks(ct)
+		if err != nil {
+			return ErrorMapper[T](err)
+		}
+
+		m, _, err := s.getMappingDepth(ct, depth)
+		if err != nil {
+			return ErrorMapper[T](err)
+		}
+
+		before, after := mapperFromMapping[any](m, ct, isPointer, inner)(ctx, c)
+		mappers[value] = concreteMapper{before: before, after: after}
+	}
+
+	// The row is read into values, and then mapped from them
+	// once the discriminator tells its type. Both are created for each row,
+	// since the mapping can be shared by concurrent queries
+	return func(v *Row) (any, error) {
+			values := newValues(c)
+			for i, name := range c {
+				v.scheduleIndex(i, name, "", reflect.ValueOf(values.dests[i]))
+			}
+
+			return values, nil
+		}, func(link any) (T, error) {
+			var t T
+			values := link.(*Values)
+
+			value, err := Get[string](values, p.column)
+			if err != nil {
+				return t, err
+			}
+
+			m, ok := mappers[value]
+			if !ok {
+				err := fmt.Errorf("No type for %q in the discriminator column %q", value, p.column)
+				return t, columnError(ErrConversion, p.column, "", err, "polymorphic", value)
+			}
+
+			row := &Row{
+				r:                &valuesRows{values: values},
+				columns:          c,
+				scanDestinations: make([]reflect.Value, len(c)),
+				allowUnknown:     true,
+			}
+
+			concrete, err := mapOneRow(row, m.before, m.after)
+			if err != nil {
+				return t, err
+			}
+
+			return concrete.(T), nil
+		}
+}
+
+// valuesRows returns the values that were already read from a row
+type valuesRows struct {
+	values *Values
+}
+
+func (r *valuesRows) Scan(dest ...any) error {
+	return r.values.assign(dest)
+}
+
+func (r *valuesRows) Columns() ([]string, error) {
+	return r.values.columns, nil
+}
+
+func (r *valuesRows) Next() bool   { return false }
+func (r *valuesRows) Close() error { return nil }
+func (r *valuesRows) Err() error   { return nil }
@@ -0,0 +1,125 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type vehicle interface {
+	Wheels() int
+}
+
+type car struct {
+	ID    int
+	Kind  string
+	Doors int
+}
+
+func (c car) Wheels() int { return 4 }
+
+type bike struct {
+	ID   int
+	Gear *int
+}
+
+func (b *bike) Wheels() int { return 2 }
+
+func TestPolymorphicMapping(t *testing.T) {
+	ctx := context.Background()
+	types := WithPolymorphicMapping("kind", map[string]reflect.Type{
+		"car":  reflect.TypeOf(car{}),
+		"bike": reflect.TypeOf(&bike{}),
+	})
+
+	newRows := func(kinds ...any) Rows {
+		rows := make([][]any, len(kinds))
+		for i, kind := range kinds {
+			rows[i] = []any{i + 1, kind, int64(i + 2), nil}
+		}
+		return newSliceRows([]string{"id", "kind", "doors", "gear"}, rows...)
+	}
+
+	for name, opts := range map[string][]MappingOption{
+		"default":        {types},
+		"type converter": {types, WithTypeConverter(NewConverters())},
+	} {
+		t.Run(name, func(t *testing.T) {
+			vehicles, err := AllFromRows(ctx, StructMapper[vehicle](opts...), newRows("car", "bike", "car"))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := []vehicle{
+				car{ID: 1, Kind: "car", Doors: 2},
+				&bike{ID: 2},
+				car{ID: 3, Kind: "car", Doors: 4},
+			}
+			if diff := cmp.Diff(expected, vehicles); diff != "" {
+				t.Fatalf("diff: %s", diff)
+			}
+		})
+	}
+
+	if _, err := AllFromRows(ctx, StructMapper[vehicle](types), newRows("car", "plane")); !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrConversion for an unknown type, got %v", err)
+	}
+
+	rows := newSliceRows([]string{"id"}, []any{1})
+	if _, err := AllFromRows(ctx, StructMapper[vehicle](types), rows); !errors.Is(err, ErrUnknownColumn) {
+		t.Fatalf("expected ErrUnknownColumn for a missing discriminator, got %v", err)
+	}
+
+	// The types must implement the interface
+	bad := WithPolymorphicMapping("kind", map[string]reflect.Type{"bike": reflect.TypeOf(bike{})})
+	if _, err := AllFromRows(ctx, StructMapper[vehicle](bad), newRows("bike")); err == nil {
+		t.Fatal("expected an error for a type that does not implement the interface")
+	}
+}
+
+func TestPolymorphicMappingConcurrent(t *testing.T) {
+	ctx := context.Background()
+	types := WithPolymorphicMapping("kind", map[string]reflect.Type{
+		"car":  reflect.TypeOf(car{}),
+		"bike": reflect.TypeOf(&bike{}),
+	})
+
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "kind", "doors", "gear"},
+			[]any{1, "car", int64(2), nil},
+			[]any{2, "bike", nil, nil},
+		), nil
+	})
+
+	// The cached mapping of a prepared query is shared by concurrent runs
+	p, err := Prepare(ctx, exec, StructMapper[vehicle](types), "SELECT * FROM vehicles")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []vehicle{car{ID: 1, Kind: "car", Doors: 2}, &bike{ID: 2}}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				vehicles, err := p.All(ctx)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				if diff := cmp.Diff(expected, vehicles); diff != "" {
+					t.Errorf("diff: %s", diff)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,155 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrEstimateExceeded is returned by a [PreFlight] queryer when the estimated
+// size of a result set is more than the configured limits
+var ErrEstimateExceeded = errors.New("estimated result size exceeds limit")
+
+// Estimate is the expected size of the result of a query
+type Estimate struct {
+	// Rows is the expected number of rows
+	Rows int64
+	// Width is the expected size of each row in bytes.
+	// It is 0 if the estimator cannot tell
+	Width int64
+}
+
+// Bytes is the expected size of the full result in bytes
+func (e Estimate) Bytes() int64 {
+	return e.Rows * e.Width
+}
+
+// Estimator is used by [PreFlight] to estimate the size of a query's result
+// before it is executed. If the width is 0, [PreFlight] uses the [EstimateRowSize]
+// of the type the rows are mapped to, when run by the scanning functions
+type Estimator = func(ctx context.Context, exec Queryer, query string, args ...any) (Estimate, error)
+
+// ExplainEstimator estimates the size of a query using the planner's estimate
+// from PostgreSQL's `EXPLAIN (FORMAT JSON)`
+func ExplainEstimator(ctx context.Context, exec Queryer, query string, args ...any) (Estimate, error) {
+	plan, err := One(ctx, exec, SingleColumnMapper[[]byte], "EXPLAIN (FORMAT JSON) "+query, args...)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	var plans []struct {
+		Plan struct {
+			Rows  float64 `json:"Plan Rows"`
+			Width int64   `json:"Plan Width"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(plan, &plans); err != nil {
+		return Estimate{}, fmt.Errorf("parsing query plan: %w", err)
+	}
+
+	if len(plans) == 0 {
+		return Estimate{}, errors.New("parsing query plan: empty plan")
+	}
+
+	return Estimate{
+		Rows:  int64(plans[0].Plan.Rows),
+		Width: plans[0].Plan.Width,
+	}, nil
+}
+
+// CountEstimator estimates the size of a query by wrapping it in a `SELECT COUNT(*)`
+// This is exact, but runs the query twice so it should only be used
+// when the query is cheap to count. The width is always 0, so [PreFlight]
+// uses [EstimateRowSize] of the mapped type instead
+func CountEstimator(ctx context.Context, exec Queryer, query string, args ...any) (Estimate, error) {
+	count, err := One(ctx, exec, SingleColumnMapper[int64], "SELECT COUNT(*) FROM ("+query+") AS scan_preflight", args...)
+	if err != nil {
+		return Estimate{}, err
+	}
+
+	return Estimate{Rows: count}, nil
+}
+
+// PreFlightOption configures a [PreFlight] queryer
+type PreFlightOption func(*preFlightQueryer)
+
+// WithMaxRows rejects queries that are expected to return more than n rows
+func WithMaxRows(n int64) PreFlightOption {
+	return func(p *preFlightQueryer) {
+		p.maxRows = n
+	}
+}
+
+// WithMaxBytes rejects queries whose results are expected to be larger than n bytes
+func WithMaxBytes(n int64) PreFlightOption {
+	return func(p *preFlightQueryer) {
+		p.maxBytes = n
+	}
+}
+
+// WithPreFlightWarning makes the [PreFlight] queryer call warn instead of
+// rejecting the query when a limit is exceeded.
+// The error passed to warn wraps [ErrEstimateExceeded]
+func WithPreFlightWarning(warn func(ctx context.Context, query string, err error)) PreFlightOption {
+	return func(p *preFlightQueryer) {
+		p.warn = warn
+	}
+}
+
+// PreFlight wraps a [Queryer] and estimates the size of every query with the
+// given [Estimator] before running it.
+// Queries expected to exceed the configured limits return an error wrapping
+// [ErrEstimateExceeded] without being executed
+func PreFlight(q Queryer, e Estimator, opts ...PreFlightOption) Queryer {
+	p := preFlightQueryer{q: q, estimate: e}
+	for _, o := range opts {
+		o(&p)
+	}
+
+	return p
+}
+
+type preFlightQueryer struct {
+	q        Queryer
+	estimate Estimator
+	maxRows  int64
+	maxBytes int64
+	warn     func(ctx context.Context, query string, err error)
+}
+
+func (p preFlightQueryer) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	est, err := p.estimate(ctx, p.q, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("estimating result size: %w", err)
+	}
+
+	if est.Width == 0 {
+		// Fall back to the size of the type the rows are mapped to, if known
+		if typ, ok := ctx.Value(ctxKeyRowType).(reflect.Type); ok {
+			est.Width = estimateSize(typ)
+		}
+	}
+
+	if err := p.check(est); err != nil {
+		if p.warn == nil {
+			return nil, err
+		}
+		p.warn(ctx, query, err)
+	}
+
+	return p.q.QueryContext(ctx, query, args...)
+}
+
+func (p preFlightQueryer) check(est Estimate) error {
+	if p.maxRows > 0 && est.Rows > p.maxRows {
+		return fmt.Errorf("%w: expected %d rows, max %d", ErrEstimateExceeded, est.Rows, p.maxRows)
+	}
+
+	if p.maxBytes > 0 && est.Bytes() > p.maxBytes {
+		return fmt.Errorf("%w: expected %d bytes, max %d", ErrEstimateExceeded, est.Bytes(), p.maxBytes)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExplainEstimator(t *testing.T) {
+	var ran string
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		ran = query
+		return newSliceRows([]string{"QUERY PLAN"}, []any{
+			`[{"Plan": {"Node Type": "Seq Scan", "Plan Rows": 1200, "Plan Width": 36}}]`,
+		}), nil
+	})
+
+	est, err := ExplainEstimator(context.Background(), exec, "SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ran != "EXPLAIN (FORMAT JSON) SELECT * FROM users" {
+		t.Fatalf("wrong query: %s", ran)
+	}
+
+	if diff := cmp.Diff(Estimate{Rows: 1200, Width: 36}, est); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestCountEstimator(t *testing.T) {
+	var ran string
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		ran = query
+		return newSliceRows([]string{"count"}, []any{int64(42)}), nil
+	})
+
+	est, err := CountEstimator(context.Background(), exec, "SELECT * FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if ran != "SELECT COUNT(*) FROM (SELECT * FROM users) AS scan_preflight" {
+		t.Fatalf("wrong query: %s", ran)
+	}
+
+	if diff := cmp.Diff(Estimate{Rows: 42}, est); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestPreFlight(t *testing.T) {
+	var executed bool
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		executed = true
+		return newSliceRows([]string{"id"}), nil
+	})
+
+	estimator := func(ctx context.Context, exec Queryer, query string, args ...any) (Estimate, error) {
+		return Estimate{Rows: 100, Width: 10}, nil
+	}
+
+	cases := map[string]struct {
+		opts     []PreFlightOption
+		err      string
+		warned   bool
+		executed bool
+	}{
+		"no limits": {
+			executed: true,
+		},
+		"within limits": {
+			opts:     []PreFlightOption{WithMaxRows(100), WithMaxBytes(1000)},
+			executed: true,
+		},
+		"too many rows": {
+			opts: []PreFlightOption{WithMaxRows(99)},
+			err:  "expected 100 rows, max 99",
+		},
+		"too many bytes": {
+			opts: []PreFlightOption{WithMaxBytes(999)},
+			err:  "expected 1000 bytes, max 999",
+		},
+		"warn": {
+			opts:     []PreFlightOption{WithMaxRows(10)},
+			warned:   true,
+			executed: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			executed = false
+			var warned bool
+			if tc.warned {
+				tc.opts = append(tc.opts, WithPreFlightWarning(func(_ context.Context, _ string, err error) {
+					warned = errors.Is(err, ErrEstimateExceeded)
+				}))
+			}
+
+			_, err := PreFlight(exec, estimator, tc.opts...).QueryContext(context.Background(), "SELECT 1")
+			switch {
+			case tc.err == "" && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			case tc.err != "" && !errors.Is(err, ErrEstimateExceeded):
+				t.Fatalf("expected ErrEstimateExceeded, got: %v", err)
+			case tc.err != "" && !strings.Contains(err.Error(), tc.err):
+				t.Fatalf("expected error to contain %q, got: %v", tc.err, err)
+			}
+
+			if warned != tc.warned {
+				t.Fatalf("warned: expected %t, got %t", tc.warned, warned)
+			}
+
+			if executed != tc.executed {
+				t.Fatalf("executed: expected %t, got %t", tc.executed, executed)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// Preparer is implemented by a [Queryer] that can prepare statements,
+// such as the wrappers in the stdscan package
+type Preparer interface {
+	PrepareContext(ctx context.Context, query string) (Stmt, error)
+}
+
+// Stmt is a prepared statement that can be run many times with different args
+type Stmt interface {
+	QueryContext(ctx context.Context, args ...any) (Rows, error)
+	Close() error
+}
+
+// PreparedQuery runs the same query many times and maps the rows to T.
+// Create one with [Prepare].
+//
+// The mapping is built with the context of the first run, and reused for the runs
+// that return the same columns and have the same mapping settings in their context,
+// such as [WithMappingOptions], [WithLocale] and [CtxKeyAllowUnknownColumns].
+// Callbacks that run for each row, such as [AfterScanner], [BeforeRow] and [AfterRow],
+// get the context of their own run.
+// It is safe for concurrent use if the statement and the mapper are
+type PreparedQuery[T any] struct {
+	exec   Queryer
+	stmt   Stmt
+	query  string
+	mapper Mapper[T]
+
+	mu       sync.RWMutex
+	columns  []string
+	settings mappingSettings
+	before   BeforeFunc
+	after    func(any) (T, error)
+}
+
+// Prepare prepares the query and returns a [PreparedQuery] that reuses both the statement
+// and the mapping of the columns for repeated executions.
+// If exec does not implement [Preparer], the query is sent to exec on each run
+// and only the mapping is reused.
+//
+// The [PreparedQuery] should be closed when it is no longer needed
+//
+//	q, err := scan.Prepare(ctx, exec, scan.StructMapper[User](), "SELECT * FROM users WHERE id = $1")
+//	if err != nil {
+//	    return err
+//	}
+//	defer q.Close()
+//
+//	user, err := q.One(ctx, 1)
+func Prepare[T any](ctx context.Context, exec Queryer, m Mapper[T], query string) (*PreparedQuery[T], error) {
+	p := &PreparedQuery[T]{
+		exec:   exec,
+		query:  query,
+		mapper: m,
+	}
+
+	if preparer, ok := exec.(Preparer); ok {
+		stmt, err := preparer.PrepareContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		p.stmt = stmt
+	}
+
+	return p, nil
+}
+
+// One runs the query with the args and maps the single row to T.
+// It returns [ErrNoRows] if there is no row
+func (p *PreparedQuery[T]) One(ctx context.Context, args ...any) (T, error) {
+	var t T
+
+	v, err := p.run(ctx, args)
+	if err != nil {
+		return t, err
+	}
+	defer v.r.Close()
+
+	before, after := p.mapping(ctx, v)
+
+	if !v.r.Next() {
+		if err = v.r.Err(); err != nil {
+			return t, err
+		}
+		return t, ErrNoRows
+	}
+
+	t, err = scanOneRow(v, before, after)
+	if err != nil {
+		return t, withQuery(err, p.query)
+	}
+
+	return t, v.r.Err()
+}
+
+// All runs the query with the args and returns a slice []T of all rows.
+// Context cancellation is handled the same way as [AllFromRows]
+func (p *PreparedQuery[T]) All(ctx context.Context, args ...any) ([]T, error) {
+	var results []T
+
+	err := p.Each(ctx, func(t T) error {
+		results = append(results, t)
+		return nil
+	}, args...)
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	return results, err
+}
+
+// Each runs the query with the args and calls fn with each row in order.
+// It stops at the first error returned by fn and returns it
+func (p *PreparedQuery[T]) Each(ctx context.Context, fn func(T) error, args ...any) error {
+	v, err := p.run(ctx, args)
+	if err != nil {
+		return err
+	}
+	defer v.r.Close()
+
+	before, after := p.mapping(ctx, v)
+
+	for v.r.Next() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		one, err := scanOneRow(v, before, after)
+		if isSkipped(err) {
+			continue
+		}
+		if err != nil {
+			return withQuery(err, p.query)
+		}
+
+		if err := fn(one); err != nil {
+			return err
+		}
+	}
+
+	return v.r.Err()
+}
+
+// Close closes the prepared statement, if any
+func (p *PreparedQuery[T]) Close() error {
+	if p.stmt == nil {
+		return nil
+	}
+
+	return p.stmt.Close()
+}
+
+// run executes the query and wraps the rows
+func (p *PreparedQuery[T]) run(ctx context.Context, args []any) (*Row, error) {
+	exec := p.exec
+	if p.stmt != nil {
+		exec = stmtQueryer{stmt: p.stmt}
+		if hooks := hooksOf(p.exec); len(hooks) > 0 {
+			exec = hookedQueryer{q: exec, hooks: hooks}
+		}
+	}
+
+	rows, err := queryContext(ctx, exec, typeOf[T](), p.query, args)
+	if err != nil {
+		return nil, err
+	}
+
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+	v, err := wrapRows(rows, allowUnknown)
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	v.ctx = ctx
+
+	return v, nil
+}
+
+// mapping returns the cached mapping for the columns of the row, building it
+// again with the context of the run if the columns or the mapping settings
+// have changed since the last run
+func (p *PreparedQuery[T]) mapping(ctx context.Context, v *Row) (BeforeFunc, func(any) (T, error)) {
+	settings := mappingSettingsOf(ctx)
+
+	p.mu.RLock()
+	if sameColumns(p.columns, v.columns) && p.settings.same(settings) && p.before != nil {
+		before, after := p.before, p.after
+		p.mu.RUnlock()
+		return before, after
+	}
+	p.mu.RUnlock()
+
+	before, after := buildMapper(ctx, p.mapper, v, v.columnsCopy())
+
+	p.mu.Lock()
+	p.columns = v.columnsCopy()
+	p.settings = settings
+	p.before, p.after = before, after
+	p.mu.Unlock()
+
+	return before, after
+}
+
+// mappingKeys are the context keys that change the mapping built for the columns
+var mappingKeys = [...]contextKey{
+	ctxKeyMappingOptions,
+	CtxKeyMapperMods,
+	CtxKeyAllowUnknownColumns,
+	CtxKeyEnforceAllFields,
+	ctxKeyLocale,
+}
+
+// mappingSettings are the values of the mapping keys in the context of a run
+type mappingSettings [len(mappingKeys)]any
+
+func mappingSettingsOf(ctx context.Context) mappingSettings {
+	var s mappingSettings
+	for i, key := range mappingKeys {
+		s[i] = ctx.Value(key)
+	}
+
+	return s
+}
+
+// same reports if a mapping built with the settings can be reused with o.
+// Options and mods are functions, so their slices are only the same if they share their elements
+func (s mappingSettings) same(o mappingSettings) bool {
+	for i := range s {
+		a, b := reflect.ValueOf(s[i]), reflect.ValueOf(o[i])
+		if a.Kind() == reflect.Slice && b.Kind() == reflect.Slice {
+			if a.Type() != b.Type() || a.Len() != b.Len() || a.Pointer() != b.Pointer() {
+				return false
+			}
+			continue
+		}
+
+		if !reflect.DeepEqual(s[i], o[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// stmtQueryer runs the prepared statement for the query
+type stmtQueryer struct {
+	stmt Stmt
+}
+
+func (s stmtQueryer) QueryContext(ctx context.Context, _ string, args ...any) (Rows, error) {
+	return s.stmt.QueryContext(ctx, args...)
+}
+
+func sameColumns(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,273 @@
+package scan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// fakePreparer prepares statements that return users with the id given as the arg
+type fakePreparer struct {
+	funcQ
+	prepared int
+	closed   int
+}
+
+func (f *fakePreparer) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	f.prepared++
+	return fakeStmt{p: f, query: query}, nil
+}
+
+type fakeStmt struct {
+	p     *fakePreparer
+	query string
+}
+
+func (s fakeStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	return s.p.funcQ(ctx, s.query, args...)
+}
+
+func (s fakeStmt) Close() error {
+	s.p.closed++
+	return nil
+}
+
+func usersByID(ctx context.Context, query string, args ...any) (Rows, error) {
+	rows := make([][]any, len(args))
+	for i, arg := range args {
+		rows[i] = []any{arg, "user"}
+	}
+
+	return newSliceRows([]string{"id", "name"}, rows...), nil
+}
+
+func TestPreparedQuery(t *testing.T) {
+	ctx := context.Background()
+
+	var built int
+	m := func(ctx context.Context, c cols) (BeforeFunc, func(any) (User, error)) {
+		built++
+		return StructMapper[User]()(ctx, c)
+	}
+
+	exec := &fakePreparer{funcQ: usersByID}
+	q, err := Prepare[User](ctx, exec, m, "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	one, err := q.One(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(User{ID: 1, Name: "user"}, one); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	all, err := q.All(ctx, 2, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]User{{ID: 2, Name: "user"}, {ID: 3, Name: "user"}}, all); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	var ids []int
+	err = q.Each(ctx, func(u User) error {
+		ids = append(ids, u.ID)
+		return nil
+	}, 4, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{4, 5}, ids); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	if _, err := q.One(ctx); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	if err := q.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if exec.prepared != 1 || exec.closed != 1 {
+		t.Fatalf("expected the statement to be prepared and closed once, got %d and %d", exec.prepared, exec.closed)
+	}
+
+	if built != 1 {
+		t.Fatalf("expected the mapping to be built once, got %d", built)
+	}
+}
+
+func TestPreparedQueryRunOptions(t *testing.T) {
+	ctx := context.Background()
+
+	var built int
+	m := func(ctx context.Context, c cols) (BeforeFunc, func(any) (User, error)) {
+		built++
+		return StructMapper[User]()(ctx, c)
+	}
+
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "name", "extra"}, []any{1, "user", "x"}), nil
+	})
+
+	q, err := Prepare[User](ctx, exec, m, "SELECT id, name, extra FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	if _, err := q.One(ctx); !errors.Is(err, ErrNoDestination) {
+		t.Fatalf("expected ErrNoDestination, got %v", err)
+	}
+
+	// The options of the run are used, even though the columns are the same
+	allowCtx := WithMappingOptions(ctx, WithAllowUnknownColumns(true))
+	for i := 0; i < 2; i++ {
+		one, err := q.One(allowCtx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(User{ID: 1, Name: "user"}, one); diff != "" {
+			t.Fatalf("diff: %s", diff)
+		}
+	}
+
+	if _, err := q.One(ctx); !errors.Is(err, ErrNoDestination) {
+		t.Fatalf("expected ErrNoDestination, got %v", err)
+	}
+
+	if built != 3 {
+		t.Fatalf("expected the mapping to be built for each change of options, got %d", built)
+	}
+}
+
+type runKey struct{}
+
+// runUser records the run of the context given to its callbacks
+type runUser struct {
+	ID        int
+	Name      string
+	MappedIn  string
+	ScannedIn string
+}
+
+func (u *runUser) MapValues(ctx context.Context, key string) any {
+	u.MappedIn, _ = ctx.Value(runKey{}).(string)
+
+	switch key {
+	case "id":
+		return &u.ID
+	case "name":
+		return &u.Name
+	}
+
+	return nil
+}
+
+func (u *runUser) AfterScan(ctx context.Context) error {
+	u.ScannedIn, _ = ctx.Value(runKey{}).(string)
+	return nil
+}
+
+func TestPreparedQueryRunContext(t *testing.T) {
+	var before, after []any
+	m := StructMapper[runUser](WithMapperMods(
+		BeforeRow(func(ctx context.Context, r *Row) error {
+			before = append(before, ctx.Value(runKey{}))
+			return nil
+		}),
+		AfterRow(func(ctx context.Context, u runUser) error {
+			after = append(after, ctx.Value(runKey{}))
+			return nil
+		}),
+	))
+
+	q, err := Prepare(context.Background(), funcQ(usersByID), m, "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	for _, run := range []string{"first", "second"} {
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), runKey{}, run))
+		one, err := q.One(ctx, 1)
+		cancel()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if diff := cmp.Diff(runUser{ID: 1, Name: "user", MappedIn: run, ScannedIn: run}, one); diff != "" {
+			t.Fatalf("diff: %s", diff)
+		}
+	}
+
+	if diff := cmp.Diff([]any{"first", "second"}, before); diff != "" {
+		t.Fatalf("BeforeRow diff: %s", diff)
+	}
+
+	if diff := cmp.Diff([]any{"first", "second"}, after); diff != "" {
+		t.Fatalf("AfterRow diff: %s", diff)
+	}
+}
+
+func TestPrepareWithoutPreparer(t *testing.T) {
+	ctx := context.Background()
+
+	var built int
+	m := func(ctx context.Context, c cols) (BeforeFunc, func(any) (int, error)) {
+		built++
+		return SingleColumnMapper[int](ctx, c)
+	}
+
+	columns := []string{"id"}
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows(columns, []any{len(args)}), nil
+	})
+
+	q, err := Prepare[int](ctx, exec, m, "SELECT count(*)")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	for i := 0; i < 3; i++ {
+		n, err := q.One(ctx, make([]any, i)...)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if n != i {
+			t.Fatalf("expected %d, got %d", i, n)
+		}
+	}
+
+	if built != 1 {
+		t.Fatalf("expected the mapping to be built once, got %d", built)
+	}
+
+	// A change in the columns rebuilds the mapping
+	columns = []string{"count"}
+	if _, err := q.One(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if built != 2 {
+		t.Fatalf("expected the mapping to be rebuilt, got %d builds", built)
+	}
+
+	errEach := errors.New("stop")
+	if err := q.Each(ctx, func(int) error { return errEach }); !errors.Is(err, errEach) {
+		t.Fatalf("expected the error from fn, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package scan
+
+import "strings"
+
+// ColumnNormalizer rewrites the name of a column before it is matched to a field,
+// for drivers and views that return qualified or quoted names such as "User"."ID".
+// See [WithColumnNormalizer]
+type ColumnNormalizer func(column string) string
+
+// WithColumnNormalizer makes the struct mapper match columns to fields by the name
+// returned by the normalizer, such as [UnquoteColumn] or [LastColumnSegment].
+// Without it, the full name of the column is matched
+func WithColumnNormalizer(n ColumnNormalizer) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.columnNormalizer = n
+	}
+}
+
+// UnquoteColumn removes the quotes around each dot separated part of the column name,
+// with double quotes, backticks or brackets e.g. "User"."ID" is matched as User.ID.
+// Dots inside quotes do not separate parts
+func UnquoteColumn(column string) string {
+	parts := columnSegments(column)
+	for i, part := range parts {
+		parts[i] = unquoteSegment(part)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// LastColumnSegment returns the last dot separated part of the column name without quotes,
+// e.g. schema.users.id and "users"."id" are matched as id.
+// Columns of nested struct fields cannot be matched with it
+func LastColumnSegment(column string) string {
+	parts := columnSegments(column)
+	return unquoteSegment(parts[len(parts)-1])
+}
+
+// columnSegments splits the column name at the dots that are not inside quotes
+func columnSegments(column string) []string {
+	var parts []string
+	var quote byte
+	start := 0
+
+	for i := 0; i < len(column); i++ {
+		b := column[i]
+		switch {
+		case quote != 0:
+			if b == quote {
+				quote = 0
+			}
+		case b == '"', b == '`':
+			quote = b
+		case b == '[':
+			quote = ']'
+		case b == '.':
+			parts = append(parts, column[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(parts, column[start:])
+}
+
+func unquoteSegment(s string) string {
+	if len(s) < 2 {
+		return s
+	}
+
+	first, last := s[0], s[len(s)-1]
+	switch {
+	case first == '"' && last == '"', first == '`' && last == '`':
+		quote := s[:1]
+		return strings.ReplaceAll(s[1:len(s)-1], quote+quote, quote)
+	case first == '[' && last == ']':
+		return strings.ReplaceAll(s[1:len(s)-1], "]]", "]")
+	}
+
+	return s
+}
@@ -0,0 +1,61 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestColumnNormalizer(t *testing.T) {
+	ctx := context.Background()
+
+	rows := newSliceRows([]string{`"id"`, `"name"`}, []any{1, "foo"})
+	users, err := AllFromRows(ctx, StructMapper[User](WithColumnNormalizer(UnquoteColumn)), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]User{{ID: 1, Name: "foo"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	rows = newSliceRows([]string{"public.users.id", `"users"."name"`}, []any{2, "bar"})
+	users, err = AllFromRows(ctx, StructMapper[User](WithColumnNormalizer(LastColumnSegment)), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]User{{ID: 2, Name: "bar"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The full name is matched by default
+	rows = newSliceRows([]string{"users.id"}, []any{3})
+	if _, err := AllFromRows(ctx, StructMapper[User](), rows); err == nil {
+		t.Fatal("expected an error for a qualified column")
+	}
+}
+
+func TestNormalizeColumns(t *testing.T) {
+	cases := []struct {
+		column, unquoted, last string
+	}{
+		{column: "id", unquoted: "id", last: "id"},
+		{column: `"User"."ID"`, unquoted: "User.ID", last: "ID"},
+		{column: "schema.table.column", unquoted: "schema.table.column", last: "column"},
+		{column: "`t`.`a.b`", unquoted: "t.a.b", last: "a.b"},
+		{column: `[dbo].[Users].[Name]`, unquoted: "dbo.Users.Name", last: "Name"},
+		{column: `"say ""hi"""`, unquoted: `say "hi"`, last: `say "hi"`},
+	}
+
+	for _, c := range cases {
+		if got := UnquoteColumn(c.column); got != c.unquoted {
+			t.Fatalf("expected %q for %q, got %q", c.unquoted, c.column, got)
+		}
+
+		if got := LastColumnSegment(c.column); got != c.last {
+			t.Fatalf("expected %q for %q, got %q", c.last, c.column, got)
+		}
+	}
+}
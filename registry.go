@@ -0,0 +1,154 @@
+package scan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrQueryNotAllowed is returned when running a query that is not
+// in the [QueryRegistry] through an [Allowlist] queryer
+var ErrQueryNotAllowed = errors.New("query not in registry")
+
+// Fingerprint returns an identifier for the query.
+// Queries that only differ in whitespace have the same fingerprint
+func Fingerprint(query string) string {
+	sum := sha256.Sum256([]byte(strings.Join(strings.Fields(query), " ")))
+	return hex.EncodeToString(sum[:])
+}
+
+// RegisteredQuery is a query known to a [QueryRegistry]
+type RegisteredQuery struct {
+	Name        string
+	SQL         string
+	Fingerprint string
+}
+
+// QueryRegistry holds a set of known queries.
+// Queries are expected to be registered when the program starts, usually
+// as package level variables, so the full set is known at compile time
+//
+//	var reg = scan.NewQueryRegistry()
+//	var getUser = reg.MustRegister("get_user", "SELECT * FROM users WHERE id = $1")
+type QueryRegistry struct {
+	mu      sync.RWMutex
+	queries map[string]RegisteredQuery
+	names   map[string]string
+	hooks   []Hook
+}
+
+// NewQueryRegistry returns an empty [QueryRegistry]
+func NewQueryRegistry() *QueryRegistry {
+	return &QueryRegistry{
+		queries: make(map[string]RegisteredQuery),
+		names:   make(map[string]string),
+	}
+}
+
+// Register adds the query to the registry under the given name.
+// It returns an error if the name is already used by a different query
+func (r *QueryRegistry) Register(name, query string) (RegisteredQuery, error) {
+	q := RegisteredQuery{
+		Name:        name,
+		SQL:         query,
+		Fingerprint: Fingerprint(query),
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if fp, ok := r.names[name]; ok && fp != q.Fingerprint {
+		return RegisteredQuery{}, fmt.Errorf("query %q is already registered", name)
+	}
+
+	r.names[name] = q.Fingerprint
+	r.queries[q.Fingerprint] = q
+	return q, nil
+}
+
+// MustRegister is like [QueryRegistry.Register] but panics on error
+func (r *QueryRegistry) MustRegister(name, query string) RegisteredQuery {
+	q, err := r.Register(name, query)
+	if err != nil {
+		panic(err)
+	}
+
+	return q
+}
+
+// Lookup returns the registered query with the same fingerprint as the given query
+func (r *QueryRegistry) Lookup(query string) (RegisteredQuery, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	q, ok := r.queries[Fingerprint(query)]
+	return q, ok
+}
+
+// Get returns the registered query with the given name
+func (r *QueryRegistry) Get(name string) (RegisteredQuery, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	fp, ok := r.names[name]
+	if !ok {
+		return RegisteredQuery{}, false
+	}
+
+	return r.queries[fp], true
+}
+
+// Check returns an error wrapping [ErrQueryNotAllowed] if the query
+// is not in the registry
+func (r *QueryRegistry) Check(query string) error {
+	if _, ok := r.Lookup(query); !ok {
+		return fmt.Errorf("%w: %s", ErrQueryNotAllowed, query)
+	}
+
+	return nil
+}
+
+// Use adds hooks that receive the events of the queries run through
+// an [Allowlist] queryer with the registry, before the hooks of the wrapped queryer.
+// See [WithHooks]
+func (r *QueryRegistry) Use(hooks ...Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.hooks = append(r.hooks, hooks...)
+}
+
+// Allowlist wraps a [Queryer] so that only queries in the registry can be run.
+// Every other query returns an error wrapping [ErrQueryNotAllowed]
+func Allowlist(q Queryer, r *QueryRegistry) Queryer {
+	return allowlistQueryer{q: q, r: r}
+}
+
+type allowlistQueryer struct {
+	q Queryer
+	r *QueryRegistry
+}
+
+func (a allowlistQueryer) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	if err := a.r.Check(query); err != nil {
+		return nil, err
+	}
+
+	return a.q.QueryContext(ctx, query, args...)
+}
+
+func (a allowlistQueryer) scanHooks() []Hook {
+	a.r.mu.RLock()
+	defer a.r.mu.RUnlock()
+
+	inner := hooksOf(a.q)
+	if len(a.r.hooks) == 0 {
+		return inner
+	}
+
+	return append(a.r.hooks[:len(a.r.hooks):len(a.r.hooks)], inner...)
+}
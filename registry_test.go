@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := Fingerprint("SELECT id, name\n\tFROM users")
+	b := Fingerprint("  SELECT id,  name FROM users ")
+	c := Fingerprint("SELECT id FROM users")
+
+	if a != b {
+		t.Fatal("queries differing only in whitespace should have the same fingerprint")
+	}
+
+	if a == c {
+		t.Fatal("different queries should have different fingerprints")
+	}
+}
+
+func TestQueryRegistry(t *testing.T) {
+	reg := NewQueryRegistry()
+	users := reg.MustRegister("users", "SELECT id, name FROM users")
+
+	if _, err := reg.Register("users", "SELECT id, name FROM users"); err != nil {
+		t.Fatalf("registering the same query twice should not fail: %v", err)
+	}
+
+	if _, err := reg.Register("users", "SELECT id FROM users"); err == nil {
+		t.Fatal("expected error registering a different query with the same name")
+	}
+
+	got, ok := reg.Get("users")
+	if !ok || got != users {
+		t.Fatalf("wrong registered query: %#v", got)
+	}
+
+	got, ok = reg.Lookup("SELECT id,\n name FROM users")
+	if !ok || got != users {
+		t.Fatalf("wrong registered query: %#v", got)
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	reg := NewQueryRegistry()
+	reg.MustRegister("users", "SELECT id, name FROM users")
+
+	var executed bool
+	exec := Allowlist(funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		executed = true
+		return newSliceRows([]string{"id", "name"}), nil
+	}), reg)
+
+	if _, err := exec.QueryContext(context.Background(), "SELECT id, name FROM users"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !executed {
+		t.Fatal("registered query was not executed")
+	}
+
+	executed = false
+	_, err := exec.QueryContext(context.Background(), "SELECT * FROM users")
+	if !errors.Is(err, ErrQueryNotAllowed) {
+		t.Fatalf("expected ErrQueryNotAllowed, got %v", err)
+	}
+
+	if executed {
+		t.Fatal("unregistered query was executed")
+	}
+}
@@ -0,0 +1,185 @@
+package scan
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy decides which query errors are retried by [WithRetry]
+// and how long to wait between the attempts. The zero value uses the defaults
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the query is run, including the first one.
+	// The default is 3
+	MaxAttempts int
+	// InitialDelay is the wait before the first retry. The default is 50ms
+	InitialDelay time.Duration
+	// MaxDelay is the longest wait between attempts. The default is 2s
+	MaxDelay time.Duration
+	// Multiplier is how much the wait grows after each retry. The default is 2
+	Multiplier float64
+	// Retryable reports if the error is transient, e.g. with the rules of the driver.
+	// The default is [IsRetryable]
+	Retryable func(error) bool
+	// OnRetry, if not nil, is called before waiting for each retry, e.g. to log the error
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
+// WithRetry returns a [Queryer] that runs the query again when it fails
+// with an error that the policy can retry, waiting longer after each attempt.
+// Only the errors returned when the query is run are retried,
+// since the rows that were already read cannot be read again.
+// If the context is done while waiting, the last error is returned.
+//
+// Only use it for queries that are safe to run twice and are not in a transaction.
+// A connection error can happen after a write such as INSERT ... RETURNING has been committed,
+// and a serialization failure or a deadlock aborts the whole transaction, so it has to be
+// retried as a whole instead of a single statement.
+//
+// The hooks of q are kept, and if q can prepare statements, the returned Queryer can too,
+// with the queries of the statements retried in the same way
+//
+//	exec := scan.WithRetry(stdscan.Wrap(db), scan.RetryPolicy{MaxAttempts: 5})
+func WithRetry(q Queryer, policy RetryPolicy) Queryer {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialDelay <= 0 {
+		policy.InitialDelay = 50 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 2 * time.Second
+	}
+	if policy.Multiplier < 1 {
+		policy.Multiplier = 2
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsRetryable
+	}
+
+	r := retryQueryer{q: q, policy: policy}
+	if p, ok := q.(Preparer); ok {
+		return retryPreparer{retryQueryer: r, p: p}
+	}
+
+	return r
+}
+
+type retryQueryer struct {
+	q      Queryer
+	policy RetryPolicy
+}
+
+func (r retryQueryer) QueryContext(ctx context.Context, query string, args ...any) (Rows, error) {
+	return r.policy.run(ctx, func() (Rows, error) {
+		return r.q.QueryContext(ctx, query, args...)
+	})
+}
+
+func (r retryQueryer) scanHooks() []Hook {
+	return hooksOf(r.q)
+}
+
+type retryPreparer struct {
+	retryQueryer
+	p Preparer
+}
+
+func (r retryPreparer) PrepareContext(ctx context.Context, query string) (Stmt, error) {
+	stmt, err := r.p.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return retryStmt{stmt: stmt, policy: r.policy}, nil
+}
+
+// retryStmt retries the queries of a prepared statement
+type retryStmt struct {
+	stmt   Stmt
+	policy RetryPolicy
+}
+
+func (s retryStmt) QueryContext(ctx context.Context, args ...any) (Rows, error) {
+	return s.policy.run(ctx, func() (Rows, error) {
+		return s.stmt.QueryContext(ctx, args...)
+	})
+}
+
+func (s retryStmt) Close() error {
+	return s.stmt.Close()
+}
+
+// run calls query until it succeeds, returns an error that cannot be retried,
+// or runs out of attempts
+func (p RetryPolicy) run(ctx context.Context, query func() (Rows, error)) (Rows, error) {
+	delay := p.InitialDelay
+
+	for attempt := 1; ; attempt++ {
+		rows, err := query()
+		if err == nil || attempt >= p.MaxAttempts || ctx.Err() != nil || !p.Retryable(err) {
+			return rows, err
+		}
+
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+
+		delay = time.Duration(float64(delay) * p.Multiplier)
+		if delay > p.MaxDelay {
+			delay = p.MaxDelay
+		}
+	}
+}
+
+// retryableStates are the SQLSTATE codes and classes of transient errors
+var retryableStates = []string{
+	"40001", // serialization failure
+	"40P01", // deadlock detected
+	"08",    // connection exception
+	"57P01", // admin shutdown
+	"HYT00", // timeout expired
+}
+
+// IsRetryable reports if the error is usually transient, so that the query
+// may succeed when it runs again: serialization failures, deadlocks and connection errors.
+// Errors with a SQLSTATE, i.e. a SQLState() string method such as the errors of pgx, are
+// classified by their code. Context errors are never retryable.
+// Use it with the rules of other drivers in [RetryPolicy.Retryable].
+//
+// It does not know if the query was idempotent or in a transaction,
+// see [WithRetry] for the queries that are safe to retry
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var state interface{ SQLState() string }
+	if errors.As(err, &state) {
+		code := state.SQLState()
+		for _, s := range retryableStates {
+			if strings.HasPrefix(code, s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
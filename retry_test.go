@@ -0,0 +1,156 @@
+package scan
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "sqlstate " + string(e) }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func TestWithRetry(t *testing.T) {
+	ctx := context.Background()
+
+	failing := func(times int, err error) (*int, Queryer) {
+		var attempts int
+		return &attempts, funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+			attempts++
+			if attempts <= times {
+				return nil, err
+			}
+			return usersByID(ctx, query, args...)
+		})
+	}
+
+	var retried []int
+	policy := RetryPolicy{
+		InitialDelay: time.Millisecond,
+		OnRetry: func(attempt int, err error, delay time.Duration) {
+			retried = append(retried, attempt)
+		},
+	}
+
+	attempts, q := failing(2, sqlStateError("40001"))
+	users, err := All(ctx, WithRetry(q, policy), StructMapper[User](), "", 1, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{1, 2}, ids(users)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+	if *attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *attempts)
+	}
+	if diff := cmp.Diff([]int{1, 2}, retried); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The last error is returned after MaxAttempts
+	attempts, q = failing(5, fmt.Errorf("query: %w", driver.ErrBadConn))
+	_, err = All(ctx, WithRetry(q, policy), StructMapper[User](), "", 1)
+	if !errors.Is(err, driver.ErrBadConn) {
+		t.Fatalf("expected ErrBadConn, got %v", err)
+	}
+	if *attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *attempts)
+	}
+
+	// Other errors are not retried
+	attempts, q = failing(1, sqlStateError("23505"))
+	_, err = All(ctx, WithRetry(q, policy), StructMapper[User](), "", 1)
+	if !errors.As(err, new(sqlStateError)) {
+		t.Fatalf("expected the unique violation, got %v", err)
+	}
+	if *attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", *attempts)
+	}
+
+	// Errors are classified with the policy
+	attempts, q = failing(1, errors.New("busy"))
+	custom := policy
+	custom.Retryable = func(err error) bool { return err.Error() == "busy" }
+	if _, err := All(ctx, WithRetry(q, custom), StructMapper[User](), "", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", *attempts)
+	}
+
+	// Waiting stops when the context is done
+	cancelled, cancel := context.WithCancel(ctx)
+	attempts, q = failing(5, sqlStateError("40P01"))
+	slow := RetryPolicy{InitialDelay: time.Hour, OnRetry: func(int, error, time.Duration) { cancel() }}
+	_, err = All(cancelled, WithRetry(q, slow), StructMapper[User](), "", 1)
+	if !errors.As(err, new(sqlStateError)) || *attempts != 1 {
+		t.Fatalf("expected the deadlock after 1 attempt, got %v after %d", err, *attempts)
+	}
+}
+
+func TestWithRetryKeepsQueryer(t *testing.T) {
+	ctx := context.Background()
+
+	var attempts int
+	p := &fakePreparer{funcQ: func(ctx context.Context, query string, args ...any) (Rows, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, driver.ErrBadConn
+		}
+		return usersByID(ctx, query, args...)
+	}}
+
+	hook := &recordingHook{name: "hook"}
+	exec := WithRetry(WithHooks(p, hook), RetryPolicy{InitialDelay: time.Millisecond})
+
+	// The statements of a prepared query are retried
+	q, err := Prepare(ctx, exec, StructMapper[User](), "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	if p.prepared != 1 {
+		t.Fatalf("expected the query to be prepared, got %d", p.prepared)
+	}
+
+	users, err := q.All(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff([]int{1}, ids(users)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+	if attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", attempts)
+	}
+
+	// The hooks of the wrapped queryer are kept
+	if len(hook.events) == 0 {
+		t.Fatal("expected the hooks of the wrapped queryer to be called")
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	cases := map[error]bool{
+		sqlStateError("40001"):                      true,
+		sqlStateError("40P01"):                      true,
+		sqlStateError("08006"):                      true,
+		sqlStateError("23505"):                      false,
+		driver.ErrBadConn:                           true,
+		fmt.Errorf("wrapped: %w", context.Canceled): false,
+		errors.New("syntax error"):                  false,
+	}
+
+	for err, want := range cases {
+		if got := IsRetryable(err); got != want {
+			t.Errorf("IsRetryable(%v) = %t, want %t", err, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package scan
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 )
@@ -30,6 +31,50 @@ type Row struct {
 	scanDestinations    []reflect.Value
 	unknownDestinations []string
 	allowUnknown        bool
+	discard             reflect.Value
+
+	// targets is reused to scan every row
+	targets []any
+
+	// fields holds the struct field of each scheduled column, for errors
+	fields []string
+
+	// state holds the state of the mapper mods for this run of the query, see runState
+	state map[any]any
+
+	// ctx is the context of this run of the query, if the mapping can be reused
+	// by runs with other contexts, see runContext
+	ctx context.Context
+}
+
+// runContext returns the context of this run of the query for the callbacks of a mapping,
+// or ctx, the context the mapping was built with, if the mapping is only used by this run.
+// A mapping can be reused by runs with other contexts, e.g. by a [PreparedQuery],
+// so callbacks must not keep the context the mapping was built with
+func (r *Row) runContext(ctx context.Context) context.Context {
+	if r == nil || r.ctx == nil {
+		return ctx
+	}
+
+	return r.ctx
+}
+
+// runState returns the state kept for key during this run of the query,
+// creating it with init the first time. A mapping can be shared by concurrent runs,
+// e.g. by a [PreparedQuery], so mods that track the rows of a query keep
+// their state here instead of in the mapping
+func runState[S any](r *Row, key any, init func() S) S {
+	if r.state == nil {
+		r.state = make(map[any]any)
+	}
+
+	s, ok := r.state[key].(S)
+	if !ok {
+		s = init()
+		r.state[key] = s
+	}
+
+	return s
 }
 
 // ScheduleScan schedules a scan for the column name into the given value
@@ -41,9 +86,21 @@ func (r *Row) ScheduleScan(colName string, val any) {
 // ScheduleScanx schedules a scan for the column name into the given reflect.Value
 // val.Kind() should be reflect.Pointer
 func (r *Row) ScheduleScanx(colName string, val reflect.Value) {
+	r.scheduleField(colName, "", val)
+}
+
+// scheduleField schedules a scan for the column into a struct field,
+// so that errors for the column can name the field
+func (r *Row) scheduleField(colName, field string, val reflect.Value) {
 	for i, n := range r.columns {
 		if n == colName {
 			r.scanDestinations[i] = val
+			if field != "" && r.fields == nil {
+				r.fields = make([]string, len(r.columns))
+			}
+			if r.fields != nil {
+				r.fields[i] = field
+			}
 			return
 		}
 	}
@@ -51,6 +108,72 @@ func (r *Row) ScheduleScanx(colName string, val reflect.Value) {
 	r.unknownDestinations = append(r.unknownDestinations, colName)
 }
 
+// scheduleIndex is like scheduleField, but first tries the column at index i
+// so that mappers that know the position of their columns do not have to search for them
+func (r *Row) scheduleIndex(i int, colName, field string, val reflect.Value) {
+	if i < 0 || i >= len(r.columns) || r.columns[i] != colName {
+		r.scheduleField(colName, field, val)
+		return
+	}
+
+	r.scanDestinations[i] = val
+	if field != "" && r.fields == nil {
+		r.fields = make([]string, len(r.columns))
+	}
+	if r.fields != nil {
+		r.fields[i] = field
+	}
+}
+
+// shareIndex is like scheduleIndex, but if the column already has a destination
+// the value is scanned into both, for fields that share the column
+func (r *Row) shareIndex(i int, colName, field string, val reflect.Value) {
+	if i < 0 || i >= len(r.columns) || r.columns[i] != colName {
+		i = -1
+		for j, n := range r.columns {
+			if n == colName {
+				i = j
+				break
+			}
+		}
+	}
+
+	if i < 0 {
+		r.scheduleField(colName, field, val)
+		return
+	}
+
+	if scheduled := r.scanDestinations[i]; scheduled != zeroValue {
+		val, field = fanOut(scheduled, val), r.field(i)
+	}
+
+	r.scheduleIndex(i, colName, field, val)
+}
+
+// field returns the struct field scheduled for the column at index i, if any
+func (r *Row) field(i int) string {
+	if i < 0 || i >= len(r.fields) {
+		return ""
+	}
+
+	return r.fields[i]
+}
+
+// skipColumns schedules the columns to be scanned and discarded
+func (r *Row) skipColumns(names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	if r.discard == zeroValue {
+		r.discard = reflect.ValueOf(new(any))
+	}
+
+	for _, name := range names {
+		r.ScheduleScanx(name, r.discard)
+	}
+}
+
 // To get a copy of the columns to pass to mapper generators
 // since modifing the map can have unintended side effects.
 // Ideally, a generator should only call this once
@@ -62,7 +185,8 @@ func (r *Row) columnsCopy() []string {
 
 func (r *Row) scanCurrentRow() error {
 	if len(r.unknownDestinations) > 0 {
-		return createError(fmt.Errorf("unknown columns to map to: %v", r.unknownDestinations), r.unknownDestinations...)
+		err := fmt.Errorf("unknown columns to map to: %v", r.unknownDestinations)
+		return columnError(ErrUnknownColumn, r.unknownDestinations[0], "", err, r.unknownDestinations...)
 	}
 
 	targets, err := r.createTargets()
@@ -72,15 +196,28 @@ func (r *Row) scanCurrentRow() error {
 
 	err = r.r.Scan(targets...)
 	if err != nil {
-		return err
+		var column string
+		i := columnIndex(err)
+		if i >= 0 && i < len(r.columns) {
+			column = r.columns[i]
+		}
+
+		return columnError(ErrConversion, column, r.field(i), err)
+	}
+
+	// Reset the destinations in place, so that scanning a row does not allocate
+	for i := range r.scanDestinations {
+		r.scanDestinations[i] = zeroValue
 	}
 
-	r.scanDestinations = make([]reflect.Value, len(r.columns))
 	return nil
 }
 
 func (r *Row) createTargets() ([]any, error) {
-	targets := make([]any, len(r.columns))
+	if len(r.targets) != len(r.columns) {
+		r.targets = make([]any, len(r.columns))
+	}
+	targets := r.targets
 
 	for i, name := range r.columns {
 		dest := r.scanDestinations[i]
@@ -91,7 +228,7 @@ func (r *Row) createTargets() ([]any, error) {
 
 		if !r.allowUnknown {
 			err := fmt.Errorf("No destination for column %s", name)
-			return nil, createError(err, "no destination", name)
+			return nil, columnError(ErrNoDestination, name, "", err, "no destination", name)
 		}
 
 		// See https://github.com/golang/go/issues/41607:
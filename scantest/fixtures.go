@@ -0,0 +1,131 @@
+package scantest
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stephenafamo/scan"
+	"gopkg.in/yaml.v3"
+)
+
+// LoadFixtures reads a list of records from a YAML or JSON file, chosen by its extension,
+// and maps each record into T with [scan.StructMapper], as if it was a row with a column for each key.
+// This keeps fixtures aligned with the column names used in production.
+//
+// The fields of nested structs are set with the full column name e.g. "author.id".
+// Nested objects and lists are passed to the mapper as JSON, for fields with the json option.
+//
+//	# users.yaml
+//	- id: 1
+//	  name: foo
+//	  created_at: 2023-01-02T15:04:05Z
+func LoadFixtures[T any](path string, opts ...scan.MappingOption) ([]T, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []map[string]any
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &records)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&records)
+	default:
+		return nil, fmt.Errorf("unsupported fixture file extension %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", path, err)
+	}
+
+	fixtures := make([]T, len(records))
+	for i, record := range records {
+		r, err := recordRows(record)
+		if err != nil {
+			return nil, fmt.Errorf("%s: record %d: %w", path, i, err)
+		}
+
+		fixtures[i], err = scan.OneFromRows(context.Background(), scan.StructMapper[T](opts...), r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: record %d: %w", path, i, err)
+		}
+	}
+
+	return fixtures, nil
+}
+
+// recordRows returns a single row with the values of the record
+func recordRows(record map[string]any) (*Rows, error) {
+	cols := make([]string, 0, len(record))
+	for col := range record {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+
+	vals := make([]any, len(cols))
+	for i, col := range cols {
+		switch val := record[col].(type) {
+		case map[string]any, []any:
+			data, err := json.Marshal(val)
+			if err != nil {
+				return nil, fmt.Errorf("column %q: %w", col, err)
+			}
+			vals[i] = data
+		case json.Number:
+			vals[i] = val.String()
+		default:
+			vals[i] = val
+		}
+	}
+
+	return NewRows(cols, vals), nil
+}
+
+// Execer runs statements that do not return rows, such as [*sql.DB]
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// InsertFixtures inserts each fixture into the table with an `INSERT` statement
+// built from the reverse mapping of its fields, see [scan.ColumnValues].
+// The statement uses ? placeholders.
+//
+// Columns of nested structs, which contain a ".", are skipped since they
+// usually belong to other tables.
+func InsertFixtures[T any](ctx context.Context, exec Execer, table string, fixtures []T) error {
+	for i, fixture := range fixtures {
+		cols, vals, err := scan.ColumnValues(fixture)
+		if err != nil {
+			return err
+		}
+
+		var names, placeholders []string
+		var args []any
+		for j, col := range cols {
+			if strings.Contains(col, ".") {
+				continue
+			}
+
+			names = append(names, col)
+			placeholders = append(placeholders, "?")
+			args = append(args, vals[j])
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+			table, strings.Join(names, ", "), strings.Join(placeholders, ", "))
+		if _, err := exec.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("inserting fixture %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package scantest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stephenafamo/scan"
+)
+
+type fixtureUser struct {
+	ID      int
+	Name    *string
+	Created time.Time
+	Tags    []string `db:"tags,json"`
+}
+
+func TestLoadFixtures(t *testing.T) {
+	name := "foo"
+	expected := []fixtureUser{
+		{ID: 1, Name: &name, Created: time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC), Tags: []string{"admin", "staff"}},
+		{ID: 2, Created: time.Date(2023, 2, 3, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, file := range []string{"users.yaml", "users.json"} {
+		t.Run(file, func(t *testing.T) {
+			users, err := LoadFixtures[fixtureUser](filepath.Join("testdata", file), scan.WithTimeLayouts(time.RFC3339))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			AssertRowsEqual(t, expected, users)
+		})
+	}
+
+	if _, err := LoadFixtures[fixtureUser]("testdata/users.txt"); err == nil {
+		t.Fatal("expected an error for an unsupported extension")
+	}
+}
+
+// execRecorder records the statements it runs
+type execRecorder struct {
+	queries []string
+}
+
+func (e *execRecorder) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	e.queries = append(e.queries, fmt.Sprintf("%s %v", query, args))
+	return nil, nil
+}
+
+func TestInsertFixtures(t *testing.T) {
+	type author struct {
+		ID int
+	}
+
+	type post struct {
+		ID     int
+		Title  string
+		Author *author
+	}
+
+	exec := &execRecorder{}
+	err := InsertFixtures(context.Background(), exec, "posts", []post{
+		{ID: 1, Title: "foo", Author: &author{ID: 2}},
+		{ID: 2, Title: "bar"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"INSERT INTO posts (id, title) VALUES (?, ?) [1 foo]",
+		"INSERT INTO posts (id, title) VALUES (?, ?) [2 bar]",
+	}
+	if diff := cmp.Diff(expected, exec.queries); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
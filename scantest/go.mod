@@ -0,0 +1,15 @@
+module github.com/stephenafamo/scan/scantest
+
+go 1.18
+
+require (
+	github.com/aarondl/opt v0.0.0-20221129170750-3d40c96d9bb8
+	github.com/google/go-cmp v0.5.9
+	github.com/stephenafamo/scan v0.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/aarondl/json v0.0.0-20221020222930-8b0db17ef1bf // indirect
+	golang.org/x/text v0.3.8 // indirect
+)
@@ -0,0 +1,92 @@
+package scantest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/stephenafamo/scan"
+)
+
+// ErrUnexpectedQuery is returned by a [Queryer] for queries that have no result
+var ErrUnexpectedQuery = errors.New("unexpected query")
+
+// Query is a query run with a [Queryer]
+type Query struct {
+	SQL  string
+	Args []any
+}
+
+// Queryer is a fake [scan.Queryer] that returns the result added for each query
+// and records the queries it runs. Queries are matched ignoring differences in whitespace.
+// It is safe for concurrent use
+//
+//	q := scantest.NewQueryer().
+//	    Add("SELECT id, name FROM users", scantest.NewRows([]string{"id", "name"}, []any{1, "foo"}))
+//	users, err := scan.All(ctx, q, scan.StructMapper[User](), "SELECT id, name FROM users")
+type Queryer struct {
+	mu      sync.Mutex
+	results map[string]result
+	queries []Query
+}
+
+type result struct {
+	rows *Rows
+	err  error
+}
+
+// NewQueryer returns a [Queryer] with no results
+func NewQueryer() *Queryer {
+	return &Queryer{results: make(map[string]result)}
+}
+
+// Add sets the rows returned for the query. Every run of the query returns the rows from the start
+func (q *Queryer) Add(query string, rows *Rows) *Queryer {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.results[normalize(query)] = result{rows: rows}
+	return q
+}
+
+// AddError sets the error returned for the query
+func (q *Queryer) AddError(query string, err error) *Queryer {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.results[normalize(query)] = result{err: err}
+	return q
+}
+
+// QueryContext implements [scan.Queryer].
+// It returns an error wrapping [ErrUnexpectedQuery] if no result was added for the query
+func (q *Queryer) QueryContext(ctx context.Context, query string, args ...any) (scan.Rows, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.queries = append(q.queries, Query{SQL: query, Args: args})
+
+	res, ok := q.results[normalize(query)]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("%w: %s", ErrUnexpectedQuery, query)
+	case res.err != nil:
+		return nil, res.err
+	}
+
+	return res.rows.copy(), nil
+}
+
+// Queries returns the queries that have been run, in order
+func (q *Queryer) Queries() []Query {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]Query(nil), q.queries...)
+}
+
+func normalize(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
@@ -0,0 +1,101 @@
+package scantest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stephenafamo/scan"
+)
+
+type account struct {
+	ID    int
+	Name  string
+	Email *string
+}
+
+func TestQueryer(t *testing.T) {
+	ctx := context.Background()
+	errFailed := errors.New("failed")
+
+	q := NewQueryer().
+		Add("SELECT * FROM accounts", NewRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"})).
+		AddError("DELETE FROM accounts", errFailed)
+
+	for i := 0; i < 2; i++ {
+		accounts, err := scan.All(ctx, q, scan.StructMapper[account](), "SELECT *\n\tFROM accounts")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		AssertRowsEqual(t, []account{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}}, accounts)
+	}
+
+	if _, err := scan.All(ctx, q, scan.StructMapper[account](), "DELETE FROM accounts"); !errors.Is(err, errFailed) {
+		t.Fatalf("expected the added error, got %v", err)
+	}
+
+	if _, err := scan.All(ctx, q, scan.StructMapper[account](), "SELECT 1", 5); !errors.Is(err, ErrUnexpectedQuery) {
+		t.Fatalf("expected ErrUnexpectedQuery, got %v", err)
+	}
+
+	expected := []Query{
+		{SQL: "SELECT *\n\tFROM accounts"},
+		{SQL: "SELECT *\n\tFROM accounts"},
+		{SQL: "DELETE FROM accounts"},
+		{SQL: "SELECT 1", Args: []any{5}},
+	}
+	if diff := cmp.Diff(expected, q.Queries()); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestRowsFromMaps(t *testing.T) {
+	rows := RowsFromMaps([]map[string]any{
+		{"id": 1, "name": "foo", "email": "foo@example.com"},
+		{"id": 2, "name": "bar"},
+	})
+
+	accounts, err := scan.AllFromRows(context.Background(), scan.StructMapper[account](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	email := "foo@example.com"
+	AssertRowsEqual(t, []account{{ID: 1, Name: "foo", Email: &email}, {ID: 2, Name: "bar"}}, accounts)
+}
+
+func TestRowsFromCSV(t *testing.T) {
+	rows, err := RowsFromCSV(strings.NewReader("id,name,email\n1,foo,foo@example.com\n2,bar,\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	accounts, err := scan.AllFromRows(context.Background(), scan.StructMapper[account](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	email := "foo@example.com"
+	AssertRowsEqual(t, []account{{ID: 1, Name: "foo", Email: &email}, {ID: 2, Name: "bar"}}, accounts)
+
+	if _, err := RowsFromCSV(strings.NewReader("")); err == nil {
+		t.Fatal("expected an error for CSV without a header")
+	}
+}
+
+func TestRowsWithErr(t *testing.T) {
+	errFailed := errors.New("failed")
+	rows := NewRows([]string{"id"}, []any{1}, []any{2}).WithErr(errFailed)
+
+	ids, err := scan.AllFromRows(context.Background(), scan.SingleColumnMapper[int], rows)
+	if !errors.Is(err, errFailed) {
+		t.Fatalf("expected the rows error, got %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1, 2}, ids); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
@@ -0,0 +1,152 @@
+package scantest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/aarondl/opt"
+)
+
+// Rows is an in-memory implementation of [scan.Rows].
+// Values are converted to the scan destinations the same way as database/sql,
+// and values that already have the type of the destination are set directly
+type Rows struct {
+	cols   []string
+	rows   [][]any
+	err    error
+	index  int
+	closed bool
+}
+
+// NewRows returns rows with the columns and a slice of values for each row
+//
+//	rows := scantest.NewRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"})
+func NewRows(cols []string, values ...[]any) *Rows {
+	return &Rows{cols: cols, rows: values}
+}
+
+// RowsFromMaps returns rows with a row for each map.
+// The columns are the keys of all the maps in sorted order,
+// and keys that are missing from a map are NULL in its row
+func RowsFromMaps(maps []map[string]any) *Rows {
+	seen := make(map[string]bool)
+	var cols []string
+	for _, m := range maps {
+		for col := range m {
+			if !seen[col] {
+				seen[col] = true
+				cols = append(cols, col)
+			}
+		}
+	}
+	sort.Strings(cols)
+
+	values := make([][]any, len(maps))
+	for i, m := range maps {
+		values[i] = make([]any, len(cols))
+		for j, col := range cols {
+			values[i][j] = m[col]
+		}
+	}
+
+	return NewRows(cols, values...)
+}
+
+// RowsFromCSV returns rows read from CSV, where the first record has the column names.
+// Values are strings, except empty values, which are NULL
+func RowsFromCSV(r io.Reader) (*Rows, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("CSV has no header")
+	}
+
+	values := make([][]any, len(records)-1)
+	for i, record := range records[1:] {
+		values[i] = make([]any, len(record))
+		for j, val := range record {
+			if val != "" {
+				values[i][j] = val
+			}
+		}
+	}
+
+	return NewRows(records[0], values...), nil
+}
+
+// WithErr returns a copy of the rows that returns err from Err once all rows have been read,
+// as if the result failed part way
+func (r *Rows) WithErr(err error) *Rows {
+	c := r.copy()
+	c.err = err
+	return c
+}
+
+// copy returns unread rows with the same values
+func (r *Rows) copy() *Rows {
+	return &Rows{cols: r.cols, rows: r.rows, err: r.err}
+}
+
+func (r *Rows) Scan(dest ...any) error {
+	if r.index == 0 || r.index > len(r.rows) {
+		return fmt.Errorf("Scan called without a row")
+	}
+
+	if len(dest) != len(r.cols) {
+		return fmt.Errorf("expected %d destinations, got %d", len(r.cols), len(dest))
+	}
+
+	row := r.rows[r.index-1]
+	for i, d := range dest {
+		var val any
+		if i < len(row) {
+			val = row[i]
+		}
+
+		// Values that are already of the destination type are set directly
+		if dv := reflect.ValueOf(d); val != nil && dv.Kind() == reflect.Pointer &&
+			reflect.TypeOf(val) == dv.Type().Elem() {
+			dv.Elem().Set(reflect.ValueOf(val))
+			continue
+		}
+
+		if err := opt.ConvertAssign(d, val); err != nil {
+			// The same format as database/sql
+			return fmt.Errorf("sql: Scan error on column index %d, name %q: %w", i, r.cols[i], err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Rows) Columns() ([]string, error) {
+	return r.cols, nil
+}
+
+func (r *Rows) Next() bool {
+	if r.closed || r.index >= len(r.rows) {
+		return false
+	}
+
+	r.index++
+	return true
+}
+
+func (r *Rows) Close() error {
+	r.closed = true
+	return nil
+}
+
+func (r *Rows) Err() error {
+	if r.index < len(r.rows) {
+		return nil
+	}
+
+	return r.err
+}
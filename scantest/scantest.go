@@ -0,0 +1,74 @@
+// Package scantest has helpers to test mappers and the code that scans rows
+// with the scan package, without a database
+package scantest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stephenafamo/scan"
+)
+
+// AssertScansTo fails the test if a row with the columns and values
+// is not scanned by [scan.StructMapper] into the expected value.
+// Differences are reported for each column, see [AssertRowsEqual]
+//
+//	scantest.AssertScansTo(t, []string{"id", "name"}, []any{1, "foo"}, User{ID: 1, Name: "foo"})
+func AssertScansTo[T any](t testing.TB, cols []string, vals []any, expected T, opts ...scan.MappingOption) {
+	t.Helper()
+
+	got, err := scan.OneFromRows(context.Background(), scan.StructMapper[T](opts...), NewRows(cols, vals))
+	if err != nil {
+		t.Fatalf("scanning %v: %v", cols, err)
+		return
+	}
+
+	if msg := diffRow(expected, got); msg != "" {
+		t.Errorf("scanned value differs from the expected value:\n%s", msg)
+	}
+}
+
+// AssertRowsEqual fails the test if the rows differ in the fields that are mapped to columns.
+// Fields that are not mapped, such as those tagged with `db:"-"`, are ignored.
+// Each difference is reported with its row and column:
+//
+//	row 0, column "name": expected "foo", got "bar"
+func AssertRowsEqual[T any](t testing.TB, expected, got []T) {
+	t.Helper()
+
+	var b strings.Builder
+	if len(expected) != len(got) {
+		fmt.Fprintf(&b, "expected %d rows, got %d\n", len(expected), len(got))
+	}
+
+	for i := 0; i < len(expected) && i < len(got); i++ {
+		if msg := diffRow(expected[i], got[i]); msg != "" {
+			fmt.Fprintf(&b, "row %d, %s", i, msg)
+		}
+	}
+
+	if b.Len() > 0 {
+		t.Errorf("rows differ:\n%s", b.String())
+	}
+}
+
+// diffRow returns a line for each mapped column with a different value
+func diffRow[T any](expected, got T) string {
+	var b strings.Builder
+	for i, d := range scan.DiffMapped(expected, got) {
+		if i > 0 {
+			b.WriteString("\t")
+		}
+
+		if d.Column == "" {
+			fmt.Fprintf(&b, "expected %#v, got %#v\n", d.A, d.B)
+			continue
+		}
+
+		fmt.Fprintf(&b, "column %q: expected %#v, got %#v\n", d.Column, d.A, d.B)
+	}
+
+	return b.String()
+}
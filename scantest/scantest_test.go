@@ -0,0 +1,95 @@
+package scantest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stephenafamo/scan"
+)
+
+type user struct {
+	ID      int
+	Name    *string
+	Created time.Time
+	Cached  bool `db:"-"`
+}
+
+// recorder records the failures of an assertion
+type recorder struct {
+	testing.TB
+	failed bool
+	msgs   []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failed = true
+	r.msgs = append(r.msgs, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+// run runs the assertion in its own goroutine as the testing package does
+func run(fn func(t testing.TB)) *recorder {
+	r := &recorder{}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fn(r)
+	}()
+	wg.Wait()
+
+	return r
+}
+
+func TestAssertScansTo(t *testing.T) {
+	name := "foo"
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	cols := []string{"id", "name", "created"}
+
+	AssertScansTo(t, cols, []any{1, "foo", created}, user{ID: 1, Name: &name, Created: created})
+
+	r := run(func(t testing.TB) {
+		AssertScansTo(t, cols, []any{1, "bar", created}, user{ID: 1, Name: &name, Created: created, Cached: true})
+	})
+	if !r.failed || !strings.Contains(r.msgs[0], `column "name": expected "foo", got "bar"`) {
+		t.Fatalf("expected a failure for the name column, got %v", r.msgs)
+	}
+
+	r = run(func(t testing.TB) {
+		AssertScansTo(t, []string{"unknown"}, []any{1}, user{})
+	})
+	if !r.failed {
+		t.Fatal("expected a failure for an unknown column")
+	}
+
+	// Mapping options are used
+	AssertScansTo(t, []string{"id", "unknown"}, []any{1, 2}, user{ID: 1}, scan.WithAllowUnknownColumns(true))
+}
+
+func TestAssertRowsEqual(t *testing.T) {
+	name := "foo"
+	expected := []user{{ID: 1, Name: &name}, {ID: 2}}
+
+	AssertRowsEqual(t, expected, []user{{ID: 1, Name: &name, Cached: true}, {ID: 2}})
+
+	r := run(func(t testing.TB) {
+		AssertRowsEqual(t, expected, []user{{ID: 1}, {ID: 3}, {ID: 4}})
+	})
+
+	want := `rows differ:
+expected 2 rows, got 3
+row 0, column "name": expected "foo", got <nil>
+row 1, column "id": expected 2, got 3
+`
+	if len(r.msgs) != 1 || r.msgs[0] != want {
+		t.Fatalf("unexpected failure message:\n%s", strings.Join(r.msgs, "\n"))
+	}
+}
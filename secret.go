@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/aarondl/opt"
+)
+
+// Redacted is printed in place of the value of a [Secret]
+const Redacted = "[REDACTED]"
+
+// Secret holds a scanned value such as a token or password that should not leak
+// through logs of mapped structs. It is printed as [Redacted] by the fmt package
+// and marshalled as [Redacted] to JSON and text. Use [Secret.Reveal] to get the value.
+//
+//	type Account struct {
+//	    ID    int
+//	    Token scan.Secret[string]
+//	}
+//
+// NULL values are scanned as the zero value of T
+type Secret[T any] struct {
+	value T
+}
+
+// NewSecret returns a [Secret] holding the value
+func NewSecret[T any](value T) Secret[T] {
+	return Secret[T]{value: value}
+}
+
+// Reveal returns the secret value
+func (s Secret[T]) Reveal() T {
+	return s.value
+}
+
+// Scan implements [sql.Scanner]
+func (s *Secret[T]) Scan(src any) error {
+	if scanner, ok := any(&s.value).(sql.Scanner); ok {
+		return scanner.Scan(src)
+	}
+
+	if src == nil {
+		var zero T
+		s.value = zero
+		return nil
+	}
+
+	return opt.ConvertAssign(&s.value, src)
+}
+
+// Value implements [driver.Valuer] so that the secret can be written back
+func (s Secret[T]) Value() (driver.Value, error) {
+	if valuer, ok := any(s.value).(driver.Valuer); ok {
+		return valuer.Value()
+	}
+
+	return s.value, nil
+}
+
+// String implements [fmt.Stringer]
+func (s Secret[T]) String() string {
+	return Redacted
+}
+
+// GoString implements [fmt.GoStringer], for the %#v verb
+func (s Secret[T]) GoString() string {
+	return Redacted
+}
+
+// Format implements [fmt.Formatter] so that every verb is redacted
+func (s Secret[T]) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, Redacted)
+}
+
+// MarshalText implements [encoding.TextMarshaler]
+func (s Secret[T]) MarshalText() ([]byte, error) {
+	return []byte(Redacted), nil
+}
+
+// MarshalJSON implements [json.Marshaler]
+func (s Secret[T]) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + Redacted + `"`), nil
+}
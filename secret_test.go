@@ -0,0 +1,55 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type Credentials struct {
+	ID     int
+	Token  Secret[string]
+	Backup *Secret[[]byte]
+}
+
+func TestSecret(t *testing.T) {
+	rows := newSliceRows([]string{"id", "token", "backup"},
+		[]any{1, "s3cr3t", []byte("b4ckup")},
+		[]any{2, nil, nil},
+	)
+
+	creds, err := AllFromRows(context.Background(), StructMapper[Credentials](), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if creds[0].Token.Reveal() != "s3cr3t" || string(creds[0].Backup.Reveal()) != "b4ckup" {
+		t.Fatalf("unexpected values: %q %q", creds[0].Token.Reveal(), creds[0].Backup.Reveal())
+	}
+
+	if creds[1].Token.Reveal() != "" || creds[1].Backup != nil {
+		t.Fatalf("expected zero values for NULL, got %#v", creds[1])
+	}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%x"} {
+		if out := fmt.Sprintf(format, creds[0]); strings.Contains(out, "s3cr3t") || strings.Contains(out, "b4ckup") {
+			t.Fatalf("%s leaked the secret: %s", format, out)
+		}
+	}
+
+	data, err := json.Marshal(creds[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"ID":1,"Token":"[REDACTED]","Backup":"[REDACTED]"}`
+	if string(data) != expected {
+		t.Fatalf("expected %s, got %s", expected, data)
+	}
+
+	if v, _ := NewSecret("x").Value(); v != "x" {
+		t.Fatalf("expected the value to be written back, got %v", v)
+	}
+}
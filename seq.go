@@ -0,0 +1,80 @@
+//go:build go1.23
+
+package scan
+
+import (
+	"context"
+	"iter"
+)
+
+// Seq runs the query and returns an iterator over the mapped rows, for range-over-func loops.
+// The rows are closed when the loop ends, also when it exits early.
+// An error, from the query, a row or the context, is yielded once with the zero value and ends the loop.
+//
+// The query runs when the loop starts, and again each time the iterator is used.
+//
+//	for user, err := range scan.Seq(ctx, exec, scan.StructMapper[User](), "SELECT * FROM users") {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    // ...
+//	}
+func Seq[T any](ctx context.Context, exec Queryer, m Mapper[T], query string, args ...any) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
+		if err != nil {
+			var t T
+			yield(t, err)
+			return
+		}
+		defer rows.Close()
+
+		for t, err := range SeqFromRows(ctx, m, rows) {
+			if !yield(t, withQuery(err, query)) {
+				return
+			}
+		}
+	}
+}
+
+// SeqFromRows returns an iterator over the rows of the given [Rows]. See [Seq].
+// The rows are closed when the loop ends
+func SeqFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+
+		var t T
+		allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+		v, err := wrapRows(rows, allowUnknown)
+		if err != nil {
+			yield(t, err)
+			return
+		}
+
+		before, after := buildMapper(ctx, m, v, v.columnsCopy())
+
+		for rows.Next() {
+			if err := ctx.Err(); err != nil {
+				yield(t, err)
+				return
+			}
+
+			one, err := scanOneRow(v, before, after)
+			if isSkipped(err) {
+				continue
+			}
+			if err != nil {
+				yield(t, err)
+				return
+			}
+
+			if !yield(one, nil) {
+				return
+			}
+		}
+
+		if err := rows.Err(); err != nil {
+			yield(t, err)
+		}
+	}
+}
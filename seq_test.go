@@ -0,0 +1,50 @@
+//go:build go1.23
+
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSeq(t *testing.T) {
+	ctx := context.Background()
+
+	var users []User
+	for u, err := range Seq(ctx, funcQ(usersByID), StructMapper[User](), "", 1, 2, 3) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		users = append(users, u)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 3}, ids(users)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The rows are closed when the loop exits early
+	rows := newSliceRows([]string{"id", "name"}, []any{1, "a"}, []any{2, "b"})
+	for range SeqFromRows(ctx, StructMapper[User](), rows) {
+		break
+	}
+	if !rows.closed {
+		t.Fatal("expected the rows to be closed")
+	}
+
+	// Errors end the loop
+	rows = newSliceRows([]string{"id", "name"}, []any{1, "a"}, []any{"x", "b"}, []any{3, "c"})
+	var seen int
+	var err error
+	for _, err = range SeqFromRows(ctx, StructMapper[User](), rows) {
+		if err != nil {
+			break
+		}
+		seen++
+	}
+	if seen != 1 || !errors.Is(err, ErrConversion) {
+		t.Fatalf("expected ErrConversion after 1 row, got %v after %d", err, seen)
+	}
+}
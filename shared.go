@@ -0,0 +1,127 @@
+package scan
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/aarondl/opt"
+)
+
+// SharedColumns decides what the struct mapper does when several fields
+// are mapped to the same column, e.g. ID and a denormalized LegacyID tagged db:"id".
+// See [WithSharedColumns]
+type SharedColumns int
+
+const (
+	// SharedColumnsFirst scans the column into the first field only
+	SharedColumnsFirst SharedColumns = iota
+	// SharedColumnsFanOut scans the column into every field mapped to it
+	SharedColumnsFanOut
+	// SharedColumnsError returns an error when building the mapper
+	// if a column of the query is mapped to several fields
+	SharedColumnsError
+)
+
+// WithSharedColumns sets how a column that is mapped to several fields is scanned.
+// With [SharedColumnsFanOut], the value is converted for each field on its own,
+// so the fields can have different types and tag options
+func WithSharedColumns(mode SharedColumns) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.sharedColumns = mode
+	}
+}
+
+// withSharedColumns adds the other fields of the mapping that have the same key
+// as a field of the filtered mapping. With [SharedColumnsError], the names of the fields
+// of the first shared column are returned instead
+func withSharedColumns(filtered, m mapping, prefix string, mode SharedColumns) (mapping, mapping) {
+	shared := filtered
+	for _, info := range filtered {
+		key := strings.TrimPrefix(info.name, prefix)
+
+		fields := mapping{info}
+		for _, other := range m {
+			if other.name != key || samePosition(other.position, info.position) {
+				continue
+			}
+
+			other.name = info.name
+			fields = append(fields, other)
+		}
+
+		if len(fields) == 1 {
+			continue
+		}
+
+		if mode == SharedColumnsError {
+			return nil, fields
+		}
+
+		shared = append(shared, fields[1:]...)
+	}
+
+	return shared, nil
+}
+
+// warnShadowed reports the fields that are not scanned because another field
+// is mapped to the same column of the filtered mapping
+func warnShadowed(filtered, m mapping, typ reflect.Type, isPointer bool, opts mappingOptions) {
+	all, _ := withSharedColumns(filtered, m, opts.structTagPrefix, SharedColumnsFanOut)
+	shadowed := all[len(filtered):]
+	if len(shadowed) == 0 {
+		return
+	}
+
+	names := fieldPaths(shadowed, typ, isPointer)
+	for i, info := range shadowed {
+		opts.warnings.warn(WarningShadowedField, info.name, names[i],
+			"Field %s is not scanned because column %q is mapped to another field", names[i], info.name)
+	}
+}
+
+func samePosition(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// fanOutDest scans the value of a column into several destinations
+type fanOutDest struct {
+	dests []any
+}
+
+func (f *fanOutDest) Scan(src any) error {
+	for _, dest := range f.dests {
+		if err := opt.ConvertAssign(dest, src); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+var fanOutType = reflect.TypeOf(&fanOutDest{})
+
+// fanOut returns a destination that scans into both the scheduled
+// destination of a column and val, so that the column can be shared
+func fanOut(scheduled, val reflect.Value) reflect.Value {
+	if scheduled == zeroValue {
+		return val
+	}
+
+	if scheduled.Type() == fanOutType {
+		f := scheduled.Interface().(*fanOutDest)
+		f.dests = append(f.dests, val.Interface())
+		return scheduled
+	}
+
+	return reflect.ValueOf(&fanOutDest{dests: []any{scheduled.Interface(), val.Interface()}})
+}
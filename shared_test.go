@@ -0,0 +1,70 @@
+package scan
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type legacyUser struct {
+	ID       int    `db:"id"`
+	LegacyID string `db:"id"`
+	OldID    *int64 `db:"id"`
+	Name     string
+}
+
+func TestSharedColumns(t *testing.T) {
+	ctx := context.Background()
+	newRows := func() Rows {
+		return newSliceRows([]string{"id", "name"},
+			[]any{int64(1), "foo"},
+			[]any{int64(2), "bar"},
+		)
+	}
+
+	users, err := AllFromRows(ctx, StructMapper[legacyUser](), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// By default only the first field is scanned
+	if diff := cmp.Diff([]legacyUser{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	expected := []legacyUser{
+		{ID: 1, LegacyID: "1", OldID: toPtr(int64(1)), Name: "foo"},
+		{ID: 2, LegacyID: "2", OldID: toPtr(int64(2)), Name: "bar"},
+	}
+
+	fanOut := []MappingOption{WithSharedColumns(SharedColumnsFanOut)}
+	for name, opts := range map[string][]MappingOption{
+		"fan out":        fanOut,
+		"type converter": append(fanOut, WithTypeConverter(NewConverters())),
+		"null handling":  append(fanOut, WithNullHandling(NullZero)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			users, err := AllFromRows(ctx, StructMapper[legacyUser](opts...), newRows())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if diff := cmp.Diff(expected, users); diff != "" {
+				t.Fatalf("diff: %s", diff)
+			}
+		})
+	}
+
+	_, err = AllFromRows(ctx, StructMapper[legacyUser](WithSharedColumns(SharedColumnsError)), newRows())
+	if err == nil || !strings.Contains(err.Error(), "LegacyID") {
+		t.Fatalf("expected an error naming the shared fields, got %v", err)
+	}
+
+	// Columns that are not in the query are not checked
+	rows := newSliceRows([]string{"name"}, []any{"foo"})
+	if _, err := AllFromRows(ctx, StructMapper[legacyUser](WithSharedColumns(SharedColumnsError)), rows); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
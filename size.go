@@ -0,0 +1,191 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+)
+
+// estimatedVarWidth is the number of bytes assumed for the contents
+// of strings, slices and maps when estimating the size of a type
+const estimatedVarWidth = 32
+
+// maxSizeDepth limits how deep pointers are followed when measuring a value,
+// so that cycles do not recurse forever
+const maxSizeDepth = 32
+
+// EstimateRowSize returns the approximate number of bytes held in memory by a
+// single row mapped to T, for capacity planning.
+//
+// The contents of strings, slices and maps cannot be known from the type,
+// so each is assumed to hold 32 bytes. Pointers are assumed to be set.
+// The same estimate is used by [PreFlight] when the [Estimator] cannot tell the width of the rows
+func EstimateRowSize[T any]() int64 {
+	return estimateSize(typeOf[T]())
+}
+
+// estimateSize returns the approximate number of bytes held by a value of the type
+func estimateSize(typ reflect.Type) int64 {
+	if typ == nil {
+		return 0
+	}
+
+	return int64(typ.Size()) + estimateIndirect(typ, 0)
+}
+
+// estimateIndirect returns the approximate number of bytes a value of the type points to
+func estimateIndirect(typ reflect.Type, depth int) int64 {
+	if depth > maxSizeDepth {
+		return 0
+	}
+
+	switch typ.Kind() {
+	case reflect.Pointer:
+		return int64(typ.Elem().Size()) + estimateIndirect(typ.Elem(), depth+1)
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Interface:
+		return estimatedVarWidth
+	case reflect.Array:
+		return int64(typ.Len()) * estimateIndirect(typ.Elem(), depth+1)
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < typ.NumField(); i++ {
+			size += estimateIndirect(typ.Field(i).Type, depth+1)
+		}
+		return size
+	default:
+		return 0
+	}
+}
+
+// sizeOf returns the approximate number of bytes held by the value,
+// including the memory it points to
+func sizeOf(v reflect.Value) int64 {
+	if !v.IsValid() {
+		return 0
+	}
+
+	return int64(v.Type().Size()) + indirectSize(v, 0)
+}
+
+// indirectSize returns the approximate number of bytes the value points to
+func indirectSize(v reflect.Value, depth int) int64 {
+	if depth > maxSizeDepth {
+		return 0
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + indirectSize(elem, depth+1)
+
+	case reflect.String:
+		return int64(v.Len())
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return 0
+		}
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		if hasIndirect(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				size += indirectSize(v.Index(i), depth+1)
+			}
+		}
+		return size
+
+	case reflect.Array:
+		var size int64
+		if hasIndirect(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				size += indirectSize(v.Index(i), depth+1)
+			}
+		}
+		return size
+
+	case reflect.Map:
+		if v.IsNil() {
+			return 0
+		}
+		entry := int64(v.Type().Key().Size() + v.Type().Elem().Size())
+		size := int64(v.Len()) * entry
+		iter := v.MapRange()
+		for iter.Next() {
+			size += indirectSize(iter.Key(), depth+1) + indirectSize(iter.Value(), depth+1)
+		}
+		return size
+
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += indirectSize(v.Field(i), depth+1)
+		}
+		return size
+
+	default:
+		return 0
+	}
+}
+
+// hasIndirect reports if values of the type can point to other memory
+func hasIndirect(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		return false
+	case reflect.Array:
+		return hasIndirect(typ.Elem())
+	}
+
+	return true
+}
+
+// MemoryUsage accounts for the approximate memory retained by the results of
+// the scanning functions that return all rows, such as [All] and [AllFromRows].
+// Add it to a context with [WithMemoryUsage]. It is safe for concurrent use
+type MemoryUsage struct {
+	rows  int64
+	bytes int64
+}
+
+// Rows returns the number of rows accounted for
+func (u *MemoryUsage) Rows() int64 {
+	return atomic.LoadInt64(&u.rows)
+}
+
+// Bytes returns the approximate number of bytes retained by the results
+func (u *MemoryUsage) Bytes() int64 {
+	return atomic.LoadInt64(&u.bytes)
+}
+
+func (u *MemoryUsage) add(rows, bytes int64) {
+	atomic.AddInt64(&u.rows, rows)
+	atomic.AddInt64(&u.bytes, bytes)
+}
+
+// ctxKeyMemoryUsage holds the [MemoryUsage] for the queries run with the context
+var ctxKeyMemoryUsage contextKey = "memory usage"
+
+// WithMemoryUsage returns a context that adds the memory retained by the results
+// of every [All] and [AllFromRows] run with it to u
+func WithMemoryUsage(ctx context.Context, u *MemoryUsage) context.Context {
+	return context.WithValue(ctx, ctxKeyMemoryUsage, u)
+}
+
+// accountResults adds the memory retained by the results to the [MemoryUsage]
+// in the context, if any
+func accountResults[T any](ctx context.Context, results []T) {
+	u, _ := ctx.Value(ctxKeyMemoryUsage).(*MemoryUsage)
+	if u == nil {
+		return
+	}
+
+	u.add(int64(len(results)), indirectSize(reflect.ValueOf(results), 0))
+}
+
+// ctxKeyRowType holds the type the rows of a query are mapped to,
+// for queryers that estimate the size of the rows
+var ctxKeyRowType contextKey = "row type"
@@ -0,0 +1,106 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEstimateRowSize(t *testing.T) {
+	type account struct {
+		ID     int64
+		Active bool
+		Name   string
+		Tags   []string
+		Owner  *struct{ ID int64 }
+		Codes  [2]int32
+	}
+
+	// 8 + 8 (bool and padding) + 16 + 24 + 8 + 8 for the struct,
+	// 32 for each of the string and the slice, and 8 for the owner
+	if got := EstimateRowSize[account](); got != 144 {
+		t.Fatalf("expected 144, got %d", got)
+	}
+
+	if got := EstimateRowSize[int64](); got != 8 {
+		t.Fatalf("expected 8, got %d", got)
+	}
+}
+
+func TestSizeOf(t *testing.T) {
+	s := "hello"
+	var nilPtr *string
+	cases := []struct {
+		value any
+		size  int64
+	}{
+		{value: &s, size: 8 + 16 + 5},
+		{value: []byte("abc"), size: 24 + 3},
+		{value: int64(1), size: 8},
+		{value: nilPtr, size: 8},
+		{value: []string{"ab", "cde"}, size: 24 + 2*16 + 5},
+		{value: map[string]int{"ab": 1}, size: 8 + 16 + 8 + 2},
+		{value: nil, size: 0},
+	}
+
+	for _, c := range cases {
+		if got := sizeOf(reflect.ValueOf(c.value)); got != c.size {
+			t.Fatalf("expected %d for %#v, got %d", c.size, c.value, got)
+		}
+	}
+
+	// Cycles stop at the maximum depth
+	type node struct{ Next *node }
+	n := &node{}
+	n.Next = n
+	if got := sizeOf(reflect.ValueOf(n)); got <= 0 {
+		t.Fatalf("expected a size for a cycle, got %d", got)
+	}
+}
+
+func TestMemoryUsage(t *testing.T) {
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id", "name"}, []any{1, "ab"}, []any{2, "cde"}), nil
+	})
+
+	var usage MemoryUsage
+	ctx := WithMemoryUsage(context.Background(), &usage)
+
+	users, err := All(ctx, exec, StructMapper[User](), "SELECT")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if usage.Rows() != 2 {
+		t.Fatalf("expected 2 rows, got %d", usage.Rows())
+	}
+
+	expected := sizeOf(reflect.ValueOf(users)) - int64(reflect.TypeOf(users).Size())
+	if usage.Bytes() != expected {
+		t.Fatalf("expected %d bytes, got %d", expected, usage.Bytes())
+	}
+}
+
+func TestPreFlightRowSize(t *testing.T) {
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return newSliceRows([]string{"id"}), nil
+	})
+
+	estimator := func(ctx context.Context, exec Queryer, query string, args ...any) (Estimate, error) {
+		return Estimate{Rows: 10}, nil
+	}
+
+	size := EstimateRowSize[User]()
+	ctx := context.Background()
+
+	q := PreFlight(exec, estimator, WithMaxBytes(10*size))
+	if _, err := All(ctx, q, StructMapper[User](), "SELECT"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	q = PreFlight(exec, estimator, WithMaxBytes(10*size-1))
+	if _, err := All(ctx, q, StructMapper[User](), "SELECT"); !errors.Is(err, ErrEstimateExceeded) {
+		t.Fatalf("expected ErrEstimateExceeded, got %v", err)
+	}
+}
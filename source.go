@@ -5,32 +5,22 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
-	"regexp"
 	"strings"
 	"sync"
+	"unsafe"
 )
 
-var (
-	matchFirstCapRe     = regexp.MustCompile("(.)([A-Z][a-z]+)")
-	matchAllCapRe       = regexp.MustCompile("([a-z0-9])([A-Z])")
-	defaultStructMapper = newDefaultMapperSourceImpl()
-)
-
-// snakeCaseFieldFunc is a NameMapperFunc that maps struct field to snake case.
-func snakeCaseFieldFunc(str string) string {
-	snake := matchFirstCapRe.ReplaceAllString(str, "${1}_${2}")
-	snake = matchAllCapRe.ReplaceAllString(snake, "${1}_${2}")
-	return strings.ToLower(snake)
-}
+var defaultStructMapper = newDefaultMapperSourceImpl()
 
 func newDefaultMapperSourceImpl() *mapperSourceImpl {
 	return &mapperSourceImpl{
-		structTagKey:    "db",
+		structTagKeys:   []string{"db"},
 		columnSeparator: ".",
-		fieldMapperFn:   snakeCaseFieldFunc,
+		fieldMapperFn:   SnakeCase,
 		scannableTypes:  []reflect.Type{reflect.TypeOf((*sql.Scanner)(nil)).Elem()},
 		maxDepth:        3,
-		cache:           make(map[reflect.Type]mapping),
+		unexported:      make(map[reflect.Type]bool),
+		cache:           make(map[mappingKey]builtMapping),
 	}
 }
 
@@ -51,8 +41,19 @@ type MappingSourceOption func(src *mapperSourceImpl) error
 // WithStructTagKey allows to use a custom struct tag key.
 // The default tag key is `db`.
 func WithStructTagKey(tagKey string) MappingSourceOption {
+	return WithStructTagKeys(tagKey)
+}
+
+// WithStructTagKeys allows to use several struct tag keys, in order of priority.
+// A field is mapped with the first key it has a tag for, e.g. with ("db", "json")
+// the json tag is used for fields without a db tag
+func WithStructTagKeys(tagKeys ...string) MappingSourceOption {
 	return func(src *mapperSourceImpl) error {
-		src.structTagKey = tagKey
+		if len(tagKeys) == 0 {
+			return fmt.Errorf("at least one struct tag key is required")
+		}
+
+		src.structTagKeys = tagKeys
 		return nil
 	}
 }
@@ -75,6 +76,16 @@ func WithFieldNameMapper(mapperFn func(string) string) MappingSourceOption {
 	}
 }
 
+// WithInitialisms maps field names to snake case with [NewSnakeCase], keeping the initialisms
+// as single words in addition to [DefaultInitialisms], e.g. WithInitialisms("OAuth", "SKU").
+// It replaces the function set with [WithFieldNameMapper]
+func WithInitialisms(initialisms ...string) MappingSourceOption {
+	return func(src *mapperSourceImpl) error {
+		src.fieldMapperFn = NewSnakeCase(initialisms...)
+		return nil
+	}
+}
+
 // WithScannableTypes specifies a list of interfaces that underlying database library can scan into.
 // In case the destination type passed to scan implements one of those interfaces,
 // scan will handle it as primitive type case i.e. simply pass the destination to the database library.
@@ -111,38 +122,120 @@ func WithScannableTypes(scannableTypes ...any) MappingSourceOption {
 	}
 }
 
+// WithCurrencies registers custom currencies for the currency codes used with the money tag option,
+// e.g. `db:"price,money,currency=BTC"`. Other codes are looked up as a [CurrencyCode]
+func WithCurrencies(currencies ...Currency) MappingSourceOption {
+	return func(src *mapperSourceImpl) error {
+		if src.currencies == nil {
+			src.currencies = make(map[string]Currency, len(currencies))
+		}
+		for _, c := range currencies {
+			src.currencies[c.Code()] = c
+		}
+		return nil
+	}
+}
+
+// WithUnexportedFields maps the unexported fields of the given struct types,
+// for domain models that deliberately keep their storage fields unexported.
+// Types can be passed as a value or a pointer e.g. User{} or (*User)(nil).
+//
+// Unexported fields are set directly, so this should only be used for types you control.
+func WithUnexportedFields(types ...any) MappingSourceOption {
+	return func(src *mapperSourceImpl) error {
+		for _, t := range types {
+			typ := reflect.TypeOf(t)
+			if typ != nil && typ.Kind() == reflect.Pointer {
+				typ = typ.Elem()
+			}
+			if typ == nil || typ.Kind() != reflect.Struct {
+				return fmt.Errorf("type with unexported fields must be a struct, got %T", t)
+			}
+			src.unexported[typ] = true
+		}
+		return nil
+	}
+}
+
 // mapperSourceImpl is an implementation of StructMapperSource.
 type mapperSourceImpl struct {
-	structTagKey    string
+	structTagKeys   []string
 	columnSeparator string
 	fieldMapperFn   func(string) string
 	scannableTypes  []reflect.Type
 	maxDepth        int
-	cache           map[reflect.Type]mapping
+	unexported      map[reflect.Type]bool
+	currencies      map[string]Currency
+	units           map[string]UnitConversion
+	cache           map[mappingKey]builtMapping
 	mutex           sync.RWMutex
 }
 
+// mappingKey is the key of a cached mapping. The depth is part of the key
+// since it can be set for each mapper with [WithMaxDepth]
+type mappingKey struct {
+	typ   reflect.Type
+	depth int
+}
+
+// builtMapping is the mapping of a type and the fields that were left out of it
+type builtMapping struct {
+	m          mapping
+	recursions []recursion
+}
+
+// recursion is a struct field that is not mapped because its type
+// is already mapped the maximum number of times on the path to it
+type recursion struct {
+	// prefix is the start of the column keys of the fields of the struct
+	prefix   string
+	position []int
+	depth    int
+}
+
 func (s *mapperSourceImpl) getMapping(typ reflect.Type) (mapping, error) {
+	m, _, err := s.getMappingDepth(typ, s.maxDepth)
+	return m, err
+}
+
+// getMappingDepth returns the mapping of typ where the types of fields are mapped
+// at most depth+1 times on each path, and the fields that were left out because of it.
+// A negative depth is the default of the source
+func (s *mapperSourceImpl) getMappingDepth(typ reflect.Type, depth int) (mapping, []recursion, error) {
+	if depth < 0 {
+		depth = s.maxDepth
+	}
+
+	key := mappingKey{typ: typ, depth: depth}
+
 	s.mutex.RLock()
-	m, ok := s.cache[typ]
+	b, ok := s.cache[key]
 	s.mutex.RUnlock()
 
 	if ok {
-		return m, nil
+		return b.m, b.recursions, nil
 	}
 
-	s.setMappings(typ, "", make(visited), &m, nil)
+	b = builtMapping{}
+	s.setMappings(typ, "", make(visited), &b, depth, nil, false)
 
 	s.mutex.Lock()
-	s.cache[typ] = m
+	s.cache[key] = b
 	s.mutex.Unlock()
 
-	return m, nil
+	return b.m, b.recursions, nil
 }
 
-func (s *mapperSourceImpl) setMappings(typ reflect.Type, prefix string, v visited, m *mapping, inits [][]int, position ...int) {
+// setMappings adds the mappings for the fields of typ.
+// If optional is true, then typ is inside a struct that is reached through a pointer
+func (s *mapperSourceImpl) setMappings(typ reflect.Type, prefix string, v visited, b *builtMapping, depth int, inits [][]int, optional bool, position ...int) {
 	count := v[typ]
-	if count > s.maxDepth {
+	if count > depth {
+		b.recursions = append(b.recursions, recursion{
+			prefix:   prefix + s.columnSeparator,
+			position: position,
+			depth:    depth,
+		})
 		return
 	}
 	v[typ] = count + 1
@@ -150,6 +243,7 @@ func (s *mapperSourceImpl) setMappings(typ reflect.Type, prefix string, v visite
 	var hasExported bool
 
 	var isPointer bool
+	fullType := typ
 	if typ.Kind() == reflect.Pointer {
 		isPointer = true
 		typ = typ.Elem()
@@ -159,106 +253,328 @@ func (s *mapperSourceImpl) setMappings(typ reflect.Type, prefix string, v visite
 	// as a value itself. Return it
 	for _, scannable := range s.scannableTypes {
 		if reflect.PtrTo(typ).Implements(scannable) {
-			*m = append(*m, mapinfo{
+			b.m = append(b.m, mapinfo{
 				name:      prefix,
 				position:  position,
 				init:      inits,
 				isPointer: isPointer,
+				optional:  optional,
 			})
 			return
 		}
 	}
 
+	// The fields of a struct reached through a pointer may be left nil
+	fieldsOptional := optional || isPointer
+
 	// Go through the struct fields and populate the map.
 	// Recursively go into any child structs, adding a prefix where necessary
 	for i := 0; i < typ.NumField(); i++ {
 		field := typ.Field(i)
 
-		// Don't consider unexported fields
-		if !field.IsExported() {
+		// Don't consider unexported fields unless the type allows it
+		if !field.IsExported() && !s.unexported[typ] {
 			continue
 		}
 
 		// Skip columns that have the tag "-"
-		tag := strings.Split(field.Tag.Get(s.structTagKey), ",")[0]
+		tag, tagOpts := parseTag(s.fieldTag(field))
 		if tag == "-" {
 			continue
 		}
 
 		hasExported = true
 
+		// A map field with the remain option receives the columns not matched to other fields
+		if tagOpts.has("remain") && field.Type.Kind() == reflect.Map && field.Type.Key().Kind() == reflect.String {
+			b.m = append(b.m, mapinfo{
+				position: append(position[:len(position):len(position)], i),
+				init:     inits,
+				optional: true,
+				typ:      field.Type,
+				remain:   true,
+			})
+			continue
+		}
+
 		key := prefix
 
-		if !field.Anonymous {
+		// Embedded structs are flattened unless they declare a prefix
+		if !field.Anonymous || tagOpts.has("prefix") {
 			var sep string
 			if prefix != "" {
 				sep = s.columnSeparator
 			}
 
+			// The exact option uses the field name as it is
 			name := tag
-			if tag == "" {
+			switch {
+			case tag == "" && tagOpts.has("exact"):
+				name = field.Name
+			case tag == "":
 				name = s.fieldMapperFn(field.Name)
 			}
 
 			key = strings.Join([]string{key, name}, sep)
 		}
 
-		currentIndex := append(position, i)
+		// Copy so that the slices are not shared between fields
+		currentIndex := make([]int, len(position)+1)
+		copy(currentIndex, position)
+		currentIndex[len(position)] = i
+
+		// Fields with a decoder are scanned from a single column
+		if decode := s.fieldDecoder(tagOpts); decode != nil {
+			b.m = append(b.m, mapinfo{
+				name:     key,
+				position: currentIndex,
+				init:     inits,
+				optional: fieldsOptional,
+				typ:      field.Type,
+				decode:   decode,
+				def:      tagOpts.defaultValue(),
+			})
+			continue
+		}
+
 		fieldType := field.Type
+		fieldInits := inits
 		var isPointer bool
 
 		if fieldType.Kind() == reflect.Pointer {
-			inits = append(inits, currentIndex)
+			fieldInits = append(inits[:len(inits):len(inits)], currentIndex)
 			fieldType = fieldType.Elem()
 			isPointer = true
 		}
 
 		if fieldType.Kind() == reflect.Struct {
-			s.setMappings(field.Type, key, v.copy(), m, inits, currentIndex...)
+			// The struct itself may be scanned as a single value by a type converter
+			if key != prefix {
+				b.m = append(b.m, mapinfo{
+					name:      key,
+					position:  currentIndex,
+					init:      inits,
+					isPointer: isPointer,
+					optional:  true,
+					container: true,
+					typ:       field.Type,
+				})
+			}
+
+			s.setMappings(field.Type, key, v.copy(), b, depth, fieldInits, fieldsOptional, currentIndex...)
 			continue
 		}
 
-		*m = append(*m, mapinfo{
+		b.m = append(b.m, mapinfo{
 			name:      key,
 			position:  currentIndex,
-			init:      inits,
+			init:      fieldInits,
 			isPointer: isPointer,
+			optional:  fieldsOptional,
+			typ:       field.Type,
+			def:       tagOpts.defaultValue(),
 		})
 	}
 
 	// If it has no exported field (such as time.Time) then we attempt to
 	// directly scan into it
 	if !hasExported {
-		*m = append(*m, mapinfo{
+		b.m = append(b.m, mapinfo{
 			name:      prefix,
 			position:  position,
 			init:      inits,
 			isPointer: isPointer,
+			optional:  optional,
+			typ:       fullType,
 		})
 	}
 }
 
-func filterColumns(ctx context.Context, c cols, m mapping, prefix string) (mapping, error) {
+// exposeField returns a settable version of the addressable field v.
+// This allows unexported fields to be scanned into
+func exposeField(v reflect.Value) reflect.Value {
+	if v.CanSet() {
+		return v
+	}
+
+	return reflect.NewAt(v.Type(), unsafe.Pointer(v.UnsafeAddr())).Elem()
+}
+
+// fieldOf is like [reflect.Value.FieldByIndex] but can also return unexported fields
+func fieldOf(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			v = v.Elem()
+		}
+		v = exposeField(v.Field(x))
+	}
+
+	return v
+}
+
+// fieldTag returns the tag of the field for the first struct tag key it has
+func (s *mapperSourceImpl) fieldTag(field reflect.StructField) string {
+	for _, key := range s.structTagKeys {
+		if tag, ok := field.Tag.Lookup(key); ok {
+			return tag
+		}
+	}
+
+	return ""
+}
+
+// tagOptions are the options after the name in a struct tag
+// e.g. `db:"name,opt1,opt2=value"`
+type tagOptions map[string]string
+
+func parseTag(tag string) (string, tagOptions) {
+	parts := strings.Split(tag, ",")
+	if len(parts) == 1 {
+		return parts[0], nil
+	}
+
+	opts := make(tagOptions, len(parts)-1)
+	for _, part := range parts[1:] {
+		key, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if key != "" {
+			opts[key] = val
+		}
+	}
+
+	return parts[0], opts
+}
+
+func (t tagOptions) has(opt string) bool {
+	_, ok := t[opt]
+	return ok
+}
+
+// defaultValue returns the value of the default option, or nil if there is none
+func (t tagOptions) defaultValue() *string {
+	def, ok := t["default"]
+	if !ok {
+		return nil
+	}
+
+	return &def
+}
+
+func filterColumns(ctx context.Context, c cols, m mapping, prefix string, match ColumnMatcher, normalize ColumnNormalizer) (mapping, error) {
 	// Filter the mapping so we only ask for the available columns
 	filtered := make(mapping, 0, len(c))
+	var matched map[int]bool
 	for _, name := range c {
 		key := name
+		if normalize != nil {
+			key = normalize(name)
+		}
+
 		if prefix != "" {
-			if !strings.HasPrefix(name, prefix) {
+			if !strings.HasPrefix(key, prefix) {
 				continue
 			}
 
-			key = name[len(prefix):]
+			key = key[len(prefix):]
+		}
+
+		i := matchColumn(key, m, match, matched)
+		if i < 0 {
+			continue
 		}
 
-		for _, info := range m {
-			if key == info.name {
-				info.name = name
-				filtered = append(filtered, info)
-				break
+		if match != nil {
+			if matched == nil {
+				matched = make(map[int]bool, len(m))
 			}
+			matched[i] = true
 		}
+
+		info := m[i]
+		info.name = name
+		filtered = append(filtered, info)
 	}
 
 	return filtered, nil
 }
+
+// matchColumn returns the index of the field in the mapping for the key.
+// An exact match is preferred, then the first field not yet matched that the matcher accepts
+func matchColumn(key string, m mapping, match ColumnMatcher, matched map[int]bool) int {
+	for i, info := range m {
+		if key == info.name {
+			return i
+		}
+	}
+
+	if match == nil {
+		return -1
+	}
+
+	for i, info := range m {
+		if !matched[i] && match(key, info.name) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// checkRecursions returns an error if a column is not in the mapping
+// because it belongs to a field that is deeper than the max depth of a recursive type.
+// If w is not nil, each such field is reported to it once instead
+func checkRecursions(c cols, m mapping, recursions []recursion, typ reflect.Type, isPointer bool, prefix string, normalize ColumnNormalizer, w warner) error {
+	if len(recursions) == 0 {
+		return nil
+	}
+
+	known := make(map[string]bool, len(m))
+	for _, info := range m {
+		// The remaining columns are not unknown
+		if info.remain {
+			return nil
+		}
+		known[info.name] = true
+	}
+
+	if isPointer {
+		typ = typ.Elem()
+	}
+
+	reported := make([]bool, len(recursions))
+	for _, name := range c {
+		key := name
+		if normalize != nil {
+			key = normalize(name)
+		}
+
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		key = key[len(prefix):]
+		if known[key] {
+			continue
+		}
+
+		for i, r := range recursions {
+			if !strings.HasPrefix(key, r.prefix) {
+				continue
+			}
+
+			path := typ.Name() + "." + fieldPaths(mapping{{position: r.position}}, typ, false)[0]
+			if w == nil {
+				err := fmt.Errorf("Column %q is deeper than the max depth %d of the recursive field %s", name, r.depth, path)
+				return columnError(ErrNoDestination, name, path, err, "max depth", name)
+			}
+
+			if !reported[i] {
+				reported[i] = true
+				w.warn(WarningTruncatedRecursion, name, path,
+					"Field %s is not mapped because it is deeper than the max depth %d", path, r.depth)
+			}
+			break
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+module github.com/stephenafamo/scan/sqlitescan
+
+go 1.18
+
+require (
+	github.com/aarondl/opt v0.0.0-20221129170750-3d40c96d9bb8
+	github.com/google/go-cmp v0.5.9
+	github.com/stephenafamo/scan v0.7.0
+	zombiezen.com/go/sqlite v0.8.0
+)
+
+require (
+	github.com/aarondl/json v0.0.0-20221020222930-8b0db17ef1bf // indirect
+	github.com/mattn/go-isatty v0.0.12 // indirect
+	github.com/remyoudompheng/bigfft v0.0.0-20200410134404-eec4a21b6bb0 // indirect
+	golang.org/x/sys v0.0.0-20220919091848-fb04ddd9f9c8 // indirect
+	golang.org/x/text v0.3.8 // indirect
+	modernc.org/libc v1.11.3 // indirect
+	modernc.org/mathutil v1.4.1 // indirect
+	modernc.org/memory v1.0.5 // indirect
+	modernc.org/sqlite v1.13.0 // indirect
+)
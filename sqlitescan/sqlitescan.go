@@ -0,0 +1,227 @@
+// Package sqlitescan scans the results of zombiezen.com/go/sqlite statements
+// directly, without going through database/sql
+package sqlitescan
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aarondl/opt"
+	"github.com/stephenafamo/scan"
+	"zombiezen.com/go/sqlite"
+)
+
+// One scans a single row from the query and maps it to T using a [*sqlite.Conn]
+func One[T any](ctx context.Context, conn *sqlite.Conn, m scan.Mapper[T], query string, args ...any) (T, error) {
+	return scan.One(ctx, Wrap(conn), m, query, args...)
+}
+
+// All scans all rows from the query and returns a slice []T of all rows using a [*sqlite.Conn]
+func All[T any](ctx context.Context, conn *sqlite.Conn, m scan.Mapper[T], query string, args ...any) ([]T, error) {
+	return scan.All(ctx, Wrap(conn), m, query, args...)
+}
+
+// Cursor returns a cursor that works similar to *sql.Rows
+func Cursor[T any](ctx context.Context, conn *sqlite.Conn, m scan.Mapper[T], query string, args ...any) (scan.ICursor[T], error) {
+	return scan.Cursor(ctx, Wrap(conn), m, query, args...)
+}
+
+// AllFromStmt scans all rows of a statement that has already been prepared and bound.
+// The statement is reset afterwards
+func AllFromStmt[T any](ctx context.Context, stmt *sqlite.Stmt, m scan.Mapper[T]) ([]T, error) {
+	rows := NewRows(stmt)
+	defer rows.Close()
+
+	return scan.AllFromRows(ctx, m, rows)
+}
+
+// Wrap converts a [*sqlite.Conn] into a [scan.Queryer]
+// to use it with the functions in the base scan package.
+//
+// Queries are prepared with [sqlite.Conn.Prepare], so the statements are cached by the connection.
+// A query should not be run again while the rows of a previous run are still open.
+// The query is interrupted if the context is done.
+func Wrap(conn *sqlite.Conn) scan.Queryer {
+	return queryer{conn: conn}
+}
+
+type queryer struct {
+	conn *sqlite.Conn
+}
+
+// QueryContext prepares the query and binds the args by position
+func (q queryer) QueryContext(ctx context.Context, query string, args ...any) (scan.Rows, error) {
+	stmt, err := q.conn.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, arg := range args {
+		if err := bind(stmt, i+1, arg); err != nil {
+			stmt.ClearBindings()
+			return nil, err
+		}
+	}
+
+	r := NewRows(stmt).(*rows)
+	r.conn = q.conn
+	r.prevInterrupt = q.conn.SetInterrupt(ctx.Done())
+
+	return r, nil
+}
+
+func bind(stmt *sqlite.Stmt, param int, arg any) error {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return err
+		}
+		arg = v
+	}
+
+	switch arg := arg.(type) {
+	case nil:
+		stmt.BindNull(param)
+	case int:
+		stmt.BindInt64(param, int64(arg))
+	case int8:
+		stmt.BindInt64(param, int64(arg))
+	case int16:
+		stmt.BindInt64(param, int64(arg))
+	case int32:
+		stmt.BindInt64(param, int64(arg))
+	case int64:
+		stmt.BindInt64(param, arg)
+	case uint:
+		return bindUint64(stmt, param, uint64(arg))
+	case uint8:
+		stmt.BindInt64(param, int64(arg))
+	case uint16:
+		stmt.BindInt64(param, int64(arg))
+	case uint32:
+		stmt.BindInt64(param, int64(arg))
+	case uint64:
+		return bindUint64(stmt, param, arg)
+	case float32:
+		stmt.BindFloat(param, float64(arg))
+	case float64:
+		stmt.BindFloat(param, arg)
+	case bool:
+		stmt.BindBool(param, arg)
+	case string:
+		stmt.BindText(param, arg)
+	case []byte:
+		stmt.BindBytes(param, arg)
+	case time.Time:
+		stmt.BindText(param, arg.Format(time.RFC3339Nano))
+	default:
+		return fmt.Errorf("sqlitescan: cannot bind arg %d of type %T", param, arg)
+	}
+
+	return nil
+}
+
+// bindUint64 binds the value as an integer, which sqlite stores as a signed 64 bit integer
+func bindUint64(stmt *sqlite.Stmt, param int, arg uint64) error {
+	if arg > math.MaxInt64 {
+		return fmt.Errorf("sqlitescan: cannot bind arg %d: %d overflows int64", param, arg)
+	}
+
+	stmt.BindInt64(param, int64(arg))
+	return nil
+}
+
+// NewRows converts a prepared and bound statement into [scan.Rows].
+// Closing the rows resets the statement so it can be used again
+func NewRows(stmt *sqlite.Stmt) scan.Rows {
+	return &rows{stmt: stmt}
+}
+
+type rows struct {
+	stmt *sqlite.Stmt
+	err  error
+	done bool
+
+	// set if the rows were created by a query, to restore the interrupt on close
+	conn          *sqlite.Conn
+	prevInterrupt <-chan struct{}
+}
+
+func (r *rows) Columns() ([]string, error) {
+	cols := make([]string, r.stmt.ColumnCount())
+	for i := range cols {
+		cols[i] = r.stmt.ColumnName(i)
+	}
+
+	return cols, nil
+}
+
+func (r *rows) Next() bool {
+	if r.done {
+		return false
+	}
+
+	hasRow, err := r.stmt.Step()
+	if err != nil || !hasRow {
+		r.err = err
+		r.done = true
+		return false
+	}
+
+	return true
+}
+
+// Scan converts the value of each column to the type of its destination
+// in the same way as database/sql
+func (r *rows) Scan(dest ...any) error {
+	if len(dest) != r.stmt.ColumnCount() {
+		return fmt.Errorf("sqlitescan: expected %d destinations, got %d", r.stmt.ColumnCount(), len(dest))
+	}
+
+	for i, d := range dest {
+		if err := opt.ConvertAssign(d, r.value(i)); err != nil {
+			return fmt.Errorf("sqlitescan: column index %d, name %q: %w", i, r.stmt.ColumnName(i), err)
+		}
+	}
+
+	return nil
+}
+
+// value returns the value of the column as one of the types used by database/sql drivers
+func (r *rows) value(col int) any {
+	switch r.stmt.ColumnType(col) {
+	case sqlite.TypeInteger:
+		return r.stmt.ColumnInt64(col)
+	case sqlite.TypeFloat:
+		return r.stmt.ColumnFloat(col)
+	case sqlite.TypeText:
+		return r.stmt.ColumnText(col)
+	case sqlite.TypeBlob:
+		buf := make([]byte, r.stmt.ColumnLen(col))
+		r.stmt.ColumnBytes(col, buf)
+		return buf
+	default:
+		return nil
+	}
+}
+
+func (r *rows) Err() error {
+	return r.err
+}
+
+func (r *rows) Close() error {
+	if r.conn != nil {
+		r.conn.SetInterrupt(r.prevInterrupt)
+		r.conn = nil
+	}
+
+	r.done = true
+	if err := r.stmt.Reset(); err != nil {
+		return err
+	}
+
+	return r.stmt.ClearBindings()
+}
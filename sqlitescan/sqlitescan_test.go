@@ -0,0 +1,139 @@
+package sqlitescan
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stephenafamo/scan"
+	"zombiezen.com/go/sqlite"
+)
+
+type user struct {
+	ID    int
+	Name  string
+	Email *string
+	Data  []byte
+}
+
+func openConn(t *testing.T) *sqlite.Conn {
+	t.Helper()
+
+	conn, err := sqlite.OpenConn(":memory:", 0)
+	if err != nil {
+		t.Fatalf("opening connection: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	for _, q := range []string{
+		`CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT, email TEXT, data BLOB)`,
+		`INSERT INTO users VALUES (1, 'foo', 'foo@example.com', x'0102'), (2, 'bar', NULL, NULL)`,
+	} {
+		stmt, _, err := conn.PrepareTransient(q)
+		if err != nil {
+			t.Fatalf("preparing %q: %v", q, err)
+		}
+		if _, err := stmt.Step(); err != nil {
+			t.Fatalf("running %q: %v", q, err)
+		}
+		stmt.Finalize()
+	}
+
+	return conn
+}
+
+func TestAll(t *testing.T) {
+	ctx := context.Background()
+	conn := openConn(t)
+
+	users, err := All(ctx, conn, scan.StructMapper[user](), `SELECT id, name, email, data FROM users WHERE id > ? ORDER BY id`, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	email := "foo@example.com"
+	expected := []user{
+		{ID: 1, Name: "foo", Email: &email, Data: []byte{1, 2}},
+		{ID: 2, Name: "bar"},
+	}
+	if diff := cmp.Diff(expected, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// The cached statement can be used again
+	name, err := One(ctx, conn, scan.SingleColumnMapper[string], `SELECT name FROM users WHERE id = ?`, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "bar" {
+		t.Fatalf("wrong name: %s", name)
+	}
+
+	name, err = One(ctx, conn, scan.SingleColumnMapper[string], `SELECT name FROM users WHERE id = ?`, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "foo" {
+		t.Fatalf("wrong name: %s", name)
+	}
+}
+
+func TestAllFromStmt(t *testing.T) {
+	conn := openConn(t)
+
+	stmt := conn.Prep(`SELECT id FROM users ORDER BY id`)
+	ids, err := AllFromStmt(context.Background(), stmt, scan.SingleColumnMapper[int])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1, 2}, ids); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestBindError(t *testing.T) {
+	conn := openConn(t)
+
+	_, err := All(context.Background(), conn, scan.SingleColumnMapper[int], `SELECT id FROM users WHERE id = ?`, struct{}{})
+	if err == nil {
+		t.Fatal("expected an error for an unsupported arg")
+	}
+
+	_, err = All(context.Background(), conn, scan.SingleColumnMapper[int], `SELECT id FROM users WHERE id = ?`, uint64(math.MaxUint64))
+	if err == nil {
+		t.Fatal("expected an error for an unsigned arg that overflows int64")
+	}
+}
+
+func TestBindUnsigned(t *testing.T) {
+	conn := openConn(t)
+
+	for _, arg := range []any{uint(2), uint8(2), uint16(2), uint32(2), uint64(2)} {
+		name, err := One(context.Background(), conn, scan.SingleColumnMapper[string], `SELECT name FROM users WHERE id = ?`, arg)
+		if err != nil {
+			t.Fatalf("%T: unexpected error: %v", arg, err)
+		}
+
+		if name != "bar" {
+			t.Fatalf("%T: wrong name: %s", arg, name)
+		}
+	}
+}
+
+func TestScanError(t *testing.T) {
+	conn := openConn(t)
+
+	_, err := All(context.Background(), conn, scan.StructMapper[user](), `SELECT name AS id FROM users WHERE id = 1`)
+
+	var scanErr *scan.Error
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("expected a *scan.Error, got %T: %v", err, err)
+	}
+
+	if scanErr.Column() != "id" {
+		t.Fatalf("expected column %q, got %q", "id", scanErr.Column())
+	}
+}
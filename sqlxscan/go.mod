@@ -0,0 +1,16 @@
+module github.com/stephenafamo/scan/sqlxscan
+
+go 1.18
+
+require (
+	github.com/google/go-cmp v0.5.9
+	github.com/jmoiron/sqlx v1.3.5
+	github.com/stephenafamo/fakedb v0.0.0-20221230081958-0b86f816ed97
+	github.com/stephenafamo/scan v0.7.0
+)
+
+require (
+	github.com/aarondl/json v0.0.0-20221020222930-8b0db17ef1bf // indirect
+	github.com/aarondl/opt v0.0.0-20221129170750-3d40c96d9bb8 // indirect
+	golang.org/x/text v0.3.8 // indirect
+)
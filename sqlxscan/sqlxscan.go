@@ -0,0 +1,89 @@
+// Package sqlxscan makes it possible to use the mappers of the scan package
+// with github.com/jmoiron/sqlx, for *sqlx.DB, *sqlx.Tx and *sqlx.Conn.
+//
+// This helps to migrate from sqlx gradually, keeping the existing connections
+// and named queries. Note that sqlx maps untagged fields to lowercase column names by default,
+// while [scan.StructMapper] uses snake_case. Use [scan.WithFieldNameMapper] with [strings.ToLower]
+// to keep the sqlx behaviour
+package sqlxscan
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stephenafamo/scan"
+	"github.com/stephenafamo/scan/stdscan"
+)
+
+// A Queryer such as *sqlx.DB, *sqlx.Tx or *sqlx.Conn
+type Queryer interface {
+	sqlx.QueryerContext
+}
+
+// A NamedQueryer can bind named parameters for its driver, such as *sqlx.DB or *sqlx.Tx
+type NamedQueryer interface {
+	Queryer
+	BindNamed(query string, arg any) (string, []any, error)
+}
+
+// One scans a single row from the query and maps it to T. See [scan.One]
+func One[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (T, error) {
+	return scan.One(ctx, convert(exec), m, sql, args...)
+}
+
+// All scans all rows from the query and returns a slice []T of all rows. See [scan.All]
+func All[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) ([]T, error) {
+	return scan.All(ctx, convert(exec), m, sql, args...)
+}
+
+// Cursor returns a cursor that works similar to *sqlx.Rows
+func Cursor[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (scan.ICursor[T], error) {
+	return scan.Cursor(ctx, convert(exec), m, sql, args...)
+}
+
+// NamedOne is like [One] for a query with named parameters such as :id,
+// which are bound from arg, a struct or a map, in the same way as sqlx.NamedQueryContext
+//
+//	user, err := sqlxscan.NamedOne(ctx, db, scan.StructMapper[User](),
+//	    `SELECT * FROM users WHERE id = :id`, map[string]any{"id": 1})
+func NamedOne[T any](ctx context.Context, exec NamedQueryer, m scan.Mapper[T], sql string, arg any) (T, error) {
+	query, args, err := exec.BindNamed(sql, arg)
+	if err != nil {
+		var t T
+		return t, err
+	}
+
+	return scan.One(ctx, convert(exec), m, query, args...)
+}
+
+// NamedAll is like [All] for a query with named parameters. See [NamedOne]
+func NamedAll[T any](ctx context.Context, exec NamedQueryer, m scan.Mapper[T], sql string, arg any) ([]T, error) {
+	query, args, err := exec.BindNamed(sql, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return scan.All(ctx, convert(exec), m, query, args...)
+}
+
+// NamedCursor is like [Cursor] for a query with named parameters. See [NamedOne]
+func NamedCursor[T any](ctx context.Context, exec NamedQueryer, m scan.Mapper[T], sql string, arg any) (scan.ICursor[T], error) {
+	query, args, err := exec.BindNamed(sql, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return scan.Cursor(ctx, convert(exec), m, query, args...)
+}
+
+// Wrap converts a [Queryer] such as *sqlx.DB into a [scan.Queryer]
+// to use it with the functions in the base scan package
+func Wrap(exec Queryer) scan.Queryer {
+	return convert(exec)
+}
+
+// convert uses the wrapper of stdscan, since the sqlx types return *sql.Rows
+// and can also prepare statements
+func convert(exec Queryer) scan.Queryer {
+	return stdscan.Wrap(exec)
+}
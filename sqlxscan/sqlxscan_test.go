@@ -0,0 +1,65 @@
+package sqlxscan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/stephenafamo/fakedb"
+	"github.com/stephenafamo/scan"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+func TestSqlx(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := sqlx.Open("test", "sqlxscan")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer db.Close()
+
+	db.MustExec("CREATE|users|id=int64,name=string")
+	defer db.MustExec("DROP|users")
+
+	db.MustExec("INSERT|users|id=?,name=?", 1, "foo")
+	db.MustExec("INSERT|users|id=?,name=?", 2, "bar")
+
+	users, err := All(ctx, db, scan.StructMapper[user](), "SELECT|users|id,name|")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]user{{ID: 1, Name: "foo"}, {ID: 2, Name: "bar"}}, users); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	named, err := NamedOne(ctx, db, scan.StructMapper[user](), "SELECT|users|id,name|name=:name", map[string]any{"name": "bar"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(user{ID: 2, Name: "bar"}, named); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tx.Rollback()
+
+	ids, err := NamedAll(ctx, tx, scan.ColumnMapper[int64]("id"), "SELECT|users|id|name=:name", user{Name: "foo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int64{1}, ids); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
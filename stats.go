@@ -0,0 +1,164 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLatencyBuckets are the upper bounds in seconds of the latency histograms of [NewQueryStats]
+var DefaultLatencyBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// DefaultRowBuckets are the upper bounds of the row count histograms of [NewQueryStats]
+var DefaultRowBuckets = []float64{0, 1, 10, 100, 1000, 10000, 100000}
+
+// QueryStats records the latency and number of rows of queries, keyed by the type
+// the rows are mapped to, so it is easy to see which models drive the load on the database.
+// Add it to a context with [WithQueryStats]. It is safe for concurrent use
+type QueryStats struct {
+	latencyBuckets []float64
+	rowBuckets     []float64
+
+	mu    sync.Mutex
+	types map[reflect.Type]*TypeStats
+}
+
+// TypeStats are the stats of the queries for a single type
+type TypeStats struct {
+	// Type is the type the rows are mapped to, e.g. *models.User
+	Type string
+	// Queries is the number of queries
+	Queries uint64
+	// Errors is the number of queries that returned an error
+	Errors uint64
+	// Latency is the time from sending the query to closing the rows, in seconds
+	Latency Histogram
+	// Rows is the number of rows scanned by each query
+	Rows Histogram
+}
+
+// Histogram counts observations in buckets, in the same way as Prometheus histograms
+type Histogram struct {
+	// Bounds are the upper bounds of the buckets, in increasing order
+	Bounds []float64
+	// Counts are the number of observations in each bucket, with an extra bucket for
+	// observations greater than the last bound. They are not cumulative
+	Counts []uint64
+	// Count is the number of observations
+	Count uint64
+	// Sum is the sum of the observations
+	Sum float64
+}
+
+func newHistogram(bounds []float64) Histogram {
+	return Histogram{
+		Bounds: bounds,
+		Counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+func (h *Histogram) observe(v float64) {
+	i := sort.SearchFloat64s(h.Bounds, v)
+	h.Counts[i]++
+	h.Count++
+	h.Sum += v
+}
+
+func (h Histogram) copy() Histogram {
+	h.Counts = append([]uint64(nil), h.Counts...)
+	return h
+}
+
+// NewQueryStats returns an empty [QueryStats] with the default buckets,
+// see [DefaultLatencyBuckets] and [DefaultRowBuckets]
+func NewQueryStats() *QueryStats {
+	return NewQueryStatsWithBuckets(DefaultLatencyBuckets, DefaultRowBuckets)
+}
+
+// NewQueryStatsWithBuckets returns an empty [QueryStats] with the given bucket bounds,
+// which must be in increasing order. Latency bounds are in seconds
+func NewQueryStatsWithBuckets(latency, rows []float64) *QueryStats {
+	return &QueryStats{
+		latencyBuckets: latency,
+		rowBuckets:     rows,
+		types:          make(map[reflect.Type]*TypeStats),
+	}
+}
+
+// ctxKeyQueryStats holds the [QueryStats] for the queries run with the context
+var ctxKeyQueryStats contextKey = "query stats"
+
+// WithQueryStats returns a context that records every query run with it
+// by [One], [All], [Cursor] and the other query functions in the stats.
+// The stats are recorded by a [Hook] that is called before the hooks of the [Queryer].
+// Queries run with [Many] are not recorded since they map several types
+func WithQueryStats(ctx context.Context, s *QueryStats) context.Context {
+	return context.WithValue(ctx, ctxKeyQueryStats, s)
+}
+
+// statsHook records the queries of a single type in the [QueryStats]
+type statsHook struct {
+	stats *QueryStats
+	typ   reflect.Type
+}
+
+func (h statsHook) Handle(ctx context.Context, e Event) context.Context {
+	if end, ok := e.(ScanEnd); ok {
+		h.stats.record(h.typ, end.Duration, end.Rows, end.Err)
+	}
+
+	return ctx
+}
+
+// Snapshot returns a copy of the stats of each type, sorted by type name
+func (s *QueryStats) Snapshot() []TypeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := make([]TypeStats, 0, len(s.types))
+	for _, t := range s.types {
+		c := *t
+		c.Latency = t.Latency.copy()
+		c.Rows = t.Rows.copy()
+		stats = append(stats, c)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Type < stats[j].Type
+	})
+
+	return stats
+}
+
+// Reset removes all recorded stats
+func (s *QueryStats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.types = make(map[reflect.Type]*TypeStats)
+}
+
+func (s *QueryStats) record(typ reflect.Type, latency time.Duration, rows int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.types[typ]
+	if !ok {
+		t = &TypeStats{
+			Type:    typ.String(),
+			Latency: newHistogram(s.latencyBuckets),
+			Rows:    newHistogram(s.rowBuckets),
+		}
+		s.types[typ] = t
+	}
+
+	t.Queries++
+	if err != nil {
+		t.Errors++
+	}
+
+	t.Latency.observe(latency.Seconds())
+	t.Rows.observe(float64(rows))
+}
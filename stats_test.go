@@ -0,0 +1,68 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestQueryStats(t *testing.T) {
+	stats := NewQueryStatsWithBuckets([]float64{60}, []float64{0, 1, 10})
+	ctx := WithQueryStats(context.Background(), stats)
+
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		if query == "fail" {
+			return nil, errors.New("failed")
+		}
+		return newSliceRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"}), nil
+	})
+
+	if _, err := All(ctx, exec, StructMapper[User](), "all"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := One(ctx, exec, StructMapper[*User](), "one"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := All(ctx, exec, StructMapper[User](), "fail"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// Queries without the stats are not recorded
+	if _, err := All(context.Background(), exec, StructMapper[User](), "all"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snapshot := stats.Snapshot()
+	for i := range snapshot {
+		// The latency depends on the machine
+		snapshot[i].Latency.Sum = 0
+	}
+
+	expected := []TypeStats{
+		{
+			Type:    "*scan.User",
+			Queries: 1,
+			Latency: Histogram{Bounds: []float64{60}, Counts: []uint64{1, 0}, Count: 1},
+			Rows:    Histogram{Bounds: []float64{0, 1, 10}, Counts: []uint64{0, 1, 0, 0}, Count: 1, Sum: 1},
+		},
+		{
+			Type:    "scan.User",
+			Queries: 2,
+			Errors:  1,
+			Latency: Histogram{Bounds: []float64{60}, Counts: []uint64{2, 0}, Count: 2},
+			Rows:    Histogram{Bounds: []float64{0, 1, 10}, Counts: []uint64{1, 0, 1, 0}, Count: 2, Sum: 2},
+		},
+	}
+	if diff := cmp.Diff(expected, snapshot); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	stats.Reset()
+	if len(stats.Snapshot()) != 0 {
+		t.Fatal("expected no stats after a reset")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build go1.23
+
+package stdscan
+
+import (
+	"context"
+	"iter"
+
+	"github.com/stephenafamo/scan"
+)
+
+// Seq returns an iterator over the rows from the query. See [scan.Seq]
+func Seq[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) iter.Seq2[T, error] {
+	return scan.Seq(ctx, convert(exec), m, sql, args...)
+}
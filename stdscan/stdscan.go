@@ -14,24 +14,200 @@ func One[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string,
 	return scan.One(ctx, convert(exec), m, sql, args...)
 }
 
+// OneOrZero is like [One], but returns false instead of [scan.ErrNoRows] if the query returns no rows
+func OneOrZero[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (T, bool, error) {
+	return scan.OneOrZero(ctx, convert(exec), m, sql, args...)
+}
+
+// First scans the first row from the query and maps it to T, ignoring the other rows
+func First[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (T, error) {
+	return scan.First(ctx, convert(exec), m, sql, args...)
+}
+
+// FanOut runs the query concurrently on each of the Queryers, e.g. the shards of a database,
+// and returns the rows of all of them. See [scan.FanOut]
+func FanOut[T any](ctx context.Context, execs []Queryer, m scan.Mapper[T], sql string, args ...any) ([]T, error) {
+	converted := make([]scan.Queryer, len(execs))
+	for i, exec := range execs {
+		converted[i] = convert(exec)
+	}
+
+	return scan.FanOut(ctx, converted, m, sql, args...)
+}
+
+// Count runs a query that returns a single number, such as SELECT COUNT(*). See [scan.Count]
+func Count(ctx context.Context, exec Queryer, sql string, args ...any) (int64, error) {
+	return scan.Count(ctx, convert(exec), sql, args...)
+}
+
+// Exists runs a query that returns a single boolean, such as SELECT EXISTS (...). See [scan.Exists]
+func Exists(ctx context.Context, exec Queryer, sql string, args ...any) (bool, error) {
+	return scan.Exists(ctx, convert(exec), sql, args...)
+}
+
+// ExactlyOne is like [One], but returns [scan.ErrTooManyRows] if the query returns more than one row
+func ExactlyOne[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (T, error) {
+	return scan.ExactlyOne(ctx, convert(exec), m, sql, args...)
+}
+
 // All scans all rows from the query and returns a slice []T of all rows using a [StdQueryer] this is for use with *sql.DB, *sql.Tx or *sql.Conn or any similar implementations
 // that return *sql.Rows
 func All[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) ([]T, error) {
 	return scan.All(ctx, convert(exec), m, sql, args...)
 }
 
+// AllIndexed scans all rows from the query and returns them in a map keyed by keyFn
+func AllIndexed[K comparable, T any](ctx context.Context, exec Queryer, m scan.Mapper[T], keyFn func(T) K, sql string, args ...any) (map[K]T, error) {
+	return scan.AllIndexed(ctx, convert(exec), m, keyFn, sql, args...)
+}
+
+// AllWhile scans the rows from the query while keep returns true. See [scan.AllWhile]
+func AllWhile[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], keep func(T) bool, sql string, args ...any) ([]T, error) {
+	return scan.AllWhile(ctx, convert(exec), m, keep, sql, args...)
+}
+
+// Stream sends the rows from the query on a channel as they are read. See [scan.Stream]
+func Stream[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (<-chan T, <-chan error) {
+	return scan.Stream(ctx, convert(exec), m, sql, args...)
+}
+
+// Chunks runs the query and calls fn with the rows in batches of size. See [scan.Chunks]
+func Chunks[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], size int, fn func([]T) error, sql string, args ...any) error {
+	return scan.Chunks(ctx, convert(exec), m, size, fn, sql, args...)
+}
+
+// Grouped folds joined rows into parents with their children using [scan.Group]
+func Grouped[P any, C any, K comparable](ctx context.Context, exec Queryer, g scan.Group[P, C, K], sql string, args ...any) ([]P, error) {
+	return scan.Grouped(ctx, convert(exec), g, sql, args...)
+}
+
+// Faceted splits the data rows and facet count rows of the query using [scan.FacetColumns]
+func Faceted[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], fc scan.FacetColumns, sql string, args ...any) ([]T, scan.FacetCounts, error) {
+	return scan.Faceted(ctx, convert(exec), m, fc, sql, args...)
+}
+
+// Many maps each result set of a query with its own [scan.ResultSet] in order.
+// Use it for stored procedures and batches that return multiple result sets
+func Many(ctx context.Context, exec Queryer, sets []scan.ResultSet, sql string, args ...any) error {
+	return scan.Many(ctx, convert(exec), sets, sql, args...)
+}
+
+// OneWithOptions is like [One] but applies the [scan.MappingOption]s
+// to the struct mapper for only this query
+func OneWithOptions[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], opts []scan.MappingOption, sql string, args ...any) (T, error) {
+	return scan.OneWithOptions(ctx, convert(exec), m, opts, sql, args...)
+}
+
+// AllWithOptions is like [All] but applies the [scan.MappingOption]s
+// to the struct mapper for only this query
+func AllWithOptions[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], opts []scan.MappingOption, sql string, args ...any) ([]T, error) {
+	return scan.AllWithOptions(ctx, convert(exec), m, opts, sql, args...)
+}
+
+// Collect runs the query and collects several values from each row. See [scan.Collect]
+func Collect(ctx context.Context, exec Queryer, collector func(context.Context, []string) any, sql string, args ...any) ([]any, error) {
+	return scan.Collect(ctx, convert(exec), collector, sql, args...)
+}
+
+// CollectOf runs the query and returns the value extracted from each row by the [scan.Collector]
+func CollectOf[T any](ctx context.Context, exec Queryer, c scan.Collector[T], sql string, args ...any) ([]T, error) {
+	return scan.CollectOf(ctx, convert(exec), c, sql, args...)
+}
+
+// Collect2 runs the query and returns the two values extracted from each row. See [scan.Collect2]
+func Collect2[A, B any](ctx context.Context, exec Queryer, c scan.Collector2[A, B], sql string, args ...any) ([]A, []B, error) {
+	return scan.Collect2(ctx, convert(exec), c, sql, args...)
+}
+
+// Collect3 runs the query and returns the three values extracted from each row. See [scan.Collect3]
+func Collect3[A, B, C any](ctx context.Context, exec Queryer, c scan.Collector3[A, B, C], sql string, args ...any) ([]A, []B, []C, error) {
+	return scan.Collect3(ctx, convert(exec), c, sql, args...)
+}
+
+// NamedOne is like [One] for a query with :name parameters, which are bound from arg,
+// a map or a struct. The parameters are rewritten into the placeholders of the dialect
+// of the driver, e.g. [scan.Postgres] for lib/pq and pgx. See [scan.Named]
+func NamedOne[T any](ctx context.Context, exec Queryer, d scan.Dialect, m scan.Mapper[T], sql string, arg any) (T, error) {
+	query, args, err := scan.Named(d, sql, arg)
+	if err != nil {
+		var t T
+		return t, err
+	}
+
+	return scan.One(ctx, convert(exec), m, query, args...)
+}
+
+// NamedAll is like [All] for a query with :name parameters. See [NamedOne]
+func NamedAll[T any](ctx context.Context, exec Queryer, d scan.Dialect, m scan.Mapper[T], sql string, arg any) ([]T, error) {
+	query, args, err := scan.Named(d, sql, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	return scan.All(ctx, convert(exec), m, query, args...)
+}
+
 // Cursor returns a cursor that works similar to *sql.Rows
 func Cursor[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (scan.ICursor[T], error) {
 	return scan.Cursor(ctx, convert(exec), m, sql, args...)
 }
 
+// Prepare prepares the query and returns a [scan.PreparedQuery] that reuses
+// the statement and the column mapping for repeated executions.
+// If exec is a [Preparer] such as *sql.DB, *sql.Tx or *sql.Conn, the statement is prepared on it
+func Prepare[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string) (*scan.PreparedQuery[T], error) {
+	return scan.Prepare(ctx, convert(exec), m, sql)
+}
+
+// Exec executes a statement that does not return rows, typically an INSERT, UPDATE or DELETE
+// this is for use with *sql.DB, *sql.Tx or *sql.Conn or any similar implementations
+func Exec(ctx context.Context, exec Executor, sql string, args ...any) (sql.Result, error) {
+	return exec.ExecContext(ctx, sql, args...)
+}
+
+// ExecReturningOne executes a statement with a RETURNING clause
+// and maps the single returned row to T
+func ExecReturningOne[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) (T, error) {
+	return scan.One(ctx, convert(exec), m, sql, args...)
+}
+
+// ExecReturningAll executes a statement with a RETURNING clause
+// and maps all the returned rows to a slice []T
+func ExecReturningAll[T any](ctx context.Context, exec Queryer, m scan.Mapper[T], sql string, args ...any) ([]T, error) {
+	return scan.All(ctx, convert(exec), m, sql, args...)
+}
+
+// An Executor runs statements that do not return rows
+// such as *sql.DB, *sql.Tx or *sql.Conn
+type Executor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
 // A Queryer that returns the concrete type [*sql.Rows]
 type Queryer interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 }
 
-// convert wraps an Queryer and makes it a Queryer
+// A Preparer is a [Queryer] that can prepare statements
+// such as *sql.DB, *sql.Tx or *sql.Conn
+type Preparer interface {
+	Queryer
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// Wrap converts a [Queryer] such as *sql.DB into a [scan.Queryer]
+// to use it with the functions in the base scan package
+func Wrap(exec Queryer) scan.Queryer {
+	return convert(exec)
+}
+
+// convert wraps an Queryer and makes it a Queryer.
+// If it can prepare statements, the returned Queryer is also a [scan.Preparer]
 func convert(wrapped Queryer) scan.Queryer {
+	if p, ok := wrapped.(Preparer); ok {
+		return preparer{queryer: queryer{wrapped: wrapped}, p: p}
+	}
+
 	return queryer{wrapped: wrapped}
 }
 
@@ -43,3 +219,89 @@ type queryer struct {
 func (q queryer) QueryContext(ctx context.Context, query string, args ...any) (scan.Rows, error) {
 	return q.wrapped.QueryContext(ctx, query, args...)
 }
+
+// WithDialect wraps a [Queryer] so that the ? placeholders of every query
+// are rewritten into the placeholders of the dialect with [scan.Rebind],
+// e.g. to run the same query text on Postgres and MySQL.
+// If exec can prepare statements, the returned Queryer can too
+//
+//	db := stdscan.WithDialect(sqlDB, scan.Postgres)
+//	clause, args, err := scan.Where(filter)
+//	users, err := stdscan.All(ctx, db, scan.StructMapper[User](), "SELECT * FROM users "+clause, args...)
+func WithDialect(exec Queryer, d scan.Dialect) Queryer {
+	q := dialectQueryer{wrapped: exec, d: d}
+	if p, ok := exec.(Preparer); ok {
+		return dialectPreparer{dialectQueryer: q, p: p}
+	}
+
+	return q
+}
+
+type dialectQueryer struct {
+	wrapped Queryer
+	d       scan.Dialect
+}
+
+// QueryContext rewrites the placeholders of the query and runs it
+func (q dialectQueryer) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return q.wrapped.QueryContext(ctx, scan.Rebind(q.d, query), args...)
+}
+
+type dialectPreparer struct {
+	dialectQueryer
+	p Preparer
+}
+
+// PrepareContext rewrites the placeholders of the query and prepares it
+func (p dialectPreparer) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return p.p.PrepareContext(ctx, scan.Rebind(p.d, query))
+}
+
+// Allowlist wraps a [Queryer] so that only queries in the registry can be run.
+// Every other query returns an error wrapping [scan.ErrQueryNotAllowed]
+func Allowlist(exec Queryer, r *scan.QueryRegistry) Queryer {
+	return allowlistQueryer{wrapped: exec, r: r}
+}
+
+type allowlistQueryer struct {
+	wrapped Queryer
+	r       *scan.QueryRegistry
+}
+
+// QueryContext runs the query only if it is in the registry
+func (a allowlistQueryer) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := a.r.Check(query); err != nil {
+		return nil, err
+	}
+
+	return a.wrapped.QueryContext(ctx, query, args...)
+}
+
+type preparer struct {
+	queryer
+	p Preparer
+}
+
+// PrepareContext prepares a statement that returns *sql.Rows
+func (p preparer) PrepareContext(ctx context.Context, query string) (scan.Stmt, error) {
+	stmt, err := p.p.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return preparedStmt{stmt: stmt}, nil
+}
+
+type preparedStmt struct {
+	stmt *sql.Stmt
+}
+
+// QueryContext runs the prepared statement with the args
+func (s preparedStmt) QueryContext(ctx context.Context, args ...any) (scan.Rows, error) {
+	return s.stmt.QueryContext(ctx, args...)
+}
+
+// Close closes the statement
+func (s preparedStmt) Close() error {
+	return s.stmt.Close()
+}
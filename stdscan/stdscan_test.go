@@ -0,0 +1,141 @@
+package stdscan
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	_ "github.com/stephenafamo/fakedb"
+	"github.com/stephenafamo/scan"
+)
+
+type user struct {
+	ID   int64
+	Name string
+}
+
+// openDB opens a fakedb database with a users table
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := sql.Open("test", t.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	// The fakedb databases are kept by name, so they are wiped for repeated runs
+	if _, err := Exec(context.Background(), db, "WIPE"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := Exec(context.Background(), db, "CREATE|users|id=int64,name=string"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return db
+}
+
+func TestExec(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	for i, name := range []string{"bob", "alice"} {
+		res, err := Exec(ctx, db, "INSERT|users|id=?,name=?", i+1, name)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if n, err := res.RowsAffected(); err != nil || n != 1 {
+			t.Fatalf("expected 1 row affected, got %d (%v)", n, err)
+		}
+	}
+
+	one, err := ExecReturningOne(ctx, db, scan.StructMapper[user](), "SELECT|users|id,name|id=?", 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff(user{ID: 2, Name: "alice"}, one); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	all, err := ExecReturningAll(ctx, db, scan.StructMapper[user](), "SELECT|users|id,name|")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]user{{ID: 1, Name: "bob"}, {ID: 2, Name: "alice"}}, all); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
+
+func TestQueryers(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	if _, err := Exec(ctx, db, "INSERT|users|id=?,name=?", 1, "bob"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tx.Rollback()
+
+	// The queryers are converted into a scan.Queryer, and into a scan.Preparer when they can prepare
+	for name, exec := range map[string]Queryer{"db": db, "tx": tx} {
+		if _, ok := convert(exec).(scan.Preparer); !ok {
+			t.Fatalf("%s: expected the converted queryer to prepare statements", name)
+		}
+
+		got, err := One(ctx, exec, scan.StructMapper[user](), "SELECT|users|id,name|id=?", 1)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		if diff := cmp.Diff(user{ID: 1, Name: "bob"}, got); diff != "" {
+			t.Fatalf("%s: diff: %s", name, diff)
+		}
+
+		p, err := Prepare(ctx, exec, scan.SingleColumnMapper[string], "SELECT|users|name|id=?")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		names, err := p.All(ctx, 1)
+		p.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		if diff := cmp.Diff([]string{"bob"}, names); diff != "" {
+			t.Fatalf("%s: diff: %s", name, diff)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+
+	if _, err := Exec(ctx, db, "INSERT|users|id=?,name=?", 1, "bob"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exec := Wrap(db)
+	if _, ok := exec.(scan.Preparer); !ok {
+		t.Fatal("expected the wrapped database to prepare statements")
+	}
+
+	got, err := scan.All(ctx, exec, scan.StructMapper[user](), "SELECT|users|id,name|")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]user{{ID: 1, Name: "bob"}}, got); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+}
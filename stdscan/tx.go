@@ -0,0 +1,62 @@
+package stdscan
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// A Beginner can begin transactions, such as *sql.DB or *sql.Conn
+type Beginner interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Tx runs fn in a transaction. fn gets the *sql.Tx, which is a [Queryer], an [Executor]
+// and a [Preparer], so that [One], [All], [Exec] and the others run in the transaction.
+//
+// The transaction is committed if fn returns nil, and rolled back if fn
+// returns an error or panics, in which case the panic continues after the rollback.
+//
+//	err := stdscan.Tx(ctx, db, func(tx *sql.Tx) error {
+//	    user, err := stdscan.One(ctx, tx, scan.StructMapper[User](), "SELECT * FROM users WHERE id = $1 FOR UPDATE", id)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    _, err = stdscan.Exec(ctx, tx, "UPDATE users SET age = $1 WHERE id = $2", user.Age+1, id)
+//	    return err
+//	})
+func Tx(ctx context.Context, db Beginner, fn func(tx *sql.Tx) error) error {
+	return TxOptions(ctx, db, nil, fn)
+}
+
+// TxOptions is like [Tx] but begins the transaction with the options,
+// e.g. to set the isolation level or make it read only
+func TxOptions(ctx context.Context, db Beginner, opts *sql.TxOptions, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+
+		// Rolled back on errors and panics. A panic is not recovered
+		if rbErr := tx.Rollback(); rbErr != nil && err != nil {
+			err = fmt.Errorf("%w (rollback: %v)", err, rbErr)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	committed = true
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return nil
+}
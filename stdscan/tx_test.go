@@ -0,0 +1,195 @@
+package stdscan
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	_ "github.com/stephenafamo/fakedb"
+	"github.com/stephenafamo/scan"
+)
+
+// txConnector opens connections of the fakedb driver that record how
+// their transactions end, and can make them fail
+type txConnector struct {
+	base        driver.Driver
+	dsn         string
+	commitErr   error
+	rollbackErr error
+	ended       []string
+}
+
+func (c *txConnector) Connect(context.Context) (driver.Conn, error) {
+	conn, err := c.base.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	return txConn{Conn: conn, c: c}, nil
+}
+
+func (c *txConnector) Driver() driver.Driver {
+	return c.base
+}
+
+type txConn struct {
+	driver.Conn
+	c *txConnector
+}
+
+func (c txConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, query)
+}
+
+// Begin is used by database/sql since the connections do not implement BeginTx
+func (c txConn) Begin() (driver.Tx, error) {
+	tx, err := c.Conn.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	return txTx{Tx: tx, c: c.c}, nil
+}
+
+type txTx struct {
+	driver.Tx
+	c *txConnector
+}
+
+func (t txTx) Commit() error {
+	t.c.ended = append(t.c.ended, "commit")
+	if err := t.Tx.Commit(); err != nil {
+		return err
+	}
+
+	return t.c.commitErr
+}
+
+func (t txTx) Rollback() error {
+	t.c.ended = append(t.c.ended, "rollback")
+	if err := t.Tx.Rollback(); err != nil {
+		return err
+	}
+
+	return t.c.rollbackErr
+}
+
+// openTxDB opens a fakedb database whose transactions are recorded by the connector
+func openTxDB(t *testing.T) (*sql.DB, *txConnector) {
+	t.Helper()
+
+	base, err := sql.Open("test", t.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer base.Close()
+
+	c := &txConnector{base: base.Driver(), dsn: t.Name()}
+	db := sql.OpenDB(c)
+	t.Cleanup(func() { db.Close() })
+
+	return db, c
+}
+
+func TestTx(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("commit", func(t *testing.T) {
+		db, c := openTxDB(t)
+
+		err := Tx(ctx, db, func(tx *sql.Tx) error {
+			if _, err := Exec(ctx, tx, "WIPE"); err != nil {
+				return err
+			}
+
+			if _, err := Exec(ctx, tx, "CREATE|users|id=int64"); err != nil {
+				return err
+			}
+
+			_, err := All(ctx, tx, scan.SingleColumnMapper[int64], "SELECT|users|id|")
+			return err
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(c.ended) != 1 || c.ended[0] != "commit" {
+			t.Fatalf("expected a commit, got %v", c.ended)
+		}
+	})
+
+	t.Run("rollback on error", func(t *testing.T) {
+		db, c := openTxDB(t)
+		fnErr := errors.New("failed")
+
+		err := Tx(ctx, db, func(tx *sql.Tx) error { return fnErr })
+		if !errors.Is(err, fnErr) || err.Error() != "failed" {
+			t.Fatalf("expected the error of fn, got %v", err)
+		}
+
+		if len(c.ended) != 1 || c.ended[0] != "rollback" {
+			t.Fatalf("expected a rollback, got %v", c.ended)
+		}
+	})
+
+	t.Run("rollback on panic", func(t *testing.T) {
+		db, c := openTxDB(t)
+
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("expected the panic to continue, got %v", r)
+			}
+
+			if len(c.ended) != 1 || c.ended[0] != "rollback" {
+				t.Fatalf("expected a rollback, got %v", c.ended)
+			}
+		}()
+
+		_ = Tx(ctx, db, func(tx *sql.Tx) error { panic("boom") })
+		t.Fatal("expected a panic")
+	})
+
+	t.Run("commit error", func(t *testing.T) {
+		db, c := openTxDB(t)
+		c.commitErr = errors.New("conflict")
+
+		err := Tx(ctx, db, func(tx *sql.Tx) error { return nil })
+		if !errors.Is(err, c.commitErr) || err.Error() != "commit transaction: conflict" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(c.ended) != 1 || c.ended[0] != "commit" {
+			t.Fatalf("expected only a commit, got %v", c.ended)
+		}
+	})
+
+	t.Run("rollback error", func(t *testing.T) {
+		db, c := openTxDB(t)
+		c.rollbackErr = errors.New("connection lost")
+		fnErr := errors.New("failed")
+
+		err := Tx(ctx, db, func(tx *sql.Tx) error { return fnErr })
+		if !errors.Is(err, fnErr) || err.Error() != "failed (rollback: connection lost)" {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestTxOptions(t *testing.T) {
+	db, c := openTxDB(t)
+
+	// The connections do not support read only transactions
+	err := TxOptions(context.Background(), db, &sql.TxOptions{ReadOnly: true}, func(tx *sql.Tx) error {
+		t.Fatal("expected fn not to be called")
+		return nil
+	})
+	if err == nil || err.Error() != "begin transaction: sql: driver does not support read-only transactions" {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(c.ended) != 0 {
+		t.Fatalf("expected no transaction, got %v", c.ended)
+	}
+}
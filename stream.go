@@ -0,0 +1,90 @@
+package scan
+
+import "context"
+
+// Stream runs the query in a new goroutine and sends the mapped rows on the returned channel
+// as they are read, for worker pools and pipelines.
+//
+// Both channels are closed when the rows are done. The error channel receives
+// at most one error, from the query, a row or the context, and stops the stream.
+// The rows are read as the values are received, so cancel the context to stop
+// early if the channel is not drained.
+//
+//	users, errs := scan.Stream(ctx, exec, scan.StructMapper[User](), "SELECT * FROM users")
+//	for user := range users {
+//	    // ...
+//	}
+//	if err := <-errs; err != nil {
+//	    // ...
+//	}
+func Stream[T any](ctx context.Context, exec Queryer, m Mapper[T], query string, args ...any) (<-chan T, <-chan error) {
+	values := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(values)
+
+		rows, err := queryContext(ctx, exec, typeOf[T](), query, args)
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		// The rows are closed before the error is sent
+		err = streamRows(ctx, m, rows, values)
+		rows.Close()
+		if err != nil {
+			errs <- withQuery(err, query)
+		}
+	}()
+
+	return values, errs
+}
+
+// StreamFromRows is like [Stream] for the given [Rows], which are closed when the rows are done
+func StreamFromRows[T any](ctx context.Context, m Mapper[T], rows Rows) (<-chan T, <-chan error) {
+	values := make(chan T)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(values)
+
+		err := streamRows(ctx, m, rows, values)
+		rows.Close()
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return values, errs
+}
+
+func streamRows[T any](ctx context.Context, m Mapper[T], rows Rows, values chan<- T) error {
+	allowUnknown, _ := ctx.Value(CtxKeyAllowUnknownColumns).(bool)
+	v, err := wrapRows(rows, allowUnknown)
+	if err != nil {
+		return err
+	}
+
+	before, after := buildMapper(ctx, m, v, v.columnsCopy())
+
+	for rows.Next() {
+		one, err := scanOneRow(v, before, after)
+		if isSkipped(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case values <- one:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return rows.Err()
+}
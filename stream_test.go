@@ -0,0 +1,53 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+
+	users, errs := Stream(ctx, funcQ(usersByID), StructMapper[User](), "", 1, 2, 3)
+
+	var got []User
+	for u := range users {
+		got = append(got, u)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 3}, ids(got)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Errors stop the stream
+	errQuery := errors.New("query")
+	failing := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		return nil, errQuery
+	})
+	users, errs = Stream(ctx, failing, StructMapper[User](), "")
+	if _, ok := <-users; ok {
+		t.Fatal("expected no rows")
+	}
+	if err := <-errs; !errors.Is(err, errQuery) {
+		t.Fatalf("expected the query error, got %v", err)
+	}
+
+	// Cancelling the context stops a stream that is not drained
+	ctx, cancel := context.WithCancel(ctx)
+	rows := newSliceRows([]string{"id", "name"}, []any{1, "a"}, []any{2, "b"})
+	users, errs = StreamFromRows(ctx, StructMapper[User](), rows)
+	<-users
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if !rows.closed {
+		t.Fatal("expected the rows to be closed")
+	}
+}
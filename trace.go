@@ -0,0 +1,192 @@
+package scan
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Tracer receives the events of the queries run with a context from [WithTracer],
+// e.g. to log queries with slog or to record them as OpenTelemetry spans
+type Tracer interface {
+	// BeforeQuery is called before the query is sent to the [Queryer].
+	// The returned context is used to run the query and is passed to the other methods
+	BeforeQuery(ctx context.Context, query string, args []any) context.Context
+
+	// RowScanned is called after each row is scanned, with the index of the row
+	// and the error returned while scanning or mapping it, if any
+	RowScanned(ctx context.Context, row int, err error)
+
+	// AfterQuery is called once the rows are closed, with the number of rows that
+	// were scanned and the first error of the query, if any
+	AfterQuery(ctx context.Context, query string, rows int, err error)
+}
+
+// ctxKeyTracer holds the [Tracer] for the queries run with the context
+var ctxKeyTracer contextKey = "tracer"
+
+// WithTracer returns a context that sends the events of every query
+// run with it by [One], [All], [Cursor] and the other query functions to the tracer.
+// The tracer is called as a [Hook], before the hooks of the [Queryer]
+//
+//	ctx = scan.WithTracer(ctx, tracer)
+//	users, err := stdscan.All(ctx, db, scan.StructMapper[User](), "SELECT * FROM users")
+func WithTracer(ctx context.Context, t Tracer) context.Context {
+	return context.WithValue(ctx, ctxKeyTracer, t)
+}
+
+// tracerHook sends the events of the queries to a [Tracer]
+type tracerHook struct {
+	t Tracer
+}
+
+func (h tracerHook) Handle(ctx context.Context, e Event) context.Context {
+	switch e := e.(type) {
+	case QueryStart:
+		return h.t.BeforeQuery(ctx, e.Query, e.Args)
+	case RowMapped:
+		h.t.RowScanned(ctx, e.Row, e.Err)
+	case ScanEnd:
+		h.t.AfterQuery(ctx, e.Query, e.Rows, e.Err)
+	}
+
+	return ctx
+}
+
+// contextHooks returns the hooks for the [Tracer] and the [QueryStats] of the context, if any.
+// The stats are only recorded if the type of the rows is known
+func contextHooks(ctx context.Context, typ reflect.Type) []Hook {
+	var hooks []Hook
+	if t, ok := ctx.Value(ctxKeyTracer).(Tracer); ok {
+		hooks = append(hooks, tracerHook{t: t})
+	}
+	if s, ok := ctx.Value(ctxKeyQueryStats).(*QueryStats); ok && s != nil && typ != nil {
+		hooks = append(hooks, statsHook{stats: s, typ: typ})
+	}
+
+	return hooks
+}
+
+// queryContext runs the query with exec and sends its events to the hooks of the context,
+// from [WithTracer] and [WithQueryStats], and then to the hooks of exec, if any
+func queryContext(ctx context.Context, exec Queryer, typ reflect.Type, query string, args []any) (Rows, error) {
+	if typ != nil {
+		ctx = context.WithValue(ctx, ctxKeyRowType, typ)
+	}
+
+	hooks := hooksOf(exec)
+	if ctxHooks := contextHooks(ctx, typ); len(ctxHooks) > 0 {
+		hooks = append(ctxHooks, hooks...)
+	}
+
+	if len(hooks) == 0 {
+		rows, err := exec.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		return filterRows(ctx, rows), nil
+	}
+
+	start := time.Now()
+	ctx = sendEvent(ctx, hooks, QueryStart{Query: query, Args: args, Type: typ})
+
+	rows, err := exec.QueryContext(ctx, query, args...)
+	ctx = sendEvent(ctx, hooks, QueryEnd{Query: query, Duration: time.Since(start), Err: err})
+	if err != nil {
+		sendEvent(ctx, hooks, ScanEnd{Query: query, Duration: time.Since(start), Err: err})
+		return nil, err
+	}
+	rows = filterRows(ctx, rows)
+
+	traced := &tracedRows{
+		Rows:  rows,
+		ctx:   ctx,
+		hooks: hooks,
+		query: query,
+		start: start,
+	}
+	if multi, ok := rows.(MultiRows); ok {
+		return tracedMultiRows{tracedRows: traced, multi: multi}, nil
+	}
+
+	return traced, nil
+}
+
+// rowTracer is implemented by rows that trace each scanned row
+type rowTracer interface {
+	scanned(value any, err error)
+}
+
+// traceRow sends the result of scanning the current row to the hooks, if any.
+// The value is the mapped row, or nil if it is not a single value
+func traceRow(v *Row, value any, err error) {
+	if t, ok := v.r.(rowTracer); ok {
+		t.scanned(value, err)
+	}
+}
+
+// tracedRows sends the events of the rows to the hooks
+type tracedRows struct {
+	Rows
+	ctx   context.Context
+	hooks []Hook
+	query string
+	start time.Time
+
+	rows    int
+	scans   int
+	err     error
+	stopped bool
+}
+
+func (r *tracedRows) scanned(value any, err error) {
+	if err != nil {
+		value = nil
+	}
+	r.ctx = sendEvent(r.ctx, r.hooks, RowMapped{Query: r.query, Row: r.scans, Value: value, Err: err})
+	r.scans++
+
+	if err != nil {
+		if r.err == nil {
+			r.err = err
+		}
+		return
+	}
+
+	r.rows++
+}
+
+// Close closes the rows and reports the query the first time
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	if r.stopped {
+		return err
+	}
+	r.stopped = true
+
+	queryErr := r.err
+	if queryErr == nil {
+		queryErr = r.Rows.Err()
+	}
+	if queryErr == nil {
+		queryErr = err
+	}
+
+	sendEvent(r.ctx, r.hooks, ScanEnd{Query: r.query, Rows: r.rows, Duration: time.Since(r.start), Err: queryErr})
+
+	return err
+}
+
+func (r *tracedRows) mappingBuilt(typ reflect.Type, c cols, d time.Duration) {
+	r.ctx = sendEvent(r.ctx, r.hooks, MappingBuilt{Query: r.query, Type: typ, Columns: c, Duration: d})
+}
+
+// tracedMultiRows keeps support for multiple result sets
+type tracedMultiRows struct {
+	*tracedRows
+	multi MultiRows
+}
+
+func (r tracedMultiRows) NextResultSet() bool {
+	return r.multi.NextResultSet()
+}
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type traceKey struct{}
+
+// recordingTracer records the events it receives
+type recordingTracer struct {
+	events []string
+}
+
+func (r *recordingTracer) BeforeQuery(ctx context.Context, query string, args []any) context.Context {
+	r.events = append(r.events, fmt.Sprintf("before %s %v", query, args))
+	return context.WithValue(ctx, traceKey{}, "span")
+}
+
+func (r *recordingTracer) RowScanned(ctx context.Context, row int, err error) {
+	r.events = append(r.events, fmt.Sprintf("row %d %v %v", row, err != nil, ctx.Value(traceKey{})))
+}
+
+func (r *recordingTracer) AfterQuery(ctx context.Context, query string, rows int, err error) {
+	r.events = append(r.events, fmt.Sprintf("after %s %d %v %v", query, rows, err != nil, ctx.Value(traceKey{})))
+}
+
+func TestTracer(t *testing.T) {
+	tracer := &recordingTracer{}
+	ctx := WithTracer(context.Background(), tracer)
+
+	var queryCtx context.Context
+	exec := funcQ(func(ctx context.Context, query string, args ...any) (Rows, error) {
+		queryCtx = ctx
+		if query == "fail" {
+			return nil, errors.New("failed")
+		}
+		return newSliceRows([]string{"id", "name"}, []any{1, "foo"}, []any{2, "bar"}), nil
+	})
+
+	if _, err := All(ctx, exec, StructMapper[User](), "all", 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if queryCtx.Value(traceKey{}) != "span" {
+		t.Fatal("expected the query to run with the context from BeforeQuery")
+	}
+
+	if _, err := All(ctx, exec, StructMapper[User](), "fail"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if _, err := One(ctx, exec, ColumnMapper[int]("missing"), "one"); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	expected := []string{
+		"before all [1]",
+		"row 0 false span",
+		"row 1 false span",
+		"after all 2 false span",
+		"before fail []",
+		"after fail 0 true span",
+		"before one []",
+		"row 0 true span",
+		"after one 0 true span",
+	}
+	if diff := cmp.Diff(expected, tracer.events); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Queries are not traced without a tracer
+	tracer.events = nil
+	if _, err := All(context.Background(), exec, StructMapper[User](), "all"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(tracer.events) != 0 {
+		t.Fatalf("expected no events, got %v", tracer.events)
+	}
+}
@@ -0,0 +1,81 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDuplicateKey is returned by the mod from [Unique] when two rows of a query have the same key
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// OnDuplicate decides what the mod from [Unique] does with rows that have a key seen before
+type OnDuplicate int
+
+const (
+	// DuplicateError returns an error wrapping [ErrDuplicateKey] for the row
+	DuplicateError OnDuplicate = iota
+	// DuplicateSkip leaves the row out of the results, keeping the first row with the key.
+	// Rows are skipped by [All], [AllIndexed], [AllWhile], [Chunks] and [PreparedQuery],
+	// while functions that return a single row such as [Cursor] return the error instead
+	DuplicateSkip
+)
+
+// duplicateError is the error for a row with a duplicate key
+type duplicateError struct {
+	key  any
+	skip bool
+}
+
+func (e duplicateError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrDuplicateKey, e.key)
+}
+
+func (e duplicateError) Is(target error) bool {
+	return target == ErrDuplicateKey
+}
+
+// isSkipped reports if the error means that the row should be left out of the results
+func isSkipped(err error) bool {
+	var dup duplicateError
+	return errors.As(err, &dup) && dup.skip
+}
+
+// Unique returns a [MapperMod] that checks that the keys of the rows of a query are unique,
+// e.g. to keep [AllIndexed] from silently overwriting rows. onDuplicate decides what
+// happens with a row that has a key seen before.
+// T must be the type returned by the mapper
+//
+//	m := scan.StructMapper[User](scan.WithMapperMods(
+//	    scan.Unique(func(u User) string { return u.Email }, scan.DuplicateError),
+//	))
+func Unique[T any, K comparable](key func(T) K, onDuplicate OnDuplicate) MapperMod {
+	return func(ctx context.Context, c cols) (BeforeFunc, AfterMod) {
+		// The keys are kept on the *Row of each run of the query,
+		// since the mapping can be reused by concurrent runs
+		stateKey := new(int)
+
+		return func(r *Row) (any, error) {
+				return r, nil
+			}, func(link, retrieved any) error {
+				v, ok := retrieved.(T)
+				if !ok {
+					return fmt.Errorf("uniqueness check expected %T, got %T", v, retrieved)
+				}
+
+				k := key(v)
+				r, ok := link.(*Row)
+				if !ok {
+					return nil
+				}
+
+				seen := runState(r, stateKey, func() map[K]struct{} { return make(map[K]struct{}) })
+				if _, ok := seen[k]; ok {
+					return duplicateError{key: k, skip: onDuplicate == DuplicateSkip}
+				}
+
+				seen[k] = struct{}{}
+				return nil
+			}
+	}
+}
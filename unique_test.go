@@ -0,0 +1,95 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestUnique(t *testing.T) {
+	ctx := context.Background()
+	byID := func(u User) int { return u.ID }
+
+	unique := StructMapper[User](WithMapperMods(Unique(byID, DuplicateError)))
+	if _, err := All(ctx, funcQ(usersByID), unique, "", 1, 2, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := All(ctx, funcQ(usersByID), unique, "", 1, 2, 1); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected ErrDuplicateKey, got %v", err)
+	}
+
+	dedupe := StructMapper[User](WithMapperMods(Unique(byID, DuplicateSkip)))
+	users, err := All(ctx, funcQ(usersByID), dedupe, "", 1, 2, 1, 3, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]int{1, 2, 3}, ids(users)); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	var chunks [][]int
+	err = Chunks(ctx, funcQ(usersByID), dedupe, 2, func(users []User) error {
+		chunks = append(chunks, ids(users))
+		return nil
+	}, "", 1, 1, 2, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([][]int{{1, 2}, {3}}, chunks); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Each run of a prepared query is checked on its own
+	q, err := Prepare(ctx, funcQ(usersByID), unique, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer q.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := q.All(ctx, 5, 6); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// Concurrent runs that share the mapping are checked on their own
+	before, after := unique(ctx, []string{"id", "name"})
+	run := func(ids ...any) *Row {
+		rows, _ := usersByID(ctx, "", ids...)
+		v, err := wrapRows(rows, false)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return v
+	}
+	next := func(v *Row) error {
+		v.r.Next()
+		_, err := mapOneRow(v, before, after)
+		return err
+	}
+
+	a, b := run(1, 1), run(5)
+	for _, v := range []*Row{a, b} {
+		if err := next(v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := next(a); !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("expected ErrDuplicateKey for the interleaved run, got %v", err)
+	}
+}
+
+func ids(users []User) []int {
+	ids := make([]int, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+
+	return ids
+}
@@ -0,0 +1,105 @@
+package scan
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// UnitConversion converts a value from the unit stored in a column
+// into the unit of a field, e.g. from meters to kilometers
+type UnitConversion func(float64) float64
+
+// WithUnits registers the conversions for the unit tag option, so that a
+// field can be in a different unit from its column.
+//
+//	type Trip struct {
+//	    Distance float64 `db:"distance_m,unit=km"`
+//	}
+//
+//	src, _ := scan.NewStructMapperSource(scan.WithUnits(map[string]scan.UnitConversion{
+//	    "km": func(m float64) float64 { return m / 1000 },
+//	}))
+//
+// Integer fields are rounded to the nearest integer
+func WithUnits(units map[string]UnitConversion) MappingSourceOption {
+	return func(src *mapperSourceImpl) error {
+		if src.units == nil {
+			src.units = make(map[string]UnitConversion, len(units))
+		}
+		for name, fn := range units {
+			if fn == nil {
+				return fmt.Errorf("unit %q has no conversion", name)
+			}
+			src.units[name] = fn
+		}
+		return nil
+	}
+}
+
+// unitDecoder returns the decoder for fields with the unit tag option
+func (s *mapperSourceImpl) unitDecoder(unit string) decodeFunc {
+	convert, ok := s.units[unit]
+
+	return func(src any, dest any) error {
+		if !ok {
+			return fmt.Errorf("unknown unit %q, register it with WithUnits", unit)
+		}
+
+		f, err := toFloat(src)
+		if err != nil {
+			return err
+		}
+		f = convert(f)
+
+		v := reflect.ValueOf(dest).Elem()
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+
+		switch {
+		case v.CanFloat():
+			v.SetFloat(f)
+		case v.CanInt():
+			i := int64(math.Round(f))
+			if v.OverflowInt(i) {
+				return fmt.Errorf("%v %s overflows %s", f, unit, v.Type())
+			}
+			v.SetInt(i)
+		default:
+			return fmt.Errorf("cannot convert unit %q into %s", unit, v.Type())
+		}
+
+		return nil
+	}
+}
+
+// toFloat returns the number in a column value
+func toFloat(src any) (float64, error) {
+	switch src := src.(type) {
+	case float64:
+		return src, nil
+	case int64:
+		return float64(src), nil
+	case []byte:
+		return strconv.ParseFloat(string(src), 64)
+	case string:
+		return strconv.ParseFloat(src, 64)
+	}
+
+	v := reflect.ValueOf(src)
+	switch {
+	case v.CanFloat():
+		return v.Float(), nil
+	case v.CanInt():
+		return float64(v.Int()), nil
+	case v.CanUint():
+		return float64(v.Uint()), nil
+	}
+
+	return 0, fmt.Errorf("cannot convert %T to a number", src)
+}
@@ -0,0 +1,52 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type Trip struct {
+	ID       int
+	Distance float64 `db:"distance_m,unit=km"`
+	Duration *int    `db:"duration_s,unit=min"`
+}
+
+func TestUnitColumns(t *testing.T) {
+	src, err := NewStructMapperSource(WithUnits(map[string]UnitConversion{
+		"km":  func(m float64) float64 { return m / 1000 },
+		"min": func(s float64) float64 { return s / 60 },
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rows := newSliceRows([]string{"id", "distance_m", "duration_s"},
+		[]any{1, int64(12500), "150"},
+		[]any{2, 800.0, nil},
+	)
+
+	trips, err := AllFromRows(context.Background(), CustomStructMapper[Trip](src), rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Trip{
+		{ID: 1, Distance: 12.5, Duration: toPtr(3)},
+		{ID: 2, Distance: 0.8},
+	}
+	if diff := cmp.Diff(expected, trips); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Units must be registered
+	rows = newSliceRows([]string{"id", "distance_m"}, []any{1, 100})
+	if _, err := AllFromRows(context.Background(), StructMapper[Trip](), rows); err == nil {
+		t.Fatal("expected an error for an unknown unit")
+	}
+
+	if _, err := NewStructMapperSource(WithUnits(map[string]UnitConversion{"km": nil})); err == nil {
+		t.Fatal("expected an error for a unit without a conversion")
+	}
+}
@@ -0,0 +1,100 @@
+package scan
+
+import "fmt"
+
+// WarningKind is the kind of a [Warning]
+type WarningKind int
+
+const (
+	// WarningUnknownColumn is a column without a field that is discarded
+	// because unknown columns are allowed
+	WarningUnknownColumn WarningKind = iota
+	// WarningTruncatedRecursion is a field of a recursive type that is not mapped
+	// because it is deeper than the max depth, see [WithMaxDepth]
+	WarningTruncatedRecursion
+	// WarningLossyConversion is a value that overflowed or lost precision
+	// and was stored as the nearest value that fits, see [WithNumericGuard] and [WithIntegerOverflow]
+	WarningLossyConversion
+	// WarningShadowedField is a field that is not scanned because
+	// another field, e.g. of an embedded struct, is mapped to the same column
+	WarningShadowedField
+)
+
+// String returns the name of the kind
+func (k WarningKind) String() string {
+	switch k {
+	case WarningUnknownColumn:
+		return "unknown column"
+	case WarningTruncatedRecursion:
+		return "truncated recursion"
+	case WarningLossyConversion:
+		return "lossy conversion"
+	case WarningShadowedField:
+		return "shadowed field"
+	default:
+		return fmt.Sprintf("WarningKind(%d)", int(k))
+	}
+}
+
+// Warning is something the struct mapper did that does not fail the query
+// but may lose data, such as discarding a column
+type Warning struct {
+	Kind WarningKind
+	// Column is the column of the query the warning is about
+	Column string
+	// Field is the path of the struct field, if the warning is about one
+	Field   string
+	Message string
+}
+
+// String returns the kind and the message of the warning
+func (w Warning) String() string {
+	return w.Kind.String() + ": " + w.Message
+}
+
+// WithWarnings makes the struct mapper report its warnings to fn.
+// Warnings about the columns and fields are reported once each time the mapper is
+// built for a query, and lossy conversions are reported for each value.
+// fn is called by the goroutine reading the rows, and must not block for long.
+//
+//	m := scan.StructMapper[User](
+//	    scan.WithAllowUnknownColumns(true),
+//	    scan.WithWarnings(func(w scan.Warning) { log.Println(w) }),
+//	)
+func WithWarnings(fn func(Warning)) MappingOption {
+	return func(opt *mappingOptions) {
+		opt.warnings = fn
+	}
+}
+
+// WarningsTo returns a function for [WithWarnings] that sends the warnings on the channel.
+// Sends block, so the channel should be buffered or read by another goroutine
+func WarningsTo(ch chan<- Warning) func(Warning) {
+	return func(w Warning) {
+		ch <- w
+	}
+}
+
+// warner reports the warnings of a mapper. It does nothing if it is nil
+type warner func(Warning)
+
+func (w warner) warn(kind WarningKind, column, field, format string, args ...any) {
+	if w == nil {
+		return
+	}
+
+	w(Warning{Kind: kind, Column: column, Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// except returns a warner that does not report warnings of the kind
+func (w warner) except(kind WarningKind) warner {
+	if w == nil {
+		return nil
+	}
+
+	return func(warning Warning) {
+		if warning.Kind != kind {
+			w(warning)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type AuditInfo struct {
+	ID int
+}
+
+type auditedUser struct {
+	User
+	AuditInfo
+	Small int8
+}
+
+func TestWarnings(t *testing.T) {
+	ctx := context.Background()
+
+	var warnings []Warning
+	collect := WithWarnings(func(w Warning) { warnings = append(warnings, w) })
+
+	newRows := func() Rows {
+		return newSliceRows([]string{"id", "name", "small", "extra", "parent.parent.id"},
+			[]any{1, "a", int64(300), "x", 5},
+			[]any{2, "b", int64(-300), "y", 6},
+		)
+	}
+
+	_, err := AllFromRows(ctx, StructMapper[auditedUser](
+		collect,
+		WithAllowUnknownColumns(true),
+		WithIntegerOverflow(IntegerOverflowSaturate, nil),
+	), newRows())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Warning{
+		{Kind: WarningShadowedField, Column: "id", Field: "AuditInfo.ID"},
+		{Kind: WarningUnknownColumn, Column: "extra"},
+		{Kind: WarningUnknownColumn, Column: "parent.parent.id"},
+		{Kind: WarningLossyConversion, Column: "small"},
+		{Kind: WarningLossyConversion, Column: "small"},
+	}
+	if diff := cmp.Diff(expected, warnings, cmpWarnings); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Fields deeper than the max depth are reported once
+	warnings = nil
+	_, err = AllFromRows(ctx, StructMapper[treeNode](collect, WithAllowUnknownColumns(true), WithMaxDepth(0)),
+		newSliceRows([]string{"id", "parent.id", "parent.parent.id", "parent.parent.parent.id"}, []any{1, 2, 3, 4}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected = []Warning{
+		{Kind: WarningTruncatedRecursion, Column: "parent.parent.id", Field: "treeNode.Parent.Parent"},
+		{Kind: WarningUnknownColumn, Column: "parent.parent.id"},
+		{Kind: WarningUnknownColumn, Column: "parent.parent.parent.id"},
+	}
+	if diff := cmp.Diff(expected, warnings, cmpWarnings); diff != "" {
+		t.Fatalf("diff: %s", diff)
+	}
+
+	// Warnings can be sent on a channel
+	ch := make(chan Warning, 10)
+	_, err = AllFromRows(ctx, StructMapper[User](WithWarnings(WarningsTo(ch)), WithAllowUnknownColumns(true)),
+		newSliceRows([]string{"id", "age"}, []any{1, 2}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if w := <-ch; w.String() != `unknown column: Column "age" has no field and is discarded` {
+		t.Fatalf("unexpected warning: %s", w)
+	}
+}
+
+var cmpWarnings = cmp.Comparer(func(a, b Warning) bool {
+	return a.Kind == b.Kind && a.Column == b.Column && a.Field == b.Field
+})